@@ -22,9 +22,13 @@ const (
 )
 
 type PrepareExportRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionToken  string                 `protobuf:"bytes,1,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
-	Digest        string                 `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SessionToken string                 `protobuf:"bytes,1,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	Digest       string                 `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// Target node platform (os/arch[/variant]). When set, only that
+	// platform's manifest is exported along with the index; empty exports
+	// every platform.
+	Platform      string `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,9 +77,18 @@ func (x *PrepareExportRequest) GetDigest() string {
 	return ""
 }
 
+func (x *PrepareExportRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
 type PrepareExportResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SizeBytes     int64                  `protobuf:"varint,1,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SizeBytes int64                  `protobuf:"varint,1,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// Names of every image record for the digest on the source node.
+	ImageNames    []string `protobuf:"bytes,2,rep,name=image_names,json=imageNames,proto3" json:"image_names,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -117,6 +130,13 @@ func (x *PrepareExportResponse) GetSizeBytes() int64 {
 	return 0
 }
 
+func (x *PrepareExportResponse) GetImageNames() []string {
+	if x != nil {
+		return x.ImageNames
+	}
+	return nil
+}
+
 type ExportImageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionToken  string                 `protobuf:"bytes,1,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
@@ -162,8 +182,13 @@ func (x *ExportImageRequest) GetSessionToken() string {
 }
 
 type DataChunk struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// SHA-256 of data.
+	Sha256 []byte `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// Set only on the final message, which carries no data: SHA-256 of all
+	// data sent on the stream.
+	StreamSha256  []byte `protobuf:"bytes,3,opt,name=stream_sha256,json=streamSha256,proto3" json:"stream_sha256,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -205,13 +230,32 @@ func (x *DataChunk) GetData() []byte {
 	return nil
 }
 
+func (x *DataChunk) GetSha256() []byte {
+	if x != nil {
+		return x.Sha256
+	}
+	return nil
+}
+
+func (x *DataChunk) GetStreamSha256() []byte {
+	if x != nil {
+		return x.StreamSha256
+	}
+	return nil
+}
+
 type ImportFromRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	SessionToken   string                 `protobuf:"bytes,1,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
 	Digest         string                 `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
 	SourceEndpoint string                 `protobuf:"bytes,3,opt,name=source_endpoint,json=sourceEndpoint,proto3" json:"source_endpoint,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// References to record the image under, in addition to the names
+	// annotated in the archive.
+	ImageNames []string `protobuf:"bytes,4,rep,name=image_names,json=imageNames,proto3" json:"image_names,omitempty"`
+	// Recorded on every created image record as tote.dev/salvaged-from.
+	SourceNode    string `protobuf:"bytes,5,opt,name=source_node,json=sourceNode,proto3" json:"source_node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ImportFromRequest) Reset() {
@@ -265,10 +309,26 @@ func (x *ImportFromRequest) GetSourceEndpoint() string {
 	return ""
 }
 
+func (x *ImportFromRequest) GetImageNames() []string {
+	if x != nil {
+		return x.ImageNames
+	}
+	return nil
+}
+
+func (x *ImportFromRequest) GetSourceNode() string {
+	if x != nil {
+		return x.SourceNode
+	}
+	return ""
+}
+
 type ImportFromResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Blobs that were missing or failed digest verification after import.
+	MissingBlobs  []string `protobuf:"bytes,3,rep,name=missing_blobs,json=missingBlobs,proto3" json:"missing_blobs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -317,6 +377,13 @@ func (x *ImportFromResponse) GetError() string {
 	return ""
 }
 
+func (x *ImportFromResponse) GetMissingBlobs() []string {
+	if x != nil {
+		return x.MissingBlobs
+	}
+	return nil
+}
+
 type ListImagesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -693,28 +760,491 @@ func (x *PushImageResponse) GetError() string {
 	return ""
 }
 
+type CheckImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ImageRef      string                 `protobuf:"bytes,1,opt,name=image_ref,json=imageRef,proto3" json:"image_ref,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckImageRequest) Reset() {
+	*x = CheckImageRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckImageRequest) ProtoMessage() {}
+
+func (x *CheckImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckImageRequest.ProtoReflect.Descriptor instead.
+func (*CheckImageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CheckImageRequest) GetImageRef() string {
+	if x != nil {
+		return x.ImageRef
+	}
+	return ""
+}
+
+type CheckImageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	MissingBlobs  []string               `protobuf:"bytes,2,rep,name=missing_blobs,json=missingBlobs,proto3" json:"missing_blobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckImageResponse) Reset() {
+	*x = CheckImageResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckImageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckImageResponse) ProtoMessage() {}
+
+func (x *CheckImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckImageResponse.ProtoReflect.Descriptor instead.
+func (*CheckImageResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CheckImageResponse) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *CheckImageResponse) GetMissingBlobs() []string {
+	if x != nil {
+		return x.MissingBlobs
+	}
+	return nil
+}
+
+type ExportBlobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionToken  string                 `protobuf:"bytes,1,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	BlobDigest    string                 `protobuf:"bytes,2,opt,name=blob_digest,json=blobDigest,proto3" json:"blob_digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportBlobRequest) Reset() {
+	*x = ExportBlobRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportBlobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportBlobRequest) ProtoMessage() {}
+
+func (x *ExportBlobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportBlobRequest.ProtoReflect.Descriptor instead.
+func (*ExportBlobRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportBlobRequest) GetSessionToken() string {
+	if x != nil {
+		return x.SessionToken
+	}
+	return ""
+}
+
+func (x *ExportBlobRequest) GetBlobDigest() string {
+	if x != nil {
+		return x.BlobDigest
+	}
+	return ""
+}
+
+type RepairImageRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Digest string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Blobs  []string               `protobuf:"bytes,2,rep,name=blobs,proto3" json:"blobs,omitempty"`
+	// Peer agent to fetch blobs from (authorized by session_token).
+	SessionToken   string `protobuf:"bytes,3,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	SourceEndpoint string `protobuf:"bytes,4,opt,name=source_endpoint,json=sourceEndpoint,proto3" json:"source_endpoint,omitempty"`
+	// Registry repository to fetch blobs from when source_endpoint is empty.
+	RegistryRef      string `protobuf:"bytes,5,opt,name=registry_ref,json=registryRef,proto3" json:"registry_ref,omitempty"`
+	RegistryUsername string `protobuf:"bytes,6,opt,name=registry_username,json=registryUsername,proto3" json:"registry_username,omitempty"`
+	RegistryPassword string `protobuf:"bytes,7,opt,name=registry_password,json=registryPassword,proto3" json:"registry_password,omitempty"`
+	Insecure         bool   `protobuf:"varint,8,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RepairImageRequest) Reset() {
+	*x = RepairImageRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepairImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepairImageRequest) ProtoMessage() {}
+
+func (x *RepairImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepairImageRequest.ProtoReflect.Descriptor instead.
+func (*RepairImageRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RepairImageRequest) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *RepairImageRequest) GetBlobs() []string {
+	if x != nil {
+		return x.Blobs
+	}
+	return nil
+}
+
+func (x *RepairImageRequest) GetSessionToken() string {
+	if x != nil {
+		return x.SessionToken
+	}
+	return ""
+}
+
+func (x *RepairImageRequest) GetSourceEndpoint() string {
+	if x != nil {
+		return x.SourceEndpoint
+	}
+	return ""
+}
+
+func (x *RepairImageRequest) GetRegistryRef() string {
+	if x != nil {
+		return x.RegistryRef
+	}
+	return ""
+}
+
+func (x *RepairImageRequest) GetRegistryUsername() string {
+	if x != nil {
+		return x.RegistryUsername
+	}
+	return ""
+}
+
+func (x *RepairImageRequest) GetRegistryPassword() string {
+	if x != nil {
+		return x.RegistryPassword
+	}
+	return ""
+}
+
+func (x *RepairImageRequest) GetInsecure() bool {
+	if x != nil {
+		return x.Insecure
+	}
+	return false
+}
+
+type RepairImageResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Blobs still missing or corrupt after the repair attempt.
+	MissingBlobs  []string `protobuf:"bytes,3,rep,name=missing_blobs,json=missingBlobs,proto3" json:"missing_blobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepairImageResponse) Reset() {
+	*x = RepairImageResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepairImageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepairImageResponse) ProtoMessage() {}
+
+func (x *RepairImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepairImageResponse.ProtoReflect.Descriptor instead.
+func (*RepairImageResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RepairImageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RepairImageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RepairImageResponse) GetMissingBlobs() []string {
+	if x != nil {
+		return x.MissingBlobs
+	}
+	return nil
+}
+
+type ListCorruptImagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCorruptImagesRequest) Reset() {
+	*x = ListCorruptImagesRequest{}
+	mi := &file_api_v1_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCorruptImagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCorruptImagesRequest) ProtoMessage() {}
+
+func (x *ListCorruptImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCorruptImagesRequest.ProtoReflect.Descriptor instead.
+func (*ListCorruptImagesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{19}
+}
+
+type ListCorruptImagesResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Images []*CorruptImage        `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+	// Unix time of the last completed scan; 0 if no scan has finished yet.
+	ScannedAt     int64 `protobuf:"varint,2,opt,name=scanned_at,json=scannedAt,proto3" json:"scanned_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCorruptImagesResponse) Reset() {
+	*x = ListCorruptImagesResponse{}
+	mi := &file_api_v1_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCorruptImagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCorruptImagesResponse) ProtoMessage() {}
+
+func (x *ListCorruptImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCorruptImagesResponse.ProtoReflect.Descriptor instead.
+func (*ListCorruptImagesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListCorruptImagesResponse) GetImages() []*CorruptImage {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *ListCorruptImagesResponse) GetScannedAt() int64 {
+	if x != nil {
+		return x.ScannedAt
+	}
+	return 0
+}
+
+type CorruptImage struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Digest string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// Blobs that are missing, truncated or fail digest verification.
+	MissingBlobs  []string `protobuf:"bytes,2,rep,name=missing_blobs,json=missingBlobs,proto3" json:"missing_blobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CorruptImage) Reset() {
+	*x = CorruptImage{}
+	mi := &file_api_v1_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CorruptImage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CorruptImage) ProtoMessage() {}
+
+func (x *CorruptImage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CorruptImage.ProtoReflect.Descriptor instead.
+func (*CorruptImage) Descriptor() ([]byte, []int) {
+	return file_api_v1_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CorruptImage) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *CorruptImage) GetMissingBlobs() []string {
+	if x != nil {
+		return x.MissingBlobs
+	}
+	return nil
+}
+
 var File_api_v1_agent_proto protoreflect.FileDescriptor
 
 const file_api_v1_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x12api/v1/agent.proto\x12\atote.v1\"S\n" +
+	"\x12api/v1/agent.proto\x12\atote.v1\"o\n" +
 	"\x14PrepareExportRequest\x12#\n" +
 	"\rsession_token\x18\x01 \x01(\tR\fsessionToken\x12\x16\n" +
-	"\x06digest\x18\x02 \x01(\tR\x06digest\"6\n" +
+	"\x06digest\x18\x02 \x01(\tR\x06digest\x12\x1a\n" +
+	"\bplatform\x18\x03 \x01(\tR\bplatform\"W\n" +
 	"\x15PrepareExportResponse\x12\x1d\n" +
 	"\n" +
-	"size_bytes\x18\x01 \x01(\x03R\tsizeBytes\"9\n" +
+	"size_bytes\x18\x01 \x01(\x03R\tsizeBytes\x12\x1f\n" +
+	"\vimage_names\x18\x02 \x03(\tR\n" +
+	"imageNames\"9\n" +
 	"\x12ExportImageRequest\x12#\n" +
-	"\rsession_token\x18\x01 \x01(\tR\fsessionToken\"\x1f\n" +
+	"\rsession_token\x18\x01 \x01(\tR\fsessionToken\"\\\n" +
 	"\tDataChunk\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\fR\x04data\"y\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\fR\x06sha256\x12#\n" +
+	"\rstream_sha256\x18\x03 \x01(\fR\fstreamSha256\"\xbb\x01\n" +
 	"\x11ImportFromRequest\x12#\n" +
 	"\rsession_token\x18\x01 \x01(\tR\fsessionToken\x12\x16\n" +
 	"\x06digest\x18\x02 \x01(\tR\x06digest\x12'\n" +
-	"\x0fsource_endpoint\x18\x03 \x01(\tR\x0esourceEndpoint\"D\n" +
+	"\x0fsource_endpoint\x18\x03 \x01(\tR\x0esourceEndpoint\x12\x1f\n" +
+	"\vimage_names\x18\x04 \x03(\tR\n" +
+	"imageNames\x12\x1f\n" +
+	"\vsource_node\x18\x05 \x01(\tR\n" +
+	"sourceNode\"i\n" +
 	"\x12ImportFromResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x13\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12#\n" +
+	"\rmissing_blobs\x18\x03 \x03(\tR\fmissingBlobs\"\x13\n" +
 	"\x11ListImagesRequest\".\n" +
 	"\x12ListImagesResponse\x12\x18\n" +
 	"\adigests\x18\x01 \x03(\tR\adigests\"0\n" +
@@ -734,7 +1264,37 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\binsecure\x18\x05 \x01(\bR\binsecure\"C\n" +
 	"\x11PushImageResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error2\x80\x04\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"0\n" +
+	"\x11CheckImageRequest\x12\x1b\n" +
+	"\timage_ref\x18\x01 \x01(\tR\bimageRef\"Q\n" +
+	"\x12CheckImageResponse\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12#\n" +
+	"\rmissing_blobs\x18\x02 \x03(\tR\fmissingBlobs\"Y\n" +
+	"\x11ExportBlobRequest\x12#\n" +
+	"\rsession_token\x18\x01 \x01(\tR\fsessionToken\x12\x1f\n" +
+	"\vblob_digest\x18\x02 \x01(\tR\n" +
+	"blobDigest\"\xa9\x02\n" +
+	"\x12RepairImageRequest\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x14\n" +
+	"\x05blobs\x18\x02 \x03(\tR\x05blobs\x12#\n" +
+	"\rsession_token\x18\x03 \x01(\tR\fsessionToken\x12'\n" +
+	"\x0fsource_endpoint\x18\x04 \x01(\tR\x0esourceEndpoint\x12!\n" +
+	"\fregistry_ref\x18\x05 \x01(\tR\vregistryRef\x12+\n" +
+	"\x11registry_username\x18\x06 \x01(\tR\x10registryUsername\x12+\n" +
+	"\x11registry_password\x18\a \x01(\tR\x10registryPassword\x12\x1a\n" +
+	"\binsecure\x18\b \x01(\bR\binsecure\"j\n" +
+	"\x13RepairImageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12#\n" +
+	"\rmissing_blobs\x18\x03 \x03(\tR\fmissingBlobs\"\x1a\n" +
+	"\x18ListCorruptImagesRequest\"i\n" +
+	"\x19ListCorruptImagesResponse\x12-\n" +
+	"\x06images\x18\x01 \x03(\v2\x15.tote.v1.CorruptImageR\x06images\x12\x1d\n" +
+	"\n" +
+	"scanned_at\x18\x02 \x01(\x03R\tscannedAt\"K\n" +
+	"\fCorruptImage\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12#\n" +
+	"\rmissing_blobs\x18\x02 \x03(\tR\fmissingBlobs2\xad\x06\n" +
 	"\tToteAgent\x12N\n" +
 	"\rPrepareExport\x12\x1d.tote.v1.PrepareExportRequest\x1a\x1e.tote.v1.PrepareExportResponse\x12@\n" +
 	"\vExportImage\x12\x1b.tote.v1.ExportImageRequest\x1a\x12.tote.v1.DataChunk0\x01\x12E\n" +
@@ -745,7 +1305,13 @@ const file_api_v1_agent_proto_rawDesc = "" +
 	"\n" +
 	"ResolveTag\x12\x1a.tote.v1.ResolveTagRequest\x1a\x1b.tote.v1.ResolveTagResponse\x12H\n" +
 	"\vRemoveImage\x12\x1b.tote.v1.RemoveImageRequest\x1a\x1c.tote.v1.RemoveImageResponse\x12B\n" +
-	"\tPushImage\x12\x19.tote.v1.PushImageRequest\x1a\x1a.tote.v1.PushImageResponseB!Z\x1fgithub.com/ppiankov/tote/api/v1b\x06proto3"
+	"\tPushImage\x12\x19.tote.v1.PushImageRequest\x1a\x1a.tote.v1.PushImageResponse\x12E\n" +
+	"\n" +
+	"CheckImage\x12\x1a.tote.v1.CheckImageRequest\x1a\x1b.tote.v1.CheckImageResponse\x12>\n" +
+	"\n" +
+	"ExportBlob\x12\x1a.tote.v1.ExportBlobRequest\x1a\x12.tote.v1.DataChunk0\x01\x12H\n" +
+	"\vRepairImage\x12\x1b.tote.v1.RepairImageRequest\x1a\x1c.tote.v1.RepairImageResponse\x12Z\n" +
+	"\x11ListCorruptImages\x12!.tote.v1.ListCorruptImagesRequest\x1a\".tote.v1.ListCorruptImagesResponseB!Z\x1fgithub.com/ppiankov/tote/api/v1b\x06proto3"
 
 var (
 	file_api_v1_agent_proto_rawDescOnce sync.Once
@@ -759,43 +1325,60 @@ func file_api_v1_agent_proto_rawDescGZIP() []byte {
 	return file_api_v1_agent_proto_rawDescData
 }
 
-var file_api_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_api_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_api_v1_agent_proto_goTypes = []any{
-	(*PrepareExportRequest)(nil),  // 0: tote.v1.PrepareExportRequest
-	(*PrepareExportResponse)(nil), // 1: tote.v1.PrepareExportResponse
-	(*ExportImageRequest)(nil),    // 2: tote.v1.ExportImageRequest
-	(*DataChunk)(nil),             // 3: tote.v1.DataChunk
-	(*ImportFromRequest)(nil),     // 4: tote.v1.ImportFromRequest
-	(*ImportFromResponse)(nil),    // 5: tote.v1.ImportFromResponse
-	(*ListImagesRequest)(nil),     // 6: tote.v1.ListImagesRequest
-	(*ListImagesResponse)(nil),    // 7: tote.v1.ListImagesResponse
-	(*ResolveTagRequest)(nil),     // 8: tote.v1.ResolveTagRequest
-	(*ResolveTagResponse)(nil),    // 9: tote.v1.ResolveTagResponse
-	(*RemoveImageRequest)(nil),    // 10: tote.v1.RemoveImageRequest
-	(*RemoveImageResponse)(nil),   // 11: tote.v1.RemoveImageResponse
-	(*PushImageRequest)(nil),      // 12: tote.v1.PushImageRequest
-	(*PushImageResponse)(nil),     // 13: tote.v1.PushImageResponse
+	(*PrepareExportRequest)(nil),      // 0: tote.v1.PrepareExportRequest
+	(*PrepareExportResponse)(nil),     // 1: tote.v1.PrepareExportResponse
+	(*ExportImageRequest)(nil),        // 2: tote.v1.ExportImageRequest
+	(*DataChunk)(nil),                 // 3: tote.v1.DataChunk
+	(*ImportFromRequest)(nil),         // 4: tote.v1.ImportFromRequest
+	(*ImportFromResponse)(nil),        // 5: tote.v1.ImportFromResponse
+	(*ListImagesRequest)(nil),         // 6: tote.v1.ListImagesRequest
+	(*ListImagesResponse)(nil),        // 7: tote.v1.ListImagesResponse
+	(*ResolveTagRequest)(nil),         // 8: tote.v1.ResolveTagRequest
+	(*ResolveTagResponse)(nil),        // 9: tote.v1.ResolveTagResponse
+	(*RemoveImageRequest)(nil),        // 10: tote.v1.RemoveImageRequest
+	(*RemoveImageResponse)(nil),       // 11: tote.v1.RemoveImageResponse
+	(*PushImageRequest)(nil),          // 12: tote.v1.PushImageRequest
+	(*PushImageResponse)(nil),         // 13: tote.v1.PushImageResponse
+	(*CheckImageRequest)(nil),         // 14: tote.v1.CheckImageRequest
+	(*CheckImageResponse)(nil),        // 15: tote.v1.CheckImageResponse
+	(*ExportBlobRequest)(nil),         // 16: tote.v1.ExportBlobRequest
+	(*RepairImageRequest)(nil),        // 17: tote.v1.RepairImageRequest
+	(*RepairImageResponse)(nil),       // 18: tote.v1.RepairImageResponse
+	(*ListCorruptImagesRequest)(nil),  // 19: tote.v1.ListCorruptImagesRequest
+	(*ListCorruptImagesResponse)(nil), // 20: tote.v1.ListCorruptImagesResponse
+	(*CorruptImage)(nil),              // 21: tote.v1.CorruptImage
 }
 var file_api_v1_agent_proto_depIdxs = []int32{
-	0,  // 0: tote.v1.ToteAgent.PrepareExport:input_type -> tote.v1.PrepareExportRequest
-	2,  // 1: tote.v1.ToteAgent.ExportImage:input_type -> tote.v1.ExportImageRequest
-	4,  // 2: tote.v1.ToteAgent.ImportFrom:input_type -> tote.v1.ImportFromRequest
-	6,  // 3: tote.v1.ToteAgent.ListImages:input_type -> tote.v1.ListImagesRequest
-	8,  // 4: tote.v1.ToteAgent.ResolveTag:input_type -> tote.v1.ResolveTagRequest
-	10, // 5: tote.v1.ToteAgent.RemoveImage:input_type -> tote.v1.RemoveImageRequest
-	12, // 6: tote.v1.ToteAgent.PushImage:input_type -> tote.v1.PushImageRequest
-	1,  // 7: tote.v1.ToteAgent.PrepareExport:output_type -> tote.v1.PrepareExportResponse
-	3,  // 8: tote.v1.ToteAgent.ExportImage:output_type -> tote.v1.DataChunk
-	5,  // 9: tote.v1.ToteAgent.ImportFrom:output_type -> tote.v1.ImportFromResponse
-	7,  // 10: tote.v1.ToteAgent.ListImages:output_type -> tote.v1.ListImagesResponse
-	9,  // 11: tote.v1.ToteAgent.ResolveTag:output_type -> tote.v1.ResolveTagResponse
-	11, // 12: tote.v1.ToteAgent.RemoveImage:output_type -> tote.v1.RemoveImageResponse
-	13, // 13: tote.v1.ToteAgent.PushImage:output_type -> tote.v1.PushImageResponse
-	7,  // [7:14] is the sub-list for method output_type
-	0,  // [0:7] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	21, // 0: tote.v1.ListCorruptImagesResponse.images:type_name -> tote.v1.CorruptImage
+	0,  // 1: tote.v1.ToteAgent.PrepareExport:input_type -> tote.v1.PrepareExportRequest
+	2,  // 2: tote.v1.ToteAgent.ExportImage:input_type -> tote.v1.ExportImageRequest
+	4,  // 3: tote.v1.ToteAgent.ImportFrom:input_type -> tote.v1.ImportFromRequest
+	6,  // 4: tote.v1.ToteAgent.ListImages:input_type -> tote.v1.ListImagesRequest
+	8,  // 5: tote.v1.ToteAgent.ResolveTag:input_type -> tote.v1.ResolveTagRequest
+	10, // 6: tote.v1.ToteAgent.RemoveImage:input_type -> tote.v1.RemoveImageRequest
+	12, // 7: tote.v1.ToteAgent.PushImage:input_type -> tote.v1.PushImageRequest
+	14, // 8: tote.v1.ToteAgent.CheckImage:input_type -> tote.v1.CheckImageRequest
+	16, // 9: tote.v1.ToteAgent.ExportBlob:input_type -> tote.v1.ExportBlobRequest
+	17, // 10: tote.v1.ToteAgent.RepairImage:input_type -> tote.v1.RepairImageRequest
+	19, // 11: tote.v1.ToteAgent.ListCorruptImages:input_type -> tote.v1.ListCorruptImagesRequest
+	1,  // 12: tote.v1.ToteAgent.PrepareExport:output_type -> tote.v1.PrepareExportResponse
+	3,  // 13: tote.v1.ToteAgent.ExportImage:output_type -> tote.v1.DataChunk
+	5,  // 14: tote.v1.ToteAgent.ImportFrom:output_type -> tote.v1.ImportFromResponse
+	7,  // 15: tote.v1.ToteAgent.ListImages:output_type -> tote.v1.ListImagesResponse
+	9,  // 16: tote.v1.ToteAgent.ResolveTag:output_type -> tote.v1.ResolveTagResponse
+	11, // 17: tote.v1.ToteAgent.RemoveImage:output_type -> tote.v1.RemoveImageResponse
+	13, // 18: tote.v1.ToteAgent.PushImage:output_type -> tote.v1.PushImageResponse
+	15, // 19: tote.v1.ToteAgent.CheckImage:output_type -> tote.v1.CheckImageResponse
+	3,  // 20: tote.v1.ToteAgent.ExportBlob:output_type -> tote.v1.DataChunk
+	18, // 21: tote.v1.ToteAgent.RepairImage:output_type -> tote.v1.RepairImageResponse
+	20, // 22: tote.v1.ToteAgent.ListCorruptImages:output_type -> tote.v1.ListCorruptImagesResponse
+	12, // [12:23] is the sub-list for method output_type
+	1,  // [1:12] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_api_v1_agent_proto_init() }
@@ -809,7 +1392,7 @@ func file_api_v1_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_v1_agent_proto_rawDesc), len(file_api_v1_agent_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
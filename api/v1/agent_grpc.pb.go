@@ -19,13 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ToteAgent_PrepareExport_FullMethodName = "/tote.v1.ToteAgent/PrepareExport"
-	ToteAgent_ExportImage_FullMethodName   = "/tote.v1.ToteAgent/ExportImage"
-	ToteAgent_ImportFrom_FullMethodName    = "/tote.v1.ToteAgent/ImportFrom"
-	ToteAgent_ListImages_FullMethodName    = "/tote.v1.ToteAgent/ListImages"
-	ToteAgent_ResolveTag_FullMethodName    = "/tote.v1.ToteAgent/ResolveTag"
-	ToteAgent_RemoveImage_FullMethodName   = "/tote.v1.ToteAgent/RemoveImage"
-	ToteAgent_PushImage_FullMethodName     = "/tote.v1.ToteAgent/PushImage"
+	ToteAgent_PrepareExport_FullMethodName     = "/tote.v1.ToteAgent/PrepareExport"
+	ToteAgent_ExportImage_FullMethodName       = "/tote.v1.ToteAgent/ExportImage"
+	ToteAgent_ImportFrom_FullMethodName        = "/tote.v1.ToteAgent/ImportFrom"
+	ToteAgent_ListImages_FullMethodName        = "/tote.v1.ToteAgent/ListImages"
+	ToteAgent_ResolveTag_FullMethodName        = "/tote.v1.ToteAgent/ResolveTag"
+	ToteAgent_RemoveImage_FullMethodName       = "/tote.v1.ToteAgent/RemoveImage"
+	ToteAgent_PushImage_FullMethodName         = "/tote.v1.ToteAgent/PushImage"
+	ToteAgent_CheckImage_FullMethodName        = "/tote.v1.ToteAgent/CheckImage"
+	ToteAgent_ExportBlob_FullMethodName        = "/tote.v1.ToteAgent/ExportBlob"
+	ToteAgent_RepairImage_FullMethodName       = "/tote.v1.ToteAgent/RepairImage"
+	ToteAgent_ListCorruptImages_FullMethodName = "/tote.v1.ToteAgent/ListCorruptImages"
 )
 
 // ToteAgentClient is the client API for ToteAgent service.
@@ -46,6 +50,14 @@ type ToteAgentClient interface {
 	RemoveImage(ctx context.Context, in *RemoveImageRequest, opts ...grpc.CallOption) (*RemoveImageResponse, error)
 	// Controller -> source agent: push image to a backup registry.
 	PushImage(ctx context.Context, in *PushImageRequest, opts ...grpc.CallOption) (*PushImageResponse, error)
+	// Controller -> agent: report which blobs of a local image are missing.
+	CheckImage(ctx context.Context, in *CheckImageRequest, opts ...grpc.CallOption) (*CheckImageResponse, error)
+	// Target agent -> source agent: stream one blob of the session's image.
+	ExportBlob(ctx context.Context, in *ExportBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataChunk], error)
+	// Controller -> target agent: fetch missing blobs from a peer or registry.
+	RepairImage(ctx context.Context, in *RepairImageRequest, opts ...grpc.CallOption) (*RepairImageResponse, error)
+	// Controller -> agent: report images the background scanner found corrupt.
+	ListCorruptImages(ctx context.Context, in *ListCorruptImagesRequest, opts ...grpc.CallOption) (*ListCorruptImagesResponse, error)
 }
 
 type toteAgentClient struct {
@@ -135,6 +147,55 @@ func (c *toteAgentClient) PushImage(ctx context.Context, in *PushImageRequest, o
 	return out, nil
 }
 
+func (c *toteAgentClient) CheckImage(ctx context.Context, in *CheckImageRequest, opts ...grpc.CallOption) (*CheckImageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckImageResponse)
+	err := c.cc.Invoke(ctx, ToteAgent_CheckImage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toteAgentClient) ExportBlob(ctx context.Context, in *ExportBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ToteAgent_ServiceDesc.Streams[1], ToteAgent_ExportBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportBlobRequest, DataChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ToteAgent_ExportBlobClient = grpc.ServerStreamingClient[DataChunk]
+
+func (c *toteAgentClient) RepairImage(ctx context.Context, in *RepairImageRequest, opts ...grpc.CallOption) (*RepairImageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RepairImageResponse)
+	err := c.cc.Invoke(ctx, ToteAgent_RepairImage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toteAgentClient) ListCorruptImages(ctx context.Context, in *ListCorruptImagesRequest, opts ...grpc.CallOption) (*ListCorruptImagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCorruptImagesResponse)
+	err := c.cc.Invoke(ctx, ToteAgent_ListCorruptImages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ToteAgentServer is the server API for ToteAgent service.
 // All implementations must embed UnimplementedToteAgentServer
 // for forward compatibility.
@@ -153,6 +214,14 @@ type ToteAgentServer interface {
 	RemoveImage(context.Context, *RemoveImageRequest) (*RemoveImageResponse, error)
 	// Controller -> source agent: push image to a backup registry.
 	PushImage(context.Context, *PushImageRequest) (*PushImageResponse, error)
+	// Controller -> agent: report which blobs of a local image are missing.
+	CheckImage(context.Context, *CheckImageRequest) (*CheckImageResponse, error)
+	// Target agent -> source agent: stream one blob of the session's image.
+	ExportBlob(*ExportBlobRequest, grpc.ServerStreamingServer[DataChunk]) error
+	// Controller -> target agent: fetch missing blobs from a peer or registry.
+	RepairImage(context.Context, *RepairImageRequest) (*RepairImageResponse, error)
+	// Controller -> agent: report images the background scanner found corrupt.
+	ListCorruptImages(context.Context, *ListCorruptImagesRequest) (*ListCorruptImagesResponse, error)
 	mustEmbedUnimplementedToteAgentServer()
 }
 
@@ -184,6 +253,18 @@ func (UnimplementedToteAgentServer) RemoveImage(context.Context, *RemoveImageReq
 func (UnimplementedToteAgentServer) PushImage(context.Context, *PushImageRequest) (*PushImageResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method PushImage not implemented")
 }
+func (UnimplementedToteAgentServer) CheckImage(context.Context, *CheckImageRequest) (*CheckImageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckImage not implemented")
+}
+func (UnimplementedToteAgentServer) ExportBlob(*ExportBlobRequest, grpc.ServerStreamingServer[DataChunk]) error {
+	return status.Error(codes.Unimplemented, "method ExportBlob not implemented")
+}
+func (UnimplementedToteAgentServer) RepairImage(context.Context, *RepairImageRequest) (*RepairImageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RepairImage not implemented")
+}
+func (UnimplementedToteAgentServer) ListCorruptImages(context.Context, *ListCorruptImagesRequest) (*ListCorruptImagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCorruptImages not implemented")
+}
 func (UnimplementedToteAgentServer) mustEmbedUnimplementedToteAgentServer() {}
 func (UnimplementedToteAgentServer) testEmbeddedByValue()                   {}
 
@@ -324,6 +405,71 @@ func _ToteAgent_PushImage_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ToteAgent_CheckImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToteAgentServer).CheckImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToteAgent_CheckImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToteAgentServer).CheckImage(ctx, req.(*CheckImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToteAgent_ExportBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ToteAgentServer).ExportBlob(m, &grpc.GenericServerStream[ExportBlobRequest, DataChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ToteAgent_ExportBlobServer = grpc.ServerStreamingServer[DataChunk]
+
+func _ToteAgent_RepairImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepairImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToteAgentServer).RepairImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToteAgent_RepairImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToteAgentServer).RepairImage(ctx, req.(*RepairImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToteAgent_ListCorruptImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCorruptImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToteAgentServer).ListCorruptImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToteAgent_ListCorruptImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToteAgentServer).ListCorruptImages(ctx, req.(*ListCorruptImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ToteAgent_ServiceDesc is the grpc.ServiceDesc for ToteAgent service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -355,6 +501,18 @@ var ToteAgent_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PushImage",
 			Handler:    _ToteAgent_PushImage_Handler,
 		},
+		{
+			MethodName: "CheckImage",
+			Handler:    _ToteAgent_CheckImage_Handler,
+		},
+		{
+			MethodName: "RepairImage",
+			Handler:    _ToteAgent_RepairImage_Handler,
+		},
+		{
+			MethodName: "ListCorruptImages",
+			Handler:    _ToteAgent_ListCorruptImages_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -362,6 +520,11 @@ var ToteAgent_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _ToteAgent_ExportImage_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExportBlob",
+			Handler:       _ToteAgent_ExportBlob_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/v1/agent.proto",
 }
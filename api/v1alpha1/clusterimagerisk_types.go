@@ -0,0 +1,122 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Risk levels of an image, from most to least severe.
+const (
+	// RiskNotActionable means the image is not pinned by digest and no digest
+	// could be resolved, so tote cannot salvage it.
+	RiskNotActionable = "NotActionable"
+
+	// RiskNoSource means the digest is known but no node caches it.
+	RiskNoSource = "NoSource"
+
+	// RiskSingleSource means exactly one node caches the image.
+	RiskSingleSource = "SingleSource"
+
+	// RiskLow means at least two nodes cache the image.
+	RiskLow = "Low"
+)
+
+// Availability values for registry and backup checks.
+const (
+	AvailabilityYes     = "Yes"
+	AvailabilityNo      = "No"
+	AvailabilityUnknown = "Unknown"
+)
+
+// ClusterImageRiskSpec identifies the workload a report covers.
+type ClusterImageRiskSpec struct {
+	// Namespace of the workload.
+	Namespace string `json:"namespace"`
+
+	// WorkloadKind is Deployment, StatefulSet, DaemonSet, Job, ReplicaSet or Pod.
+	WorkloadKind string `json:"workloadKind"`
+
+	// WorkloadName is the name of the workload.
+	WorkloadName string `json:"workloadName"`
+}
+
+// ImageRisk describes how salvageable one container image is.
+type ImageRisk struct {
+	// Container is the container name.
+	Container string `json:"container"`
+
+	// Image is the image reference from the pod spec.
+	Image string `json:"image"`
+
+	// Digest is the pinned digest, or the one resolved from node status or
+	// the source registry for tag-only images.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Pinned is true when the image reference includes a digest.
+	Pinned bool `json:"pinned"`
+
+	// CachedNodes is the number of nodes that have the digest cached.
+	CachedNodes int `json:"cachedNodes"`
+
+	// RegistryAvailable is Yes, No or Unknown: whether the source registry
+	// still serves the image. Unknown without --registry-resolve.
+	RegistryAvailable string `json:"registryAvailable"`
+
+	// BackupAvailable is Yes, No or Unknown: whether the backup registry has
+	// the image. Unknown without --backup-registry.
+	BackupAvailable string `json:"backupAvailable"`
+
+	// Risk is NotActionable, NoSource, SingleSource or Low.
+	Risk string `json:"risk"`
+}
+
+// ClusterImageRiskStatus is the latest assessment of the workload's images.
+type ClusterImageRiskStatus struct {
+	// Images lists every container image of the workload's pods.
+	// +optional
+	Images []ImageRisk `json:"images,omitempty"`
+
+	// AtRisk counts images whose risk is not Low.
+	AtRisk int `json:"atRisk"`
+
+	// Risk is the most severe risk of any image.
+	// +optional
+	Risk string `json:"risk,omitempty"`
+
+	// LastUpdated is when the report was computed (RFC3339).
+	// +optional
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=imagerisks;imagerisk
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.namespace`,priority=0
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.workloadKind`,priority=0
+// +kubebuilder:printcolumn:name="Workload",type=string,JSONPath=`.spec.workloadName`,priority=0
+// +kubebuilder:printcolumn:name="At Risk",type=integer,JSONPath=`.status.atRisk`,priority=0
+// +kubebuilder:printcolumn:name="Risk",type=string,JSONPath=`.status.risk`,priority=0
+// +kubebuilder:printcolumn:name="Updated",type=string,JSONPath=`.status.lastUpdated`,priority=1
+
+// ClusterImageRisk reports, for one opted-in workload, which of its images
+// tote could not salvage if the registry failed.
+type ClusterImageRisk struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterImageRiskSpec   `json:"spec,omitempty"`
+	Status ClusterImageRiskStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterImageRiskList contains a list of ClusterImageRisk.
+type ClusterImageRiskList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterImageRisk `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterImageRisk{}, &ClusterImageRiskList{})
+}
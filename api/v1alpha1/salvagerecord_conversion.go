@@ -0,0 +1,153 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
+)
+
+// AnnotationConversionData holds, as JSON, the v1alpha2 fields a v1alpha1
+// SalvageRecord has no place for, so a round trip through v1alpha1 (a
+// v1alpha1 client updating the record) keeps them.
+const AnnotationConversionData = "tote.dev/conversion-data"
+
+// conversionData is the v1alpha2-only part of a SalvageRecord.
+type conversionData struct {
+	Container          string                      `json:"container,omitempty"`
+	Workload           *v1alpha2.WorkloadReference `json:"workload,omitempty"`
+	ObservedGeneration int64                       `json:"observedGeneration,omitempty"`
+	StartedAt          *metav1.Time                `json:"startedAt,omitempty"`
+	Duration           *metav1.Duration            `json:"duration,omitempty"`
+	SizeBytes          int64                       `json:"sizeBytes,omitempty"`
+	BackupRef          string                      `json:"backupRef,omitempty"`
+	Phase              v1alpha2.SalvagePhase       `json:"phase,omitempty"`
+	Conditions         []metav1.Condition          `json:"conditions,omitempty"`
+}
+
+// ConvertTo converts this SalvageRecord to the v1alpha2 hub version. Fields
+// saved in AnnotationConversionData by ConvertFrom are restored; otherwise
+// the Salvaged condition is derived from the phase and v1alpha2-only fields
+// stay empty.
+func (src *SalvageRecord) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha2.SalvageRecord)
+	dst.ObjectMeta = src.ObjectMeta
+
+	var saved *conversionData
+	if raw, ok := src.Annotations[AnnotationConversionData]; ok {
+		saved = &conversionData{}
+		if err := json.Unmarshal([]byte(raw), saved); err != nil {
+			return fmt.Errorf("decoding %s: %w", AnnotationConversionData, err)
+		}
+		dst.Annotations = maps.Clone(src.Annotations)
+		delete(dst.Annotations, AnnotationConversionData)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec = v1alpha2.SalvageRecordSpec{
+		PodName:    src.Spec.PodName,
+		Digest:     src.Spec.Digest,
+		ImageRef:   src.Spec.ImageRef,
+		SourceNode: src.Spec.SourceNode,
+		TargetNode: src.Spec.TargetNode,
+	}
+
+	dst.Status = v1alpha2.SalvageRecordStatus{
+		Phase:              v1alpha2.SalvagePhase(src.Status.Phase),
+		ObservedGeneration: src.Generation,
+		Error:              src.Status.Error,
+	}
+	if t, err := time.Parse(time.RFC3339, src.Status.CompletedAt); err == nil {
+		completed := metav1.NewTime(t)
+		dst.Status.CompletedAt = &completed
+	}
+	if saved != nil {
+		saved.restore(dst)
+		if saved.Phase == dst.Status.Phase && saved.Conditions != nil {
+			// The phase is unchanged, so the saved conditions still hold.
+			dst.Status.Conditions = saved.Conditions
+			return nil
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:               v1alpha2.ConditionSalvaged,
+		ObservedGeneration: src.Generation,
+	}
+	switch dst.Status.Phase {
+	case v1alpha2.PhaseCompleted:
+		cond.Status, cond.Reason = metav1.ConditionTrue, "ImageImported"
+	case v1alpha2.PhaseFailed:
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, "SalvageFailed", src.Status.Error
+	default:
+		return nil
+	}
+	if dst.Status.CompletedAt != nil {
+		cond.LastTransitionTime = *dst.Status.CompletedAt
+	}
+	meta.SetStatusCondition(&dst.Status.Conditions, cond)
+	return nil
+}
+
+// ConvertFrom converts the v1alpha2 hub version to this SalvageRecord.
+// Fields v1alpha1 has no place for are saved in AnnotationConversionData.
+func (dst *SalvageRecord) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha2.SalvageRecord)
+	dst.ObjectMeta = src.ObjectMeta
+
+	data, err := json.Marshal(conversionData{
+		Container:          src.Spec.Container,
+		Workload:           src.Spec.Workload,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		StartedAt:          src.Status.StartedAt,
+		Duration:           src.Status.Duration,
+		SizeBytes:          src.Status.SizeBytes,
+		BackupRef:          src.Status.BackupRef,
+		Phase:              src.Status.Phase,
+		Conditions:         src.Status.Conditions,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", AnnotationConversionData, err)
+	}
+	dst.Annotations = maps.Clone(src.Annotations)
+	if dst.Annotations == nil {
+		dst.Annotations = make(map[string]string, 1)
+	}
+	dst.Annotations[AnnotationConversionData] = string(data)
+
+	dst.Spec = SalvageRecordSpec{
+		PodName:    src.Spec.PodName,
+		Digest:     src.Spec.Digest,
+		ImageRef:   src.Spec.ImageRef,
+		SourceNode: src.Spec.SourceNode,
+		TargetNode: src.Spec.TargetNode,
+	}
+
+	dst.Status = SalvageRecordStatus{
+		Phase: string(src.Status.Phase),
+		Error: src.Status.Error,
+	}
+	if src.Status.CompletedAt != nil {
+		dst.Status.CompletedAt = src.Status.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	return nil
+}
+
+// restore copies the saved fields onto dst.
+func (d *conversionData) restore(dst *v1alpha2.SalvageRecord) {
+	dst.Spec.Container = d.Container
+	dst.Spec.Workload = d.Workload
+	dst.Status.ObservedGeneration = d.ObservedGeneration
+	dst.Status.StartedAt = d.StartedAt
+	dst.Status.Duration = d.Duration
+	dst.Status.SizeBytes = d.SizeBytes
+	dst.Status.BackupRef = d.BackupRef
+}
@@ -0,0 +1,197 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
+)
+
+func TestSalvageRecordConvertTo(t *testing.T) {
+	src := &SalvageRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-e3b0c442", Namespace: "default", Generation: 1},
+		Spec: SalvageRecordSpec{
+			PodName:    "app",
+			Digest:     "sha256:e3b0c442",
+			ImageRef:   "registry.example.com/app:v1",
+			SourceNode: "node-a",
+			TargetNode: "node-b",
+		},
+		Status: SalvageRecordStatus{Phase: "Completed", CompletedAt: "2026-01-15T10:30:00Z"},
+	}
+
+	var dst v1alpha2.SalvageRecord
+	if err := src.ConvertTo(&dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != src.Name || dst.Spec.PodName != "app" || dst.Spec.SourceNode != "node-a" || dst.Spec.TargetNode != "node-b" {
+		t.Errorf("metadata or spec not carried over: %+v", dst)
+	}
+	if dst.Status.Phase != v1alpha2.PhaseCompleted {
+		t.Errorf("expected Completed, got %s", dst.Status.Phase)
+	}
+	want := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	if dst.Status.CompletedAt == nil || !dst.Status.CompletedAt.Time.Equal(want) {
+		t.Errorf("expected completedAt %v, got %v", want, dst.Status.CompletedAt)
+	}
+	cond := meta.FindStatusCondition(dst.Status.Conditions, v1alpha2.ConditionSalvaged)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected Salvaged=True, got %+v", cond)
+	}
+}
+
+func TestSalvageRecordConvertTo_Failed(t *testing.T) {
+	src := &SalvageRecord{
+		Status: SalvageRecordStatus{Phase: "Failed", CompletedAt: "not-a-time", Error: "no source"},
+	}
+
+	var dst v1alpha2.SalvageRecord
+	if err := src.ConvertTo(&dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Status.CompletedAt != nil {
+		t.Errorf("expected unparsable completedAt to be dropped, got %v", dst.Status.CompletedAt)
+	}
+	cond := meta.FindStatusCondition(dst.Status.Conditions, v1alpha2.ConditionSalvaged)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Message != "no source" {
+		t.Errorf("expected Salvaged=False with the error, got %+v", cond)
+	}
+}
+
+func TestSalvageRecordConvertFrom(t *testing.T) {
+	completed := metav1.NewTime(time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC))
+	src := &v1alpha2.SalvageRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-e3b0c442", Namespace: "default"},
+		Spec: v1alpha2.SalvageRecordSpec{
+			PodName:    "app",
+			Container:  "app",
+			Workload:   &v1alpha2.WorkloadReference{Kind: "Deployment", Name: "app"},
+			Digest:     "sha256:e3b0c442",
+			ImageRef:   "registry.example.com/app:v1",
+			SourceNode: "node-a",
+			TargetNode: "node-b",
+		},
+		Status: v1alpha2.SalvageRecordStatus{
+			Phase:       v1alpha2.PhaseCompleted,
+			CompletedAt: &completed,
+			SizeBytes:   1024,
+			BackupRef:   "backup.example.com/app:v1",
+		},
+	}
+
+	var dst SalvageRecord
+	if err := dst.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != src.Name || dst.Spec.Digest != src.Spec.Digest || dst.Spec.ImageRef != src.Spec.ImageRef {
+		t.Errorf("metadata or spec not carried over: %+v", dst)
+	}
+	if dst.Status.Phase != "Completed" || dst.Status.CompletedAt != "2026-01-15T10:30:00Z" {
+		t.Errorf("unexpected status %+v", dst.Status)
+	}
+
+	// Converting back keeps everything v1alpha1 can represent.
+	var back v1alpha2.SalvageRecord
+	if err := dst.ConvertTo(&back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Spec.PodName != src.Spec.PodName || !back.Status.CompletedAt.Equal(src.Status.CompletedAt) {
+		t.Errorf("round trip lost data: %+v", back)
+	}
+}
+
+func TestSalvageRecordRoundTrip(t *testing.T) {
+	started := metav1.NewTime(time.Date(2026, 1, 15, 10, 29, 58, 0, time.UTC))
+	completed := metav1.NewTime(time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC))
+	src := &v1alpha2.SalvageRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app-e3b0c442",
+			Namespace:   "default",
+			Annotations: map[string]string{"team": "payments"},
+		},
+		Spec: v1alpha2.SalvageRecordSpec{
+			PodName:    "app",
+			Container:  "app",
+			Workload:   &v1alpha2.WorkloadReference{Kind: "Deployment", Name: "app"},
+			Digest:     "sha256:e3b0c442",
+			ImageRef:   "registry.example.com/app:v1",
+			SourceNode: "node-a",
+			TargetNode: "node-b",
+		},
+		Status: v1alpha2.SalvageRecordStatus{
+			Phase:              v1alpha2.PhaseCompleted,
+			ObservedGeneration: 1,
+			StartedAt:          &started,
+			CompletedAt:        &completed,
+			Duration:           &metav1.Duration{Duration: 2 * time.Second},
+			SizeBytes:          1024,
+			BackupRef:          "backup.example.com/app:v1",
+			Conditions: []metav1.Condition{{
+				Type:               v1alpha2.ConditionSalvaged,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Imported",
+				Message:            "imported on node-b",
+				LastTransitionTime: completed,
+			}},
+		},
+	}
+	orig := src.DeepCopy()
+
+	var spoke SalvageRecord
+	if err := spoke.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src, orig) {
+		t.Error("ConvertFrom modified the source")
+	}
+	var back v1alpha2.SalvageRecord
+	if err := spoke.ConvertTo(&back); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := back.Annotations[AnnotationConversionData]; ok {
+		t.Error("conversion annotation leaked into v1alpha2")
+	}
+	if !equality.Semantic.DeepEqual(&back, orig) {
+		t.Errorf("round trip lost data:\n got %+v\nwant %+v", back, *orig)
+	}
+}
+
+func TestSalvageRecordRoundTrip_PhaseChanged(t *testing.T) {
+	src := &v1alpha2.SalvageRecord{
+		Spec: v1alpha2.SalvageRecordSpec{PodName: "app", Container: "app"},
+		Status: v1alpha2.SalvageRecordStatus{
+			Phase:     v1alpha2.PhaseCompleted,
+			SizeBytes: 1024,
+			Conditions: []metav1.Condition{{
+				Type:   v1alpha2.ConditionSalvaged,
+				Status: metav1.ConditionTrue,
+				Reason: "Imported",
+			}},
+		},
+	}
+
+	var spoke SalvageRecord
+	if err := spoke.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+	// A v1alpha1 client marks the record failed.
+	spoke.Status.Phase = "Failed"
+	spoke.Status.Error = "import aborted"
+
+	var back v1alpha2.SalvageRecord
+	if err := spoke.ConvertTo(&back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Spec.Container != "app" || back.Status.SizeBytes != 1024 {
+		t.Errorf("expected saved fields restored, got %+v", back)
+	}
+	cond := meta.FindStatusCondition(back.Status.Conditions, v1alpha2.ConditionSalvaged)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Message != "import aborted" {
+		t.Errorf("expected condition derived from the new phase, got %+v", cond)
+	}
+}
@@ -0,0 +1,129 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SalvageRequest phases.
+const (
+	RequestPending   = "Pending"
+	RequestRunning   = "Running"
+	RequestCompleted = "Completed"
+	RequestFailed    = "Failed"
+)
+
+// ConditionComplete is True once every target node has the image.
+const ConditionComplete = "Complete"
+
+// SalvageRequestSpec describes an image transfer to a set of nodes.
+// +kubebuilder:validation:XValidation:rule="has(self.digest) || has(self.imageRef)",message="digest or imageRef is required"
+// +kubebuilder:validation:XValidation:rule="has(self.targetNodes) || has(self.nodeSelector)",message="targetNodes or nodeSelector is required"
+type SalvageRequestSpec struct {
+	// Digest is the image content digest (sha256:...). Resolved from
+	// ImageRef via node status when empty.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// ImageRef is the image reference. Its name is recreated on the
+	// targets and it is required for PushToBackup.
+	// +optional
+	ImageRef string `json:"imageRef,omitempty"`
+
+	// TargetNodes lists the nodes to copy the image to.
+	// +optional
+	TargetNodes []string `json:"targetNodes,omitempty"`
+
+	// NodeSelector selects the target nodes by label, e.g. a new node pool.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// SourceNode pins the node to export from. By default any node that
+	// caches the digest is used.
+	// +optional
+	SourceNode string `json:"sourceNode,omitempty"`
+
+	// PushToBackup also pushes the image to the backup registry.
+	// +optional
+	PushToBackup bool `json:"pushToBackup,omitempty"`
+}
+
+// TargetStatus is the outcome of the transfer to one node.
+type TargetStatus struct {
+	// Node is the target node name.
+	Node string `json:"node"`
+
+	// Phase is Completed or Failed.
+	Phase string `json:"phase"`
+
+	// SourceNode is the node the image was copied from (empty if it was
+	// already cached on the target).
+	// +optional
+	SourceNode string `json:"sourceNode,omitempty"`
+
+	// Error is the failure reason.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// SalvageRequestStatus reports the progress of a SalvageRequest.
+type SalvageRequestStatus struct {
+	// Phase is Pending, Running, Completed or Failed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// ObservedGeneration is the spec generation the status describes.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Digest is the resolved image digest.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Targets lists the outcome per target node.
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// BackupRef is the backup registry reference when PushToBackup succeeded.
+	// +optional
+	BackupRef string `json:"backupRef,omitempty"`
+
+	// CompletedAt is when the request finished (RFC3339).
+	// +optional
+	CompletedAt string `json:"completedAt,omitempty"`
+
+	// Conditions hold the Complete condition.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.imageRef`,priority=0
+// +kubebuilder:printcolumn:name="Digest",type=string,JSONPath=`.status.digest`,priority=1
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// SalvageRequest asks tote to copy an image to a set of nodes, without a
+// failing pod. Each spec generation is carried out once.
+type SalvageRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SalvageRequestSpec   `json:"spec,omitempty"`
+	Status SalvageRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SalvageRequestList contains a list of SalvageRequest.
+type SalvageRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SalvageRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SalvageRequest{}, &SalvageRequestList{})
+}
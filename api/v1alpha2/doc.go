@@ -0,0 +1,4 @@
+// Package v1alpha2 contains the tote.dev/v1alpha2 API types.
+//
+// +groupName=tote.dev
+package v1alpha2
@@ -0,0 +1,17 @@
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the API group and version for tote CRDs.
+	GroupVersion = schema.GroupVersion{Group: "tote.dev", Version: "v1alpha2"}
+
+	// SchemeBuilder is used to add Go types to the GroupVersionResource scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
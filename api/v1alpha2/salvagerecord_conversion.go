@@ -0,0 +1,5 @@
+package v1alpha2
+
+// Hub marks v1alpha2 as the version other SalvageRecord versions convert
+// through.
+func (*SalvageRecord) Hub() {}
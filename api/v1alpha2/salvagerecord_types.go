@@ -0,0 +1,135 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SalvagePhase is the outcome of a salvage operation.
+// +kubebuilder:validation:Enum=Completed;Failed
+type SalvagePhase string
+
+const (
+	// PhaseCompleted means the image was imported on the target node.
+	PhaseCompleted SalvagePhase = "Completed"
+
+	// PhaseFailed means the salvage did not complete.
+	PhaseFailed SalvagePhase = "Failed"
+)
+
+// Condition types of a SalvageRecord.
+const (
+	// ConditionSalvaged is True once the image is on the target node.
+	ConditionSalvaged = "Salvaged"
+
+	// ConditionBackedUp reports the push to the backup registry. It is only
+	// set when a backup registry is configured.
+	ConditionBackedUp = "BackedUp"
+)
+
+// WorkloadReference names the workload that owns the salvaged pod.
+type WorkloadReference struct {
+	// Kind is Deployment, StatefulSet, DaemonSet, Job or ReplicaSet.
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload.
+	Name string `json:"name"`
+}
+
+// SalvageRecordSpec describes a salvage operation.
+type SalvageRecordSpec struct {
+	// PodName is the name of the pod that triggered the salvage.
+	PodName string `json:"podName"`
+
+	// Container is the container whose image failed to pull.
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Workload is the pod's owning workload (empty for standalone pods).
+	// +optional
+	Workload *WorkloadReference `json:"workload,omitempty"`
+
+	// Digest is the image content digest (sha256:...).
+	Digest string `json:"digest"`
+
+	// ImageRef is the original image reference from the pod spec.
+	ImageRef string `json:"imageRef"`
+
+	// SourceNode is the node the image was exported from.
+	SourceNode string `json:"sourceNode"`
+
+	// TargetNode is the node the image was imported to.
+	TargetNode string `json:"targetNode"`
+}
+
+// SalvageRecordStatus describes the outcome of a salvage operation.
+type SalvageRecordStatus struct {
+	// Phase is the current state: Completed or Failed.
+	Phase SalvagePhase `json:"phase"`
+
+	// ObservedGeneration is the generation the status describes.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartedAt is when the salvage started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is when the salvage finished.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// Duration is how long the transfer took.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// SizeBytes is the size of the transferred image.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// BackupRef is the backup registry reference when the image was pushed.
+	// +optional
+	BackupRef string `json:"backupRef,omitempty"`
+
+	// Error is the failure reason (empty on success).
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Conditions hold the Salvaged and BackedUp conditions.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Digest",type=string,JSONPath=`.spec.digest`,priority=0
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.sourceNode`,priority=0
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetNode`,priority=0
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,priority=0
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.sizeBytes`,priority=1
+// +kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.status.duration`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// SalvageRecord tracks a single image salvage operation.
+type SalvageRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SalvageRecordSpec   `json:"spec,omitempty"`
+	Status SalvageRecordStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SalvageRecordList contains a list of SalvageRecord.
+type SalvageRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SalvageRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SalvageRecord{}, &SalvageRecordList{})
+}
@@ -3,18 +3,28 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,8 +32,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 	"github.com/ppiankov/tote/internal/agent"
 	"github.com/ppiankov/tote/internal/cleanup"
 	"github.com/ppiankov/tote/internal/config"
@@ -33,11 +45,16 @@ import (
 	"github.com/ppiankov/tote/internal/inventory"
 	"github.com/ppiankov/tote/internal/metrics"
 	"github.com/ppiankov/tote/internal/notify"
+	"github.com/ppiankov/tote/internal/outage"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/recovery"
 	"github.com/ppiankov/tote/internal/registry"
 	"github.com/ppiankov/tote/internal/session"
+	"github.com/ppiankov/tote/internal/templates"
 	"github.com/ppiankov/tote/internal/tlsutil"
 	"github.com/ppiankov/tote/internal/transfer"
 	"github.com/ppiankov/tote/internal/version"
+	"github.com/ppiankov/tote/internal/webhook"
 )
 
 func main() {
@@ -71,96 +88,166 @@ func newRootCmd() *cobra.Command {
 	return root
 }
 
+// controllerOptions holds the controller command's flags.
+type controllerOptions struct {
+	enabled                bool
+	metricsAddr            string
+	maxConcurrentSalvages  int
+	sessionTTL             string
+	agentNamespace         string
+	agentGRPCPort          int
+	maxImageSize           int64
+	backupRegistry         string
+	backupRegistrySecret   string
+	backupRegistryInsecure bool
+	tlsCert                string
+	tlsKey                 string
+	tlsCA                  string
+	jsonLog                bool
+	salvageRecordTTL       string
+	webhookURL             string
+	webhookEvents          string
+	notifyConfig           string
+	notifyQueueSize        int
+	notifyWorkers          int
+	notifyMaxAttempts      int
+	notifySigningSecret    string
+	clusterName            string
+	notifyAggregation      string
+	notifyMaxPerMinute     int
+	registryResolve        bool
+	registryResolveTimeout string
+	registryResolveCA      string
+	registryInsecure       bool
+	corruptScanPoll        string
+	messageTemplates       string
+	imageRiskInterval      string
+	webhookPort            int
+	webhookCertDir         string
+	conversionService      string
+	maxSalvagesPerNode     int
+	maxSalvagesPerSource   int
+	preseedUnscheduled     bool
+	preseedMaxNodes        int
+	salvageStrategy        string
+	recoveryStrategy       string
+	podWebhookConfig       string
+	outageThreshold        int
+	outageWindow           string
+	outagePriority         int
+}
+
 func newControllerCmd() *cobra.Command {
-	var (
-		enabled                bool
-		metricsAddr            string
-		maxConcurrentSalvages  int
-		sessionTTL             string
-		agentNamespace         string
-		agentGRPCPort          int
-		maxImageSize           int64
-		backupRegistry         string
-		backupRegistrySecret   string
-		backupRegistryInsecure bool
-		tlsCert                string
-		tlsKey                 string
-		tlsCA                  string
-		jsonLog                bool
-		salvageRecordTTL       string
-		webhookURL             string
-		webhookEvents          string
-		registryResolve        bool
-		registryResolveTimeout string
-		registryResolveCA      string
-		registryInsecure       bool
-	)
+	var o controllerOptions
 
 	cmd := &cobra.Command{
 		Use:   "controller",
 		Short: "Run the tote controller (detects failures, orchestrates salvage)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := config.ValidateTLSFlags(tlsCert, tlsKey, tlsCA); err != nil {
+			if err := config.ValidateTLSFlags(o.tlsCert, o.tlsKey, o.tlsCA); err != nil {
 				return err
 			}
-			return runController(enabled, metricsAddr, maxConcurrentSalvages, sessionTTL, agentNamespace, agentGRPCPort, maxImageSize, backupRegistry, backupRegistrySecret, backupRegistryInsecure, tlsCert, tlsKey, tlsCA, jsonLog, salvageRecordTTL, webhookURL, webhookEvents, registryResolve, registryResolveTimeout, registryResolveCA, registryInsecure)
+			return runController(o)
 		},
 	}
 
-	cmd.Flags().BoolVar(&enabled, "enabled", true, "global kill switch for the operator")
-	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", ":8080", "address for the metrics endpoint")
-	cmd.Flags().IntVar(&maxConcurrentSalvages, "max-concurrent-salvages", config.DefaultMaxConcurrentSalvages, "max parallel salvage operations")
-	cmd.Flags().StringVar(&sessionTTL, "session-ttl", config.DefaultSessionTTL.String(), "session lifetime for salvage operations")
-	cmd.Flags().StringVar(&agentNamespace, "agent-namespace", "", "namespace where tote agents run (required for salvage)")
-	cmd.Flags().IntVar(&agentGRPCPort, "agent-grpc-port", config.DefaultAgentGRPCPort, "gRPC port for agent communication")
-	cmd.Flags().Int64Var(&maxImageSize, "max-image-size", config.DefaultMaxImageSize, "max image size in bytes for salvage (0 = no limit)")
-	cmd.Flags().StringVar(&backupRegistry, "backup-registry", "", "registry host to push salvaged images (empty = disabled)")
-	cmd.Flags().StringVar(&backupRegistrySecret, "backup-registry-secret", "", "name of dockerconfigjson Secret for backup registry credentials")
-	cmd.Flags().BoolVar(&backupRegistryInsecure, "backup-registry-insecure", false, "allow HTTP connections to backup registry")
-	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "path to TLS certificate file (enables mTLS when all three TLS flags are set)")
-	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "path to TLS private key file")
-	cmd.Flags().StringVar(&tlsCA, "tls-ca", "", "path to CA certificate file")
-	cmd.Flags().BoolVar(&jsonLog, "json-log", false, "output logs in JSON format")
-	cmd.Flags().StringVar(&salvageRecordTTL, "salvagerecord-ttl", "168h", "time-to-live for completed SalvageRecords")
-	cmd.Flags().StringVar(&webhookURL, "webhook-url", "", "URL for webhook notifications (empty = disabled)")
-	cmd.Flags().StringVar(&webhookEvents, "webhook-events", "", "comma-separated event types to send (empty = all)")
-	cmd.Flags().BoolVar(&registryResolve, "registry-resolve", false, "enable registry-assisted tag resolution for tag-only images")
-	cmd.Flags().StringVar(&registryResolveTimeout, "registry-resolve-timeout", config.DefaultRegistryResolveTimeout.String(), "timeout for registry tag resolution requests")
-	cmd.Flags().StringVar(&registryResolveCA, "registry-resolve-ca", "", "path to CA certificate for source registry TLS")
-	cmd.Flags().BoolVar(&registryInsecure, "registry-insecure", false, "allow HTTP connections to source registries")
+	cmd.Flags().BoolVar(&o.enabled, "enabled", true, "global kill switch for the operator")
+	cmd.Flags().StringVar(&o.metricsAddr, "metrics-addr", ":8080", "address for the metrics endpoint")
+	cmd.Flags().IntVar(&o.maxConcurrentSalvages, "max-concurrent-salvages", config.DefaultMaxConcurrentSalvages, "max parallel salvage operations")
+	cmd.Flags().IntVar(&o.maxSalvagesPerNode, "max-salvages-per-node", config.DefaultMaxSalvagesPerNode, "max parallel salvages onto one node (0 = unlimited)")
+	cmd.Flags().IntVar(&o.maxSalvagesPerSource, "max-salvages-per-source", config.DefaultMaxSalvagesPerSource, "max parallel salvages served by one node (0 = unlimited)")
+	cmd.Flags().BoolVar(&o.preseedUnscheduled, "preseed-unscheduled", false, "copy a failing workload's image onto nodes its unscheduled replicas can land on")
+	cmd.Flags().IntVar(&o.preseedMaxNodes, "preseed-max-nodes", config.DefaultPreseedMaxNodes, "max nodes pre-seeded per image (0 = unlimited)")
+	cmd.Flags().StringVar(&o.salvageStrategy, "salvage-strategy", config.StrategyTransfer, "default fix for pull failures: transfer (copy the image to the pod's node) or reschedule (delete the pod and steer its replacement onto nodes caching the image)")
+	cmd.Flags().IntVar(&o.outageThreshold, "registry-outage-threshold", config.DefaultRegistryOutageThreshold, "pods failing to pull from one registry because it is unreachable, rate limiting or failing TLS that declare a registry outage (0 = disabled)")
+	cmd.Flags().StringVar(&o.outageWindow, "registry-outage-window", config.DefaultRegistryOutageWindow.String(), "how recent those pull failures must be; an outage ends after a window without one")
+	cmd.Flags().IntVar(&o.outagePriority, "registry-outage-priority", 0, "added to the queue priority of salvages whose image is on a registry in an outage")
+	cmd.Flags().StringVar(&o.sessionTTL, "session-ttl", config.DefaultSessionTTL.String(), "session lifetime for salvage operations")
+	cmd.Flags().StringVar(&o.agentNamespace, "agent-namespace", "", "namespace where tote agents run (required for salvage)")
+	cmd.Flags().IntVar(&o.agentGRPCPort, "agent-grpc-port", config.DefaultAgentGRPCPort, "gRPC port for agent communication")
+	cmd.Flags().Int64Var(&o.maxImageSize, "max-image-size", config.DefaultMaxImageSize, "max image size in bytes for salvage (0 = no limit)")
+	cmd.Flags().StringVar(&o.backupRegistry, "backup-registry", "", "registry host to push salvaged images (empty = disabled)")
+	cmd.Flags().StringVar(&o.backupRegistrySecret, "backup-registry-secret", "", "name of dockerconfigjson Secret for backup registry credentials")
+	cmd.Flags().BoolVar(&o.backupRegistryInsecure, "backup-registry-insecure", false, "allow HTTP connections to backup registry")
+	cmd.Flags().StringVar(&o.tlsCert, "tls-cert", "", "path to TLS certificate file (enables mTLS when all three TLS flags are set)")
+	cmd.Flags().StringVar(&o.tlsKey, "tls-key", "", "path to TLS private key file")
+	cmd.Flags().StringVar(&o.tlsCA, "tls-ca", "", "path to CA certificate file")
+	cmd.Flags().BoolVar(&o.jsonLog, "json-log", false, "output logs in JSON format")
+	cmd.Flags().StringVar(&o.salvageRecordTTL, "salvagerecord-ttl", "168h", "time-to-live for completed SalvageRecords")
+	cmd.Flags().StringVar(&o.webhookURL, "webhook-url", "", "URL for webhook notifications (empty = disabled)")
+	cmd.Flags().StringVar(&o.webhookEvents, "webhook-events", "", "comma-separated event types to send (empty = all)")
+	cmd.Flags().StringVar(&o.notifyConfig, "notify-config", "", "path to notification sinks config file (Slack, Teams, PagerDuty, webhook)")
+	cmd.Flags().IntVar(&o.notifyQueueSize, "notify-queue-size", config.DefaultNotifyQueueSize, "max notifications waiting for delivery; overflow is dead-lettered")
+	cmd.Flags().IntVar(&o.notifyWorkers, "notify-workers", config.DefaultNotifyWorkers, "concurrent notification deliveries")
+	cmd.Flags().IntVar(&o.notifyMaxAttempts, "notify-max-attempts", config.DefaultNotifyMaxAttempts, "delivery attempts per notification before it is dead-lettered")
+	cmd.Flags().StringVar(&o.clusterName, "cluster-name", "", "cluster name included in notifications")
+	cmd.Flags().StringVar(&o.notifyAggregation, "notify-aggregation-window", config.DefaultNotifyAggregationWindow.String(), "window for coalescing a workload's per-pod notifications into one (0 = disabled)")
+	cmd.Flags().IntVar(&o.notifyMaxPerMinute, "notify-max-per-minute", config.DefaultNotifyMaxPerMinute, "max notifications sent per minute across all sinks (0 = unlimited)")
+	cmd.Flags().StringVar(&o.messageTemplates, "message-templates", "", "path to Go text/template overrides for event and notification messages")
+	cmd.Flags().StringVar(&o.notifySigningSecret, "notify-signing-secret", "", "name of Secret whose signing-key signs notifications (X-Tote-Signature)")
+	cmd.Flags().BoolVar(&o.registryResolve, "registry-resolve", false, "enable registry-assisted tag resolution for tag-only images")
+	cmd.Flags().StringVar(&o.registryResolveTimeout, "registry-resolve-timeout", config.DefaultRegistryResolveTimeout.String(), "timeout for registry tag resolution requests")
+	cmd.Flags().StringVar(&o.registryResolveCA, "registry-resolve-ca", "", "path to CA certificate for source registry TLS")
+	cmd.Flags().BoolVar(&o.registryInsecure, "registry-insecure", false, "allow HTTP connections to source registries")
+	cmd.Flags().StringVar(&o.imageRiskInterval, "image-risk-interval", config.DefaultImageRiskInterval.String(), "interval for refreshing ClusterImageRisk reports (0 = disabled)")
+	cmd.Flags().StringVar(&o.corruptScanPoll, "corrupt-scan-poll-interval", config.DefaultCorruptScanPollInterval.String(), "interval for collecting agent corrupt-content scan results (0 = disabled)")
+	cmd.Flags().IntVar(&o.webhookPort, "webhook-port", config.DefaultWebhookPort, "port for the webhook server")
+	cmd.Flags().StringVar(&o.webhookCertDir, "webhook-cert-dir", "", "directory with tls.crt and tls.key for the webhook server; enables SalvageRecord conversion (empty = disabled)")
+	cmd.Flags().StringVar(&o.recoveryStrategy, "recovery", config.RecoveryDelete, "default restart of a pod once its image is fixed: delete, wait (kubelet pull backoff), evict (Eviction API, honours PodDisruptionBudgets) or restart (rollout restart of its workload)")
+	cmd.Flags().StringVar(&o.podWebhookConfig, "pod-webhook-config", "", "name of the MutatingWebhookConfiguration steering rescheduled pods; its CA bundle is set from ca.crt in --webhook-cert-dir")
+	cmd.Flags().StringVar(&o.conversionService, "conversion-webhook-service", "", "namespace/name of the webhook Service; points the SalvageRecord CRD conversion at it using ca.crt from --webhook-cert-dir")
 
 	return cmd
 }
 
+// agentOptions holds the agent command's flags.
+type agentOptions struct {
+	containerdSocket string
+	grpcPort         int
+	metricsAddr      string
+	tlsCert          string
+	tlsKey           string
+	tlsCA            string
+	jsonLog          bool
+	scanInterval     string
+	scanRehash       string
+	exportBandwidth  string
+	importBandwidth  string
+	maxExports       int
+	nodeName         string
+	chunkSize        string
+	compression      string
+}
+
 func newAgentCmd() *cobra.Command {
-	var (
-		containerdSocket string
-		grpcPort         int
-		metricsAddr      string
-		tlsCert          string
-		tlsKey           string
-		tlsCA            string
-		jsonLog          bool
-	)
+	var o agentOptions
 
 	cmd := &cobra.Command{
 		Use:   "agent",
 		Short: "Run the tote agent (serves images from local containerd)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := config.ValidateTLSFlags(tlsCert, tlsKey, tlsCA); err != nil {
+			if err := config.ValidateTLSFlags(o.tlsCert, o.tlsKey, o.tlsCA); err != nil {
 				return err
 			}
-			return runAgent(containerdSocket, grpcPort, metricsAddr, tlsCert, tlsKey, tlsCA, jsonLog)
+			return runAgent(o)
 		},
 	}
 
-	cmd.Flags().StringVar(&containerdSocket, "containerd-socket", config.DefaultContainerdSocket, "path to containerd socket")
-	cmd.Flags().IntVar(&grpcPort, "grpc-port", config.DefaultAgentGRPCPort, "gRPC listen port")
-	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", ":8081", "address for the metrics endpoint")
-	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "path to TLS certificate file (enables mTLS when all three TLS flags are set)")
-	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "path to TLS private key file")
-	cmd.Flags().StringVar(&tlsCA, "tls-ca", "", "path to CA certificate file")
-	cmd.Flags().BoolVar(&jsonLog, "json-log", false, "output logs in JSON format")
+	cmd.Flags().StringVar(&o.containerdSocket, "containerd-socket", config.DefaultContainerdSocket, "path to containerd socket")
+	cmd.Flags().IntVar(&o.grpcPort, "grpc-port", config.DefaultAgentGRPCPort, "gRPC listen port")
+	cmd.Flags().StringVar(&o.metricsAddr, "metrics-addr", ":8081", "address for the metrics endpoint")
+	cmd.Flags().StringVar(&o.tlsCert, "tls-cert", "", "path to TLS certificate file (enables mTLS when all three TLS flags are set)")
+	cmd.Flags().StringVar(&o.tlsKey, "tls-key", "", "path to TLS private key file")
+	cmd.Flags().StringVar(&o.tlsCA, "tls-ca", "", "path to CA certificate file")
+	cmd.Flags().BoolVar(&o.jsonLog, "json-log", false, "output logs in JSON format")
+	cmd.Flags().StringVar(&o.scanInterval, "scan-interval", config.DefaultAgentScanInterval.String(), "interval between checks that every image's content is present (0 = disabled)")
+	cmd.Flags().StringVar(&o.scanRehash, "scan-rehash-interval", "0s", "interval between scans that re-hash all image content (0 = never)")
+	cmd.Flags().StringVar(&o.exportBandwidth, "export-bandwidth", "0", "max bytes per second sent by exports, e.g. 50Mi (0 = unlimited)")
+	cmd.Flags().StringVar(&o.importBandwidth, "import-bandwidth", "0", "max bytes per second received by imports, e.g. 50Mi (0 = unlimited)")
+	cmd.Flags().IntVar(&o.maxExports, "max-exports", config.DefaultAgentMaxExports, "max exports served at once (0 = unlimited)")
+	cmd.Flags().StringVar(&o.nodeName, "node-name", "", "name of this agent's node; its tote.dev throttle labels override the flags (empty = flags only)")
+	cmd.Flags().StringVar(&o.chunkSize, "chunk-size", "32Ki", "size of the checksummed chunks exports are sent in (4Ki-2Mi)")
+	cmd.Flags().StringVar(&o.compression, "transfer-compression", config.DefaultAgentCompression, "export stream compression, used when the receiving agent supports it: zstd, gzip or none")
 
 	return cmd
 }
@@ -198,23 +285,48 @@ func newDoctorCmd() *cobra.Command {
 	return cmd
 }
 
-func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int, sessionTTLStr, agentNamespace string, agentGRPCPort int, maxImageSize int64, backupRegistry, backupRegistrySecret string, backupRegistryInsecure bool, tlsCert, tlsKey, tlsCA string, jsonLog bool, salvageRecordTTLStr, webhookURL, webhookEvents string, registryResolve bool, registryResolveTimeoutStr, registryResolveCA string, registryInsecure bool) error {
-	if jsonLog {
+func runController(o controllerOptions) error {
+	if o.jsonLog {
 		ctrl.SetLogger(zap.New())
 	} else {
 		ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 	}
 
+	if err := config.ValidateSalvageStrategy(o.salvageStrategy); err != nil {
+		return err
+	}
+	if err := config.ValidateRecovery(o.recoveryStrategy); err != nil {
+		return err
+	}
+	podWebhook := o.webhookCertDir != "" && o.podWebhookConfig != ""
+	if o.salvageStrategy == config.StrategyReschedule && !podWebhook {
+		return fmt.Errorf("--salvage-strategy=%s requires --webhook-cert-dir and --pod-webhook-config", config.StrategyReschedule)
+	}
+
+	// Message templates are validated before anything starts.
+	var tmpls *templates.Set
+	if o.messageTemplates != "" {
+		var err error
+		tmpls, err = templates.Load(o.messageTemplates, events.Reasons, notify.EventTypes)
+		if err != nil {
+			return err
+		}
+	}
+
 	scheme := runtime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(appsv1.AddToScheme(scheme))
 	utilruntime.Must(batchv1.AddToScheme(scheme))
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha2.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(admissionregistrationv1.AddToScheme(scheme))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restCfg := ctrl.GetConfigOrDie()
+	opts := ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
-			BindAddress: metricsAddr,
+			BindAddress: o.metricsAddr,
 		},
 		HealthProbeBindAddress: ":8081",
 		LeaderElection:         true,
@@ -224,11 +336,34 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 				&corev1.Pod{}: {Transform: stripPodFields},
 			},
 		},
-	})
+	}
+	if o.webhookCertDir != "" {
+		opts.WebhookServer = ctrlwebhook.NewServer(ctrlwebhook.Options{Port: o.webhookPort, CertDir: o.webhookCertDir})
+	}
+	mgr, err := ctrl.NewManager(restCfg, opts)
 	if err != nil {
 		return fmt.Errorf("creating manager: %w", err)
 	}
 
+	// SalvageRecord conversion between v1alpha1 and v1alpha2, and pod
+	// steering for the reschedule salvage strategy.
+	if o.webhookCertDir != "" {
+		if err := ctrl.NewWebhookManagedBy(mgr, &v1alpha2.SalvageRecord{}).Complete(); err != nil {
+			return fmt.Errorf("setting up conversion webhook: %w", err)
+		}
+		mgr.GetWebhookServer().Register(webhook.ReschedulePath, &ctrlwebhook.Admission{Handler: &webhook.PodSteerer{Owners: owners.NewResolver(mgr.GetAPIReader(), 0)}})
+		if o.podWebhookConfig != "" {
+			if err := enablePodWebhook(restCfg, scheme, o.webhookCertDir, o.podWebhookConfig); err != nil {
+				return err
+			}
+		}
+		if o.conversionService != "" {
+			if err := enableConversion(restCfg, scheme, o.webhookCertDir, o.conversionService); err != nil {
+				return err
+			}
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		return fmt.Errorf("setting up healthz check: %w", err)
 	}
@@ -237,15 +372,23 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 	}
 
 	cfg := config.New()
-	cfg.Enabled = enabled
-	cfg.AgentNamespace = agentNamespace
-	cfg.AgentGRPCPort = agentGRPCPort
-	cfg.MaxConcurrentSalvages = maxConcurrentSalvages
-	cfg.MaxImageSize = maxImageSize
+	cfg.Enabled = o.enabled
+	cfg.AgentNamespace = o.agentNamespace
+	cfg.AgentGRPCPort = o.agentGRPCPort
+	cfg.MaxConcurrentSalvages = o.maxConcurrentSalvages
+	cfg.MaxSalvagesPerNode = o.maxSalvagesPerNode
+	cfg.MaxSalvagesPerSource = o.maxSalvagesPerSource
+	cfg.PreseedUnscheduled = o.preseedUnscheduled
+	cfg.PreseedMaxNodes = o.preseedMaxNodes
+	cfg.SalvageStrategy = o.salvageStrategy
+	cfg.PodWebhook = podWebhook
+	cfg.Recovery = o.recoveryStrategy
+	cfg.RegistryOutagePriority = o.outagePriority
+	cfg.MaxImageSize = o.maxImageSize
 
 	sessionTTL := config.DefaultSessionTTL
-	if sessionTTLStr != "" && sessionTTLStr != config.DefaultSessionTTL.String() {
-		parsed, err := time.ParseDuration(sessionTTLStr)
+	if o.sessionTTL != "" && o.sessionTTL != config.DefaultSessionTTL.String() {
+		parsed, err := time.ParseDuration(o.sessionTTL)
 		if err != nil {
 			return fmt.Errorf("invalid session-ttl: %w", err)
 		}
@@ -255,6 +398,12 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 
 	m := metrics.NewCounters(ctrlmetrics.Registry)
 	emitter := events.NewEmitter(mgr.GetEventRecorder("tote"))
+	emitter.Templates = tmpls
+
+	// Owners of any kind are read as metadata through the API reader, so no
+	// informer is started per owner kind.
+	ownerResolver := owners.NewResolver(mgr.GetAPIReader(), owners.DefaultTTL)
+	emitter.Owners = ownerResolver
 
 	reconciler := &controller.PodReconciler{
 		Client:  mgr.GetClient(),
@@ -262,21 +411,45 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 		Finder:  inventory.NewFinder(mgr.GetClient()),
 		Emitter: emitter,
 		Metrics: m,
+		Owners:  ownerResolver,
+	}
+	reconciler.Recoverer = recovery.New(mgr.GetClient(), ownerResolver, emitter, m, cfg.Recovery)
+	reconciler.APIReader = mgr.GetAPIReader()
+
+	// The namespace the controller runs in, from the downward API.
+	controllerNamespace := os.Getenv("POD_NAMESPACE")
+	if controllerNamespace == "" {
+		controllerNamespace = o.agentNamespace
+	}
+
+	// Registry outage detection across pods.
+	if o.outageThreshold > 0 {
+		outageWindow, err := time.ParseDuration(o.outageWindow)
+		if err != nil || outageWindow <= 0 {
+			return fmt.Errorf("invalid registry-outage-window %q: want a positive duration", o.outageWindow)
+		}
+		tracker := outage.NewTracker(o.outageThreshold, outageWindow, emitter, m)
+		// Outage events are recorded on the controller's own Namespace.
+		tracker.Namespace = controllerNamespace
+		if err := mgr.Add(tracker); err != nil {
+			return fmt.Errorf("adding registry outage tracker: %w", err)
+		}
+		reconciler.Outages = tracker
 	}
 
 	// Registry-assisted tag resolution (opt-in).
-	if registryResolve {
+	if o.registryResolve {
 		resolveTimeout := config.DefaultRegistryResolveTimeout
-		if registryResolveTimeoutStr != "" && registryResolveTimeoutStr != config.DefaultRegistryResolveTimeout.String() {
-			parsed, err := time.ParseDuration(registryResolveTimeoutStr)
+		if o.registryResolveTimeout != "" && o.registryResolveTimeout != config.DefaultRegistryResolveTimeout.String() {
+			parsed, err := time.ParseDuration(o.registryResolveTimeout)
 			if err != nil {
 				return fmt.Errorf("invalid registry-resolve-timeout: %w", err)
 			}
 			resolveTimeout = parsed
 		}
-		tagResolver := registry.NewHTTPTagResolver(resolveTimeout, registryInsecure)
-		if registryResolveCA != "" {
-			if err := tagResolver.WithCA(registryResolveCA); err != nil {
+		tagResolver := registry.NewHTTPTagResolver(resolveTimeout, o.registryInsecure)
+		if o.registryResolveCA != "" {
+			if err := tagResolver.WithCA(o.registryResolveCA); err != nil {
 				return fmt.Errorf("loading registry CA: %w", err)
 			}
 		}
@@ -284,13 +457,13 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 	}
 
 	// Set up salvage orchestrator if agent namespace is configured.
-	if agentNamespace != "" {
+	if o.agentNamespace != "" {
 		sessions := session.NewStore()
-		resolver := transfer.NewResolver(mgr.GetClient(), agentNamespace, agentGRPCPort)
+		resolver := transfer.NewResolver(mgr.GetClient(), o.agentNamespace, o.agentGRPCPort)
 
 		// Load mTLS client credentials for agent communication.
-		if config.TLSEnabled(tlsCert, tlsKey, tlsCA) {
-			clientCreds, err := tlsutil.ClientCredentials(tlsCert, tlsKey, tlsCA)
+		if config.TLSEnabled(o.tlsCert, o.tlsKey, o.tlsCA) {
+			clientCreds, err := tlsutil.ClientCredentials(o.tlsCert, o.tlsKey, o.tlsCA)
 			if err != nil {
 				return fmt.Errorf("loading TLS credentials: %w", err)
 			}
@@ -300,23 +473,117 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 		reconciler.AgentResolver = resolver
 		orch := transfer.NewOrchestrator(
 			sessions, resolver, emitter, m, mgr.GetClient(),
-			maxConcurrentSalvages, sessionTTL, maxImageSize,
+			o.maxConcurrentSalvages, sessionTTL, o.maxImageSize,
 		)
 		orch.TransportCreds = resolver.TransportCreds
-		if backupRegistry != "" {
-			orch.SetBackupRegistry(backupRegistry, backupRegistrySecret, agentNamespace, backupRegistryInsecure)
+		orch.Owners = ownerResolver
+		orch.Recoverer = reconciler.Recoverer
+		if o.backupRegistry != "" {
+			orch.SetBackupRegistry(o.backupRegistry, o.backupRegistrySecret, o.agentNamespace, o.backupRegistryInsecure)
 		}
+		orch.SetNodeLimits(cfg.MaxSalvagesPerNode, cfg.MaxSalvagesPerSource)
 		reconciler.Orchestrator = orch
+
+		// Salvages wait in a prioritized queue for a free slot.
+		queue := transfer.NewQueue(orch, m)
+		if err := mgr.Add(queue); err != nil {
+			return fmt.Errorf("adding salvage queue: %w", err)
+		}
+		reconciler.Queue = queue
+
+		// Declarative transfers via SalvageRequest, accepted only in the
+		// controller's namespace.
+		requests := &controller.SalvageRequestReconciler{
+			Client:       mgr.GetClient(),
+			Config:       cfg,
+			Finder:       reconciler.Finder,
+			Orchestrator: orch,
+			Namespace:    controllerNamespace,
+		}
+		if err := requests.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("setting up salvage request controller: %w", err)
+		}
+
+		// Warn workloads about corrupt content found by agent scanners.
+		corruptScanPoll, err := time.ParseDuration(o.corruptScanPoll)
+		if err != nil {
+			return fmt.Errorf("invalid corrupt-scan-poll-interval: %w", err)
+		}
+		if corruptScanPoll > 0 {
+			poller := controller.NewCorruptScanPoller(mgr.GetClient(), cfg, resolver, emitter, corruptScanPoll)
+			poller.Owners = ownerResolver
+			if err := mgr.Add(poller); err != nil {
+				return fmt.Errorf("adding corrupt scan poller: %w", err)
+			}
+		}
 	}
 
-	// Webhook notifier (optional).
-	if webhookURL != "" {
-		var evtTypes []string
-		if webhookEvents != "" {
-			evtTypes = strings.Split(webhookEvents, ",")
+	// Image risk reports for opted-in workloads.
+	imageRiskInterval, err := time.ParseDuration(o.imageRiskInterval)
+	if err != nil {
+		return fmt.Errorf("invalid image-risk-interval: %w", err)
+	}
+	if imageRiskInterval > 0 {
+		reporter := controller.NewImageRiskReporter(mgr.GetClient(), cfg, reconciler.Finder, imageRiskInterval)
+		reporter.TagResolver = reconciler.TagResolver
+		reporter.Owners = ownerResolver
+		if reconciler.Orchestrator != nil && o.backupRegistry != "" {
+			backup := registry.NewHTTPTagResolver(config.DefaultRegistryResolveTimeout, o.backupRegistryInsecure)
+			backup.AuthFunc = reconciler.Orchestrator.BackupCredentials
+			reporter.BackupResolver = backup
+			reporter.BackupRegistry = o.backupRegistry
+		}
+		if err := mgr.Add(reporter); err != nil {
+			return fmt.Errorf("adding image risk reporter: %w", err)
+		}
+	}
+
+	// Notification sinks (optional).
+	notifier, err := buildNotifier(o.webhookURL, o.webhookEvents, o.notifyConfig, o.clusterName)
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		notifier.Templates = tmpls
+		if o.notifySigningSecret != "" {
+			if o.agentNamespace == "" {
+				return fmt.Errorf("--notify-signing-secret requires --agent-namespace")
+			}
+			key, err := loadSigningKey(context.Background(), mgr.GetAPIReader(), o.agentNamespace, o.notifySigningSecret)
+			if err != nil {
+				return err
+			}
+			notifier.SetSigningKey(key)
+		}
+		notifier.Metrics = m
+		// Coalesce per-pod events of a workload and cap the overall rate
+		// so an outage does not flood on-call channels.
+		notifyAggregation, err := time.ParseDuration(o.notifyAggregation)
+		if err != nil {
+			return fmt.Errorf("invalid notify-aggregation-window: %w", err)
+		}
+		if notifyAggregation > 0 {
+			notifier.EnableAggregation(notifyAggregation)
+		}
+		if o.notifyMaxPerMinute > 0 {
+			notifier.EnableRateLimit(o.notifyMaxPerMinute)
+		}
+		// Deliver from background workers so a slow receiver never holds
+		// up reconciliation.
+		notifier.EnableQueue(notify.QueueOptions{
+			Size:        o.notifyQueueSize,
+			Workers:     o.notifyWorkers,
+			MaxAttempts: o.notifyMaxAttempts,
+			Backoff:     config.NotifyRetryBackoff,
+			MaxBackoff:  config.NotifyMaxRetryBackoff,
+		})
+		if err := mgr.Add(notifier); err != nil {
+			return fmt.Errorf("adding notifier: %w", err)
 		}
-		notifier := notify.NewNotifier(webhookURL, evtTypes)
 		reconciler.Notifier = notifier
+		if reconciler.Outages != nil {
+			reconciler.Outages.Notifier = notifier
+		}
 		if reconciler.Orchestrator != nil {
 			reconciler.Orchestrator.Notifier = notifier
 		}
@@ -327,7 +594,7 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 	}
 
 	// SalvageRecord TTL cleanup.
-	recordTTL, err := time.ParseDuration(salvageRecordTTLStr)
+	recordTTL, err := time.ParseDuration(o.salvageRecordTTL)
 	if err != nil {
 		return fmt.Errorf("invalid salvagerecord-ttl: %w", err)
 	}
@@ -339,14 +606,95 @@ func runController(enabled bool, metricsAddr string, maxConcurrentSalvages int,
 	return mgr.Start(ctrl.SetupSignalHandler())
 }
 
+// enableConversion points the SalvageRecord CRD at the conversion webhook
+// served behind service ("namespace/name"), trusting certDir/ca.crt.
+func enableConversion(restCfg *rest.Config, scheme *runtime.Scheme, certDir, service string) error {
+	namespace, name, ok := strings.Cut(service, "/")
+	if !ok || namespace == "" || name == "" {
+		return fmt.Errorf("invalid conversion-webhook-service %q: want namespace/name", service)
+	}
+	return withWebhookCA(restCfg, scheme, certDir, func(ctx context.Context, c client.Client, caBundle []byte) error {
+		return webhook.EnableConversion(ctx, c, webhook.SalvageRecordCRD, namespace, name, caBundle)
+	})
+}
+
+// enablePodWebhook sets the CA bundle of the pod steering
+// MutatingWebhookConfiguration name to certDir/ca.crt.
+func enablePodWebhook(restCfg *rest.Config, scheme *runtime.Scheme, certDir, name string) error {
+	return withWebhookCA(restCfg, scheme, certDir, func(ctx context.Context, c client.Client, caBundle []byte) error {
+		return webhook.InjectCABundle(ctx, c, name, caBundle)
+	})
+}
+
+// withWebhookCA reads certDir/ca.crt and calls fn with a direct client.
+func withWebhookCA(restCfg *rest.Config, scheme *runtime.Scheme, certDir string, fn func(context.Context, client.Client, []byte) error) error {
+	caBundle, err := os.ReadFile(filepath.Join(certDir, "ca.crt"))
+	if err != nil {
+		return fmt.Errorf("reading webhook CA: %w", err)
+	}
+	// The manager's client is not usable before Start.
+	c, err := client.New(restCfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("creating client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return fn(ctx, c, caBundle)
+}
+
+// buildNotifier combines the --notify-config sinks with the legacy
+// --webhook-url sink. Returns nil when no sink is configured.
+func buildNotifier(webhookURL, webhookEvents, notifyConfig, clusterName string) (*notify.Notifier, error) {
+	var routes []notify.Route
+	if notifyConfig != "" {
+		cfg, err := notify.LoadConfig(notifyConfig)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Source == "" && clusterName != "" {
+			cfg.Source = "/tote/" + clusterName
+		}
+		if routes, err = cfg.Routes(); err != nil {
+			return nil, err
+		}
+	}
+	if webhookURL != "" {
+		var evtTypes []string
+		if webhookEvents != "" {
+			evtTypes = strings.Split(webhookEvents, ",")
+		}
+		routes = append(routes, notify.NewNotifier(webhookURL, evtTypes).Routes...)
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	return &notify.Notifier{Routes: routes, Cluster: clusterName}, nil
+}
+
+// loadSigningKey reads the notification HMAC key from a Secret.
+func loadSigningKey(ctx context.Context, c client.Reader, namespace, name string) ([]byte, error) {
+	var secret corev1.Secret
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &secret); err != nil {
+		return nil, fmt.Errorf("reading secret %s/%s: %w", namespace, name, err)
+	}
+	key := secret.Data[config.NotifySigningKeyField]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %s/%s missing %s key", namespace, name, config.NotifySigningKeyField)
+	}
+	return key, nil
+}
+
 // stripPodFields removes fields from Pod objects before they enter the informer
 // cache. The controller only needs a small subset of each pod; stripping the rest
 // reduces per-pod memory from ~15KB to ~1-2KB on sidecar-injected clusters.
 //
 // Retained fields:
 //   - metadata: name, namespace, annotations, ownerReferences, labels, uid, resourceVersion
-//   - spec: nodeName, containers[].name, containers[].image
-//   - status: containerStatuses[].state.waiting, initContainerStatuses[].state.waiting
+//   - spec: nodeName, imagePullSecrets, containers[].name, containers[].image,
+//     initContainers[].name, initContainers[].image, initContainers[].restartPolicy,
+//     ephemeralContainers[].name, ephemeralContainers[].image
+//   - status: state.waiting and imageID of containerStatuses, initContainerStatuses
+//     and ephemeralContainerStatuses
 func stripPodFields(obj any) (any, error) {
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
@@ -355,7 +703,6 @@ func stripPodFields(obj any) (any, error) {
 
 	// Strip spec fields we don't use.
 	pod.Spec.Volumes = nil
-	pod.Spec.InitContainers = nil
 	pod.Spec.SecurityContext = nil
 	pod.Spec.ServiceAccountName = ""
 	pod.Spec.SchedulerName = ""
@@ -364,20 +711,17 @@ func stripPodFields(obj any) (any, error) {
 	pod.Spec.TerminationGracePeriodSeconds = nil
 	for i := range pod.Spec.Containers {
 		c := &pod.Spec.Containers[i]
-		c.Command = nil
-		c.Args = nil
-		c.Env = nil
-		c.EnvFrom = nil
-		c.VolumeMounts = nil
-		c.Ports = nil
-		c.Resources = corev1.ResourceRequirements{}
-		c.LivenessProbe = nil
-		c.ReadinessProbe = nil
-		c.StartupProbe = nil
-		c.SecurityContext = nil
-		c.ImagePullPolicy = ""
-		c.TerminationMessagePath = ""
-		c.TerminationMessagePolicy = ""
+		*c = corev1.Container{Name: c.Name, Image: c.Image}
+	}
+	// Init containers keep their restart policy, which tells native
+	// sidecars apart.
+	for i := range pod.Spec.InitContainers {
+		c := &pod.Spec.InitContainers[i]
+		*c = corev1.Container{Name: c.Name, Image: c.Image, RestartPolicy: c.RestartPolicy}
+	}
+	for i := range pod.Spec.EphemeralContainers {
+		c := &pod.Spec.EphemeralContainers[i]
+		*c = corev1.EphemeralContainer{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: c.Name, Image: c.Image}}
 	}
 
 	// Strip status fields we don't use.
@@ -386,26 +730,9 @@ func stripPodFields(obj any) (any, error) {
 	pod.Status.HostIP = ""
 	pod.Status.PodIP = ""
 	pod.Status.StartTime = nil
-	for i := range pod.Status.ContainerStatuses {
-		s := &pod.Status.ContainerStatuses[i]
-		s.Ready = false
-		s.RestartCount = 0
-		s.ContainerID = ""
-		s.Started = nil
-		s.State.Running = nil
-		s.State.Terminated = nil
-		s.LastTerminationState = corev1.ContainerState{}
-	}
-	for i := range pod.Status.InitContainerStatuses {
-		s := &pod.Status.InitContainerStatuses[i]
-		s.Ready = false
-		s.RestartCount = 0
-		s.ContainerID = ""
-		s.Started = nil
-		s.State.Running = nil
-		s.State.Terminated = nil
-		s.LastTerminationState = corev1.ContainerState{}
-	}
+	stripContainerStatuses(pod.Status.ContainerStatuses)
+	stripContainerStatuses(pod.Status.InitContainerStatuses)
+	stripContainerStatuses(pod.Status.EphemeralContainerStatuses)
 
 	// Strip managed fields (metadata bloat from server-side apply).
 	pod.ManagedFields = nil
@@ -413,34 +740,76 @@ func stripPodFields(obj any) (any, error) {
 	return pod, nil
 }
 
-func runAgent(containerdSocket string, grpcPort int, metricsAddr, tlsCert, tlsKey, tlsCA string, jsonLog bool) error {
-	if jsonLog {
+// stripContainerStatuses keeps the fields detection and the corrupt image
+// scan read: name, image, imageID and the waiting state.
+func stripContainerStatuses(statuses []corev1.ContainerStatus) {
+	for i := range statuses {
+		s := &statuses[i]
+		*s = corev1.ContainerStatus{
+			Name:    s.Name,
+			Image:   s.Image,
+			ImageID: s.ImageID,
+			State:   corev1.ContainerState{Waiting: s.State.Waiting},
+		}
+	}
+}
+
+func runAgent(o agentOptions) error {
+	if o.jsonLog {
 		ctrl.SetLogger(zap.New())
 	} else {
 		ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 	}
 	logger := ctrl.Log.WithName("agent")
 
+	scanInterval, err := time.ParseDuration(o.scanInterval)
+	if err != nil {
+		return fmt.Errorf("invalid scan-interval: %w", err)
+	}
+	scanRehash, err := time.ParseDuration(o.scanRehash)
+	if err != nil {
+		return fmt.Errorf("invalid scan-rehash-interval: %w", err)
+	}
+	throttle := agent.ThrottleConfig{MaxExports: o.maxExports}
+	if throttle.ExportBytesPerSec, err = agent.ParseBandwidth(o.exportBandwidth); err != nil {
+		return fmt.Errorf("invalid export-bandwidth: %w", err)
+	}
+	if throttle.ImportBytesPerSec, err = agent.ParseBandwidth(o.importBandwidth); err != nil {
+		return fmt.Errorf("invalid import-bandwidth: %w", err)
+	}
+	if o.nodeName != "" {
+		throttle = nodeThrottle(o.nodeName, throttle)
+	}
+	chunkSize, err := agent.ParseChunkSize(o.chunkSize)
+	if err != nil {
+		return fmt.Errorf("invalid chunk-size: %w", err)
+	}
+	if err := agent.ValidateCompression(o.compression); err != nil {
+		return fmt.Errorf("invalid transfer-compression: %w", err)
+	}
+
 	// Hard fail if containerd socket is not accessible.
-	if _, err := os.Stat(containerdSocket); err != nil {
-		return fmt.Errorf("containerd socket %s: %w (agent requires containerd access)", containerdSocket, err)
+	if _, err := os.Stat(o.containerdSocket); err != nil {
+		return fmt.Errorf("containerd socket %s: %w (agent requires containerd access)", o.containerdSocket, err)
 	}
 
-	store, err := agent.NewContainerdStore(containerdSocket)
+	store, err := agent.NewContainerdStore(o.containerdSocket)
 	if err != nil {
 		return fmt.Errorf("connecting to containerd: %w", err)
 	}
 	defer func() { _ = store.Close() }()
 
 	sessions := session.NewStore()
-	srv := agent.NewServer(store, sessions, grpcPort)
+	srv := agent.NewServer(store, sessions, o.grpcPort)
+	srv.ChunkSize = chunkSize
+	srv.Compression = o.compression
 
-	if config.TLSEnabled(tlsCert, tlsKey, tlsCA) {
-		serverCreds, err := tlsutil.ServerCredentials(tlsCert, tlsKey, tlsCA)
+	if config.TLSEnabled(o.tlsCert, o.tlsKey, o.tlsCA) {
+		serverCreds, err := tlsutil.ServerCredentials(o.tlsCert, o.tlsKey, o.tlsCA)
 		if err != nil {
 			return fmt.Errorf("loading server TLS credentials: %w", err)
 		}
-		clientCreds, err := tlsutil.ClientCredentials(tlsCert, tlsKey, tlsCA)
+		clientCreds, err := tlsutil.ClientCredentials(o.tlsCert, o.tlsKey, o.tlsCA)
 		if err != nil {
 			return fmt.Errorf("loading client TLS credentials: %w", err)
 		}
@@ -449,6 +818,71 @@ func runAgent(containerdSocket string, grpcPort int, metricsAddr, tlsCert, tlsKe
 		logger.Info("mTLS enabled")
 	}
 
-	logger.Info("starting agent", "grpc-port", grpcPort, "containerd-socket", containerdSocket, "metrics-addr", metricsAddr)
-	return srv.Start(ctrl.SetupSignalHandler())
+	ctx := ctrl.SetupSignalHandler()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	gauges := metrics.NewAgentGauges(reg)
+	go serveAgentMetrics(ctx, o.metricsAddr, reg)
+	srv.Throttle = agent.NewThrottle(throttle, gauges)
+
+	// Background content scanner (finds corruption before a pod restarts).
+	if scanInterval > 0 {
+		scanner := agent.NewScanner(store, scanInterval, scanRehash)
+		scanner.Metrics = gauges
+		srv.Scanner = scanner
+		go func() { _ = scanner.Start(ctx) }()
+	}
+
+	logger.Info("starting agent", "grpc-port", o.grpcPort, "containerd-socket", o.containerdSocket, "metrics-addr", o.metricsAddr, "scan-interval", scanInterval, "scan-rehash-interval", scanRehash,
+		"export-bandwidth", throttle.ExportBytesPerSec, "import-bandwidth", throttle.ImportBytesPerSec, "max-exports", throttle.MaxExports,
+		"chunk-size", chunkSize, "transfer-compression", o.compression)
+	return srv.Start(ctx)
+}
+
+// nodeThrottle applies the throttle labels on the agent's node to the
+// flag-configured limits. Lookup or label errors are logged and the flags
+// are kept; labels are read once at startup.
+func nodeThrottle(nodeName string, flags agent.ThrottleConfig) agent.ThrottleConfig {
+	logger := ctrl.Log.WithName("agent")
+	restCfg, err := ctrl.GetConfig()
+	if err != nil {
+		logger.Error(err, "cannot read node labels, using flag throttle limits")
+		return flags
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		logger.Error(err, "cannot read node labels, using flag throttle limits")
+		return flags
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Error(err, "cannot read node labels, using flag throttle limits", "node", nodeName)
+		return flags
+	}
+	throttle, err := flags.WithNodeLabels(node.Labels)
+	if err != nil {
+		logger.Error(err, "invalid throttle label, using flag throttle limits", "node", nodeName)
+		return flags
+	}
+	return throttle
+}
+
+// serveAgentMetrics exposes the agent's Prometheus registry on addr until ctx
+// is cancelled. Failures are logged; metrics are not worth killing the agent.
+func serveAgentMetrics(ctx context.Context, addr string, reg *prometheus.Registry) {
+	logger := ctrl.Log.WithName("agent-metrics")
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "metrics server stopped", "addr", addr)
+	}
 }
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ppiankov/tote/internal/detector"
 )
 
 func TestStripPodFields(t *testing.T) {
@@ -36,7 +42,7 @@ func TestStripPodFields(t *testing.T) {
 				{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
 			},
 			InitContainers: []corev1.Container{
-				{Name: "init", Image: "busybox"},
+				{Name: "init", Image: "busybox", Command: []string{"/bin/sh"}},
 			},
 			Containers: []corev1.Container{
 				{
@@ -176,8 +182,10 @@ func TestStripPodFields(t *testing.T) {
 	if got.Spec.Volumes != nil {
 		t.Error("volumes not stripped")
 	}
-	if got.Spec.InitContainers != nil {
-		t.Error("initContainers not stripped")
+	if len(got.Spec.InitContainers) != 1 || got.Spec.InitContainers[0].Name != "init" || got.Spec.InitContainers[0].Image != "busybox" {
+		t.Errorf("initContainers name and image stripped: %+v", got.Spec.InitContainers)
+	} else if got.Spec.InitContainers[0].Command != nil {
+		t.Error("init container command not stripped")
 	}
 	if got.Spec.SecurityContext != nil {
 		t.Error("securityContext not stripped")
@@ -267,6 +275,63 @@ func TestStripPodFields(t *testing.T) {
 	}
 }
 
+func TestStripPodFields_DetectsEveryContainerKind(t *testing.T) {
+	waiting := func(name string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:         name,
+			RestartCount: 2,
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason:  "ImagePullBackOff",
+				Message: "Back-off pulling image",
+			}},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-abc123", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{Name: "migrate", Image: "registry.example.com/migrate:v1", Command: []string{"/migrate"}},
+				{Name: "proxy", Image: "registry.example.com/proxy:v1", RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways)},
+			},
+			Containers: []corev1.Container{{Name: "app", Image: "registry.example.com/app:v1"}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.36", Stdin: true},
+				TargetContainerName:      "app",
+			}},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses:      []corev1.ContainerStatus{waiting("migrate"), waiting("proxy")},
+			ContainerStatuses:          []corev1.ContainerStatus{{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+			EphemeralContainerStatuses: []corev1.ContainerStatus{waiting("debugger")},
+		},
+	}
+
+	result, err := stripPodFields(pod)
+	if err != nil {
+		t.Fatalf("stripPodFields returned error: %v", err)
+	}
+	cached := result.(*corev1.Pod)
+	if cached.Spec.EphemeralContainers[0].Stdin || cached.Status.EphemeralContainerStatuses[0].RestartCount != 0 {
+		t.Error("ephemeral container fields not stripped")
+	}
+
+	want := map[string]detector.Failure{
+		"migrate":  {Image: "registry.example.com/migrate:v1", Kind: detector.KindInit},
+		"proxy":    {Image: "registry.example.com/proxy:v1", Kind: detector.KindSidecar},
+		"debugger": {Image: "busybox:1.36", Kind: detector.KindEphemeral},
+	}
+	failures := detector.Detect(cached)
+	if len(failures) != len(want) {
+		t.Fatalf("expected %d failures on the cached pod, got %+v", len(want), failures)
+	}
+	for _, f := range failures {
+		w := want[f.ContainerName]
+		if f.Image != w.Image || f.Kind != w.Kind {
+			t.Errorf("%s: expected image %q kind %q, got %q %q", f.ContainerName, w.Image, w.Kind, f.Image, f.Kind)
+		}
+	}
+}
+
 func TestStripPodFields_NonPod(t *testing.T) {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{Name: "test"},
@@ -279,3 +344,52 @@ func TestStripPodFields_NonPod(t *testing.T) {
 		t.Error("non-pod object was modified")
 	}
 }
+
+func TestBuildNotifier(t *testing.T) {
+	if n, err := buildNotifier("", "", "", ""); err != nil || n != nil {
+		t.Fatalf("expected no notifier without sinks, got %v, %v", n, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "sinks.yaml")
+	cfg := "sinks:\n  - name: chat\n    type: slack\n    url: http://slack.example\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	n, err := buildNotifier("http://hook.example", "salvaged,salvage_failed", path, "prod-eu")
+	if err != nil {
+		t.Fatalf("buildNotifier: %v", err)
+	}
+	if len(n.Routes) != 2 || n.Routes[0].Name != "chat" || n.Routes[1].Name != "webhook" {
+		t.Errorf("expected config sink plus legacy webhook, got %+v", n.Routes)
+	}
+	if n.Cluster != "prod-eu" {
+		t.Errorf("expected cluster name on notifier, got %q", n.Cluster)
+	}
+
+	if _, err := buildNotifier("", "", filepath.Join(t.TempDir(), "missing.yaml"), ""); err == nil {
+		t.Error("expected error for missing config file")
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "tote-system"},
+			Data:       map[string][]byte{"signing-key": []byte("s3cret")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "tote-system"},
+		},
+	).Build()
+
+	key, err := loadSigningKey(context.Background(), cl, "tote-system", "notify")
+	if err != nil || string(key) != "s3cret" {
+		t.Fatalf("expected key s3cret, got %q, %v", key, err)
+	}
+	if _, err := loadSigningKey(context.Background(), cl, "tote-system", "empty"); err == nil {
+		t.Error("expected error for secret without signing-key")
+	}
+	if _, err := loadSigningKey(context.Background(), cl, "tote-system", "missing"); err == nil {
+		t.Error("expected error for missing secret")
+	}
+}
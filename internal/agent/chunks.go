@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+// Chunk size bounds. The upper bound keeps a chunk well under gRPC's default
+// 4 MiB message limit.
+const (
+	minChunkSize = 4 * 1024        // 4 KiB
+	maxChunkSize = 1024 * 1024 * 2 // 2 MiB
+)
+
+// ErrChecksumMismatch is returned when a received chunk or stream does not
+// match the SHA-256 the source sent with it.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ParseChunkSize parses a transfer chunk size such as "32Ki" or "1Mi".
+func ParseChunkSize(s string) (int, error) {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chunk size %q: %w", s, err)
+	}
+	n := q.Value()
+	if n < minChunkSize || n > maxChunkSize {
+		return 0, fmt.Errorf("invalid chunk size %q: must be between 4Ki and 2Mi", s)
+	}
+	return int(n), nil
+}
+
+// negotiateCompression compresses the stream's responses with the agent's
+// configured compressor if the receiving agent advertised support for it,
+// and sends them uncompressed otherwise.
+func (s *Server) negotiateCompression(ctx context.Context) {
+	if s.Compression == "" || s.Compression == CompressionNone {
+		return
+	}
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return
+	}
+	for _, name := range supported {
+		if name == s.Compression {
+			_ = grpc.SetSendCompressor(ctx, name)
+			return
+		}
+	}
+}
+
+// sendChunks sends everything read from r in chunkSize pieces, each with its
+// SHA-256, followed by a final message carrying the SHA-256 of the whole
+// stream.
+func sendChunks(stream grpc.ServerStreamingServer[v1.DataChunk], r io.Reader, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = config.DefaultAgentChunkSize
+	}
+	total := sha256.New()
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			sum := sha256.Sum256(buf[:n])
+			total.Write(buf[:n])
+			if sendErr := stream.Send(&v1.DataChunk{Data: buf[:n], Sha256: sum[:]}); sendErr != nil {
+				return fmt.Errorf("sending chunk: %w", sendErr)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading export: %w", err)
+		}
+	}
+	if err := stream.Send(&v1.DataChunk{StreamSha256: total.Sum(nil)}); err != nil {
+		return fmt.Errorf("sending stream checksum: %w", err)
+	}
+	return nil
+}
+
+// chunkReceiver verifies and unpacks a DataChunk stream. Streams from agents
+// that send no checksums are passed through unverified.
+type chunkReceiver struct {
+	recv    func() (*v1.DataChunk, error)
+	metrics *metrics.AgentGauges
+
+	total    hash.Hash
+	chunks   int
+	summed   bool // the source sent chunk checksums
+	finished bool // the stream checksum arrived and matched
+}
+
+func newChunkReceiver(recv func() (*v1.DataChunk, error), m *metrics.AgentGauges) *chunkReceiver {
+	return &chunkReceiver{recv: recv, metrics: m, total: sha256.New()}
+}
+
+// copyTo writes the stream's data to w until the stream ends. It fails on
+// the first chunk whose checksum does not match, and if the stream ends
+// without the checksum trailer the source promised.
+func (c *chunkReceiver) copyTo(w io.Writer) error {
+	for {
+		chunk, err := c.recv()
+		if err == io.EOF {
+			if c.summed && !c.finished {
+				return fmt.Errorf("stream ended without checksum: %w", io.ErrUnexpectedEOF)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("receiving chunk: %w", err)
+		}
+		if err := c.verify(chunk); err != nil {
+			if c.metrics != nil {
+				c.metrics.RecordChecksumFailure()
+			}
+			return err
+		}
+		if len(chunk.Data) == 0 {
+			continue
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return fmt.Errorf("writing chunk: %w", err)
+		}
+	}
+}
+
+func (c *chunkReceiver) verify(chunk *v1.DataChunk) error {
+	if c.finished {
+		return errors.New("data after stream checksum")
+	}
+	if chunk.StreamSha256 != nil {
+		if !bytes.Equal(chunk.StreamSha256, c.total.Sum(nil)) {
+			return fmt.Errorf("stream: %w", ErrChecksumMismatch)
+		}
+		c.finished = true
+		return nil
+	}
+	c.chunks++
+	if chunk.Sha256 != nil {
+		c.summed = true
+		sum := sha256.Sum256(chunk.Data)
+		if !bytes.Equal(chunk.Sha256, sum[:]) {
+			return fmt.Errorf("chunk %d: %w", c.chunks, ErrChecksumMismatch)
+		}
+	} else if c.summed {
+		return fmt.Errorf("chunk %d has no checksum: %w", c.chunks, ErrChecksumMismatch)
+	}
+	c.total.Write(chunk.Data)
+	return nil
+}
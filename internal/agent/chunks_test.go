@@ -0,0 +1,229 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/session"
+)
+
+func TestParseChunkSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"32Ki", 32 * 1024, false},
+		{"1Mi", 1024 * 1024, false},
+		{"1Ki", 0, true},
+		{"4Mi", 0, true},
+		{"big", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseChunkSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseChunkSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseChunkSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// chunkStream returns a recv func replaying chunks, then io.EOF.
+func chunkStream(chunks ...*v1.DataChunk) func() (*v1.DataChunk, error) {
+	return func() (*v1.DataChunk, error) {
+		if len(chunks) == 0 {
+			return nil, io.EOF
+		}
+		c := chunks[0]
+		chunks = chunks[1:]
+		return c, nil
+	}
+}
+
+func summedChunk(data string) *v1.DataChunk {
+	sum := sha256.Sum256([]byte(data))
+	return &v1.DataChunk{Data: []byte(data), Sha256: sum[:]}
+}
+
+func streamTrailer(data string) *v1.DataChunk {
+	sum := sha256.Sum256([]byte(data))
+	return &v1.DataChunk{StreamSha256: sum[:]}
+}
+
+func TestChunkReceiver(t *testing.T) {
+	corrupt := summedChunk("world")
+	corrupt.Data = []byte("w0rld")
+
+	tests := []struct {
+		name    string
+		chunks  []*v1.DataChunk
+		wantErr error
+	}{
+		{"verified", []*v1.DataChunk{summedChunk("hello "), summedChunk("world"), streamTrailer("hello world")}, nil},
+		{"unchecksummed source", []*v1.DataChunk{{Data: []byte("hello world")}}, nil},
+		{"corrupt chunk", []*v1.DataChunk{summedChunk("hello "), corrupt, streamTrailer("hello world")}, ErrChecksumMismatch},
+		{"wrong stream checksum", []*v1.DataChunk{summedChunk("hello "), summedChunk("world"), streamTrailer("hello there")}, ErrChecksumMismatch},
+		{"missing checksum", []*v1.DataChunk{summedChunk("hello "), {Data: []byte("world")}}, ErrChecksumMismatch},
+		{"truncated", []*v1.DataChunk{summedChunk("hello ")}, io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gauges := metrics.NewAgentGauges(prometheus.NewRegistry())
+			var buf bytes.Buffer
+			err := newChunkReceiver(chunkStream(tt.chunks...), gauges).copyTo(&buf)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if buf.String() != "hello world" {
+					t.Errorf("expected 'hello world', got %q", buf.String())
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == ErrChecksumMismatch && testutil.ToFloat64(gauges.ChecksumFailures) != 1 {
+				t.Error("expected checksum failure to be counted")
+			}
+		})
+	}
+}
+
+func TestZstdCompressor_RoundTrip(t *testing.T) {
+	c := &zstdCompressor{}
+	data := bytes.Repeat([]byte("layer data "), 4096)
+
+	for range 2 { // second pass reuses pooled encoder and decoder
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		if err != nil {
+			t.Fatalf("Compress: %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if buf.Len() >= len(data) {
+			t.Errorf("expected compressed size below %d, got %d", len(data), buf.Len())
+		}
+
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			t.Fatalf("Decompress: %v", err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatal("decompressed data does not match")
+		}
+	}
+}
+
+func TestImportFrom_CompressedChunks(t *testing.T) {
+	data := bytes.Repeat([]byte("image-tar-data"), 10000)
+	digest := "sha256:fake-140000" // FakeImageStore digests imports by length
+
+	for _, compression := range []string{CompressionZstd, CompressionGzip, CompressionNone} {
+		t.Run(compression, func(t *testing.T) {
+			source := NewFakeImageStore()
+			source.AddImage(digest, data)
+			sessions := session.NewStore()
+			sess := sessions.Create(digest, "node-a", "node-b", 5*time.Minute)
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			srv := grpc.NewServer()
+			v1.RegisterToteAgentServer(srv, &Server{Store: source, Sessions: sessions, ChunkSize: 4096, Compression: compression})
+			go func() { _ = srv.Serve(lis) }()
+			defer srv.Stop()
+
+			target := NewFakeImageStore()
+			resp, err := (&Server{Store: target, Sessions: session.NewStore()}).ImportFrom(context.Background(), &v1.ImportFromRequest{
+				SessionToken:   sess.Token,
+				Digest:         digest,
+				SourceEndpoint: lis.Addr().String(),
+			})
+			if err != nil {
+				t.Fatalf("ImportFrom: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+		})
+	}
+}
+
+func TestExportImage_ChunkSizeAndTrailer(t *testing.T) {
+	store := NewFakeImageStore()
+	store.AddImage("sha256:aaa", bytes.Repeat([]byte("x"), 10000))
+	sessions := session.NewStore()
+	sess := sessions.Create("sha256:aaa", "node-a", "node-b", 5*time.Minute)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	v1.RegisterToteAgentServer(srv, &Server{Store: store, Sessions: sessions, ChunkSize: 4096})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	stream, err := v1.NewToteAgentClient(conn).ExportImage(context.Background(), &v1.ExportImageRequest{SessionToken: sess.Token})
+	if err != nil {
+		t.Fatalf("ExportImage: %v", err)
+	}
+	var sizes []int
+	var trailer []byte
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if chunk.StreamSha256 != nil {
+			trailer = chunk.StreamSha256
+			continue
+		}
+		sizes = append(sizes, len(chunk.Data))
+	}
+
+	if want := []int{4096, 4096, 1808}; !slices.Equal(sizes, want) {
+		t.Errorf("expected chunk sizes %v, got %v", want, sizes)
+	}
+	sum := sha256.Sum256(bytes.Repeat([]byte("x"), 10000))
+	if !bytes.Equal(trailer, sum[:]) {
+		t.Error("expected stream checksum trailer")
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/content"
 	ctrimg "github.com/containerd/containerd/v2/core/images"
 	ctrarchive "github.com/containerd/containerd/v2/core/images/archive"
 	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -20,9 +25,23 @@ type ImageStore interface {
 	Has(ctx context.Context, digest string) (bool, error)
 	Size(ctx context.Context, digest string) (int64, error)
 	ResolveTag(ctx context.Context, imageRef string) (string, error)
-	Export(ctx context.Context, digest string, w io.Writer) error
-	Import(ctx context.Context, r io.Reader) (string, error)
+	Export(ctx context.Context, digest, platform string, w io.Writer) error
+	Import(ctx context.Context, r io.Reader, opts ImportOptions) (string, error)
+	Names(ctx context.Context, digest string) ([]string, error)
 	Remove(ctx context.Context, imageRef string) error
+	Verify(ctx context.Context, digest string) error
+	Check(ctx context.Context, digest, platform string) error
+	ExportBlob(ctx context.Context, digest, blob string, w io.Writer) error
+	ImportBlob(ctx context.Context, blob string, r io.Reader) error
+}
+
+// ImportOptions controls the image records Import creates.
+type ImportOptions struct {
+	// Names are references to record the image under in addition to those
+	// annotated in the archive.
+	Names []string
+	// Labels are set on every created record.
+	Labels map[string]string
 }
 
 // ContainerdStore implements ImageStore using the containerd client.
@@ -111,14 +130,114 @@ func (s *ContainerdStore) Size(ctx context.Context, digest string) (int64, error
 	return img.Size(ctx)
 }
 
+// Names returns the names of every image record that targets the digest,
+// e.g. "registry/repo:tag" and "registry/repo@sha256:...".
+func (s *ContainerdStore) Names(ctx context.Context, digest string) ([]string, error) {
+	imgs, err := s.client.ImageService().List(ctx, "target.digest=="+digest)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(imgs))
+	for _, img := range imgs {
+		names = append(names, img.Name)
+	}
+	return names, nil
+}
+
 // Remove deletes an image record from containerd by name/reference.
 func (s *ContainerdStore) Remove(ctx context.Context, imageRef string) error {
 	return s.client.ImageService().Delete(ctx, imageRef)
 }
 
+// Verify checks that every blob the image with the given digest needs on
+// this node's platform is present in the content store and hashes to its
+// descriptor. Returns an *IncompleteImageError listing bad blobs otherwise.
+func (s *ContainerdStore) Verify(ctx context.Context, digest string) error {
+	imgs, err := s.client.ImageService().List(ctx, "target.digest=="+digest)
+	if err != nil {
+		return err
+	}
+	if len(imgs) == 0 {
+		return fmt.Errorf("image %s: %w", digest, errdefs.ErrNotFound)
+	}
+	return verifyContent(ctx, s.client.ContentStore(), imgs[0].Target, platforms.Default(), true)
+}
+
+// Check is a cheaper Verify: it confirms every blob the image needs on the
+// given platform (empty = this node's) is present with the expected size but
+// does not re-hash the content.
+func (s *ContainerdStore) Check(ctx context.Context, digest, platform string) error {
+	matcher, err := platformMatcher(platform)
+	if err != nil {
+		return err
+	}
+	imgs, err := s.client.ImageService().List(ctx, "target.digest=="+digest)
+	if err != nil {
+		return err
+	}
+	if len(imgs) == 0 {
+		return fmt.Errorf("image %s: %w", digest, errdefs.ErrNotFound)
+	}
+	return verifyContent(ctx, s.client.ContentStore(), imgs[0].Target, matcher, false)
+}
+
+// ExportBlob writes a single content blob to w. The blob must be referenced
+// by the image with the given digest so a session for one image cannot be
+// used to read arbitrary content from the node.
+func (s *ContainerdStore) ExportBlob(ctx context.Context, digest, blob string, w io.Writer) error {
+	imgs, err := s.client.ImageService().List(ctx, "target.digest=="+digest)
+	if err != nil {
+		return err
+	}
+	if len(imgs) == 0 {
+		return fmt.Errorf("image %s: %w", digest, errdefs.ErrNotFound)
+	}
+	desc, err := findBlob(ctx, s.client.ContentStore(), imgs[0].Target, blob)
+	if err != nil {
+		return err
+	}
+	ra, err := s.client.ContentStore().ReaderAt(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("opening blob %s: %w", blob, err)
+	}
+	defer func() { _ = ra.Close() }()
+	_, err = io.Copy(w, content.NewReader(ra))
+	return err
+}
+
+// ImportBlob writes a single content blob read from r into the content
+// store, replacing any existing blob with the same digest. The content is
+// verified against the digest on commit.
+func (s *ContainerdStore) ImportBlob(ctx context.Context, blob string, r io.Reader) error {
+	dgst, err := godigest.Parse(blob)
+	if err != nil {
+		return fmt.Errorf("parsing blob digest: %w", err)
+	}
+
+	ctx, done, err := s.client.WithLease(ctx)
+	if err != nil {
+		return fmt.Errorf("creating lease: %w", err)
+	}
+	defer func() { _ = done(ctx) }()
+
+	// A corrupt blob is present under the right digest, so WriteBlob would
+	// treat it as already imported. Drop it first.
+	cs := s.client.ContentStore()
+	if err := cs.Delete(ctx, dgst); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("removing stale blob %s: %w", blob, err)
+	}
+	if err := content.WriteBlob(ctx, cs, "tote-repair-"+dgst.Encoded(), r, ocispec.Descriptor{Digest: dgst}); err != nil {
+		return fmt.Errorf("writing blob %s: %w", blob, err)
+	}
+	return nil
+}
+
 // Export writes the image with the given digest as a tar archive to w.
-// Uses direct content-store access for containerd v1.x compatibility.
-func (s *ContainerdStore) Export(ctx context.Context, digest string, w io.Writer) error {
+// When platform is set, only the index and that platform's manifest tree
+// are written, so a node that only pulled its own platform can still serve
+// a multi-arch image. Uses direct content-store access for containerd v1.x
+// compatibility.
+func (s *ContainerdStore) Export(ctx context.Context, digest, platform string, w io.Writer) error {
 	imgs, err := s.client.ImageService().List(ctx, "target.digest=="+digest)
 	if err != nil {
 		return err
@@ -126,14 +245,28 @@ func (s *ContainerdStore) Export(ctx context.Context, digest string, w io.Writer
 	if len(imgs) == 0 {
 		return fmt.Errorf("image %s: %w", digest, errdefs.ErrNotFound)
 	}
-	return ctrarchive.Export(ctx, s.client.ContentStore(), w,
-		ctrarchive.WithImage(s.client.ImageService(), imgs[0].Name))
+	// Export every record so each name travels in the archive annotations.
+	opts := []ctrarchive.ExportOpt{ctrarchive.WithImages(imgs)}
+	if platform != "" {
+		matcher, err := platformMatcher(platform)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, ctrarchive.WithPlatform(matcher))
+	}
+	return ctrarchive.Export(ctx, s.client.ContentStore(), w, opts...)
 }
 
 // Import reads a tar archive from r and imports it into containerd.
 // Returns the digest of the imported image.
 // Uses direct content-store access for containerd v1.x compatibility.
-func (s *ContainerdStore) Import(ctx context.Context, r io.Reader) (string, error) {
+//
+// A record is created for every name annotated in the archive and every
+// name in opts, each also in its repo@digest form, so kubelet finds the
+// image under whichever reference the pod spec uses. Existing records with
+// those names are re-pointed at the imported image and get tote's labels;
+// labels set by kubelet or other tools are kept.
+func (s *ContainerdStore) Import(ctx context.Context, r io.Reader, opts ImportOptions) (string, error) {
 	desc, err := ctrarchive.ImportIndex(ctx, s.client.ContentStore(), r)
 	if err != nil {
 		return "", fmt.Errorf("importing archive: %w", err)
@@ -155,26 +288,112 @@ func (s *ContainerdStore) Import(ctx context.Context, r io.Reader) (string, erro
 		return "", fmt.Errorf("no manifests in imported archive: %w", errdefs.ErrNotFound)
 	}
 
-	var lastDigest string
+	// An archive lists one entry per exported record; group names by target.
+	targets := make(map[godigest.Digest]ocispec.Descriptor)
+	names := make(map[godigest.Digest][]string)
+	var last godigest.Digest
 	for _, m := range index.Manifests {
-		name := m.Annotations[ctrimg.AnnotationImageName]
-		if name == "" {
-			name = m.Annotations[ocispec.AnnotationRefName]
+		target := m
+		target.Annotations = nil
+		targets[m.Digest] = target
+		names[m.Digest] = append(names[m.Digest], archiveImageName(m))
+		last = m.Digest
+	}
+	names[last] = append(names[last], opts.Names...)
+
+	labels := map[string]string{"io.cri-containerd.image": "managed"}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+
+	is := s.client.ImageService()
+	paths := updatePaths(labels)
+	for dgst, target := range targets {
+		for _, name := range imageRecordNames(names[dgst], dgst) {
+			img := ctrimg.Image{Name: name, Target: target, Labels: labels}
+			if _, err := is.Create(ctx, img); err != nil {
+				if !errdefs.IsAlreadyExists(err) {
+					return "", fmt.Errorf("creating image record %s: %w", name, err)
+				}
+				if _, err := is.Update(ctx, img, paths...); err != nil {
+					return "", fmt.Errorf("updating image record %s: %w", name, err)
+				}
+			}
 		}
+	}
+
+	return last.String(), nil
+}
+
+// updatePaths returns the fieldpaths that re-point an existing image record
+// and set labels on it without replacing its other labels.
+func updatePaths(labels map[string]string) []string {
+	paths := []string{"target"}
+	for k := range labels {
+		paths = append(paths, "labels."+k)
+	}
+	sort.Strings(paths[1:])
+	return paths
+}
+
+// archiveImageName returns the image name annotated on an archive index
+// entry. The OCI ref.name annotation is only used when it is a full
+// reference; containerd writes just the tag there.
+func archiveImageName(m ocispec.Descriptor) string {
+	if name := m.Annotations[ctrimg.AnnotationImageName]; name != "" {
+		return name
+	}
+	if name := m.Annotations[ocispec.AnnotationRefName]; strings.ContainsAny(name, "/@") {
+		return name
+	}
+	return ""
+}
+
+// imageRecordNames normalizes names the way the CRI plugin does and adds
+// the repo@digest form of each repository. Falls back to the bare digest
+// when there is no usable name. Bare digests (CRI image ID records) are
+// kept as-is.
+func imageRecordNames(names []string, dgst godigest.Digest) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	for _, name := range names {
 		if name == "" {
-			name = m.Digest.String()
+			continue
+		}
+		if _, err := godigest.Parse(name); err == nil {
+			add(name)
+			continue
+		}
+		named, err := reference.ParseDockerRef(name)
+		if err != nil {
+			continue
 		}
-		if _, err := s.client.ImageService().Create(ctx, ctrimg.Image{
-			Name:   name,
-			Target: m,
-			Labels: map[string]string{
-				"io.cri-containerd.image": "managed",
-			},
-		}); err != nil && !errdefs.IsAlreadyExists(err) {
-			return "", fmt.Errorf("creating image record: %w", err)
+		add(named.String())
+		if canonical, err := reference.WithDigest(reference.TrimNamed(named), dgst); err == nil {
+			add(canonical.String())
 		}
-		lastDigest = m.Digest.String()
 	}
+	if len(out) == 0 {
+		add(dgst.String())
+	}
+	return out
+}
 
-	return lastDigest, nil
+// platformMatcher returns a strict matcher for the given os/arch[/variant]
+// string, or this node's default platform matcher when it is empty.
+func platformMatcher(platform string) (platforms.MatchComparer, error) {
+	if platform == "" {
+		return platforms.Default(), nil
+	}
+	p, err := platforms.Parse(platform)
+	if err != nil {
+		return nil, fmt.Errorf("parsing platform %q: %w", platform, err)
+	}
+	return platforms.OnlyStrict(p), nil
 }
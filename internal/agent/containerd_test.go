@@ -1,8 +1,88 @@
 package agent
 
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	ctrimg "github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/platforms"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
 // Ensure fakes satisfy the interface at compile time.
 var (
 	_ ImageStore = (*FakeImageStore)(nil)
 	_ ImageStore = (*FailingImageStore)(nil)
 	_ ImageStore = (*ContainerdStore)(nil)
 )
+
+func TestPlatformMatcher(t *testing.T) {
+	m, err := platformMatcher("linux/arm64")
+	if err != nil {
+		t.Fatalf("platformMatcher: %v", err)
+	}
+	if !m.Match(ocispec.Platform{OS: "linux", Architecture: "arm64"}) {
+		t.Error("expected linux/arm64 to match")
+	}
+	// Strict: a 64-bit ARM target must not accept 32-bit ARM content.
+	if m.Match(ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}) {
+		t.Error("expected linux/arm/v7 not to match linux/arm64")
+	}
+
+	if _, err := platformMatcher("not a platform/"); err == nil {
+		t.Error("expected error for invalid platform")
+	}
+	if m, err := platformMatcher(""); err != nil || !m.Match(platforms.DefaultSpec()) {
+		t.Errorf("expected empty platform to match this node, got %v", err)
+	}
+}
+
+func TestImageRecordNames(t *testing.T) {
+	dgst := godigest.Digest("sha256:" + strings.Repeat("a", 64))
+
+	got := imageRecordNames([]string{"nginx:1.25", "registry.example.com/app:v1", "nginx:1.25", "not a ref"}, dgst)
+	want := []string{
+		"docker.io/library/nginx:1.25",
+		"docker.io/library/nginx@" + dgst.String(),
+		"registry.example.com/app:v1",
+		"registry.example.com/app@" + dgst.String(),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := imageRecordNames(nil, dgst); !slices.Equal(got, []string{dgst.String()}) {
+		t.Errorf("expected bare digest fallback, got %v", got)
+	}
+	if got := imageRecordNames([]string{dgst.String()}, dgst); !slices.Equal(got, []string{dgst.String()}) {
+		t.Errorf("expected bare digest to be kept, got %v", got)
+	}
+}
+
+func TestUpdatePaths(t *testing.T) {
+	got := updatePaths(map[string]string{"io.cri-containerd.image": "managed", "tote.dev/imported": "true"})
+	want := []string{"target", "labels.io.cri-containerd.image", "labels.tote.dev/imported"}
+	if !slices.Equal(got, want) {
+		t.Errorf("updatePaths = %v, want %v", got, want)
+	}
+}
+
+func TestArchiveImageName(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		want        string
+	}{
+		{map[string]string{ctrimg.AnnotationImageName: "docker.io/library/nginx:1.25"}, "docker.io/library/nginx:1.25"},
+		{map[string]string{ocispec.AnnotationRefName: "registry.example.com/app:v1"}, "registry.example.com/app:v1"},
+		// A bare tag cannot be turned back into a full reference.
+		{map[string]string{ocispec.AnnotationRefName: "1.25"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := archiveImageName(ocispec.Descriptor{Annotations: tt.annotations}); got != tt.want {
+			t.Errorf("archiveImageName(%v) = %q, want %q", tt.annotations, got, tt.want)
+		}
+	}
+}
@@ -4,21 +4,30 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"sync"
 )
 
 // FakeImageStore implements ImageStore for testing.
 type FakeImageStore struct {
-	mu     sync.Mutex
-	images map[string][]byte
-	tags   map[string]string // imageRef -> digest
+	mu        sync.Mutex
+	images    map[string][]byte
+	tags      map[string]string   // imageRef -> digest
+	broken    map[string][]string // digest -> missing blobs
+	blobs     map[string][]byte   // blob digest -> data
+	platforms map[string][]string // digest -> platforms with complete content
+	labels    map[string]map[string]string
 }
 
 // NewFakeImageStore creates an empty fake image store.
 func NewFakeImageStore() *FakeImageStore {
 	return &FakeImageStore{
-		images: make(map[string][]byte),
-		tags:   make(map[string]string),
+		images:    make(map[string][]byte),
+		tags:      make(map[string]string),
+		broken:    make(map[string][]string),
+		blobs:     make(map[string][]byte),
+		platforms: make(map[string][]string),
+		labels:    make(map[string]map[string]string),
 	}
 }
 
@@ -36,6 +45,29 @@ func (f *FakeImageStore) AddTag(imageRef, digest string) {
 	f.tags[imageRef] = digest
 }
 
+// SetMissingBlobs marks the image with the given digest as incomplete so
+// Verify reports the listed blobs as missing.
+func (f *FakeImageStore) SetMissingBlobs(digest string, blobs []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broken[digest] = blobs
+}
+
+// SetPlatforms restricts the platforms Check accepts for the digest. Images
+// without an entry accept every platform.
+func (f *FakeImageStore) SetPlatforms(digest string, platforms ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.platforms[digest] = platforms
+}
+
+// AddBlob stores a content blob that ExportBlob can serve.
+func (f *FakeImageStore) AddBlob(blob string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[blob] = data
+}
+
 // List returns all stored digests.
 func (f *FakeImageStore) List(_ context.Context) ([]string, error) {
 	f.mu.Lock()
@@ -47,6 +79,27 @@ func (f *FakeImageStore) List(_ context.Context) ([]string, error) {
 	return digests, nil
 }
 
+// Names returns every tag mapped to the digest, sorted.
+func (f *FakeImageStore) Names(_ context.Context, digest string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name, d := range f.tags {
+		if d == digest {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Labels returns the labels the last Import set for the digest.
+func (f *FakeImageStore) Labels(digest string) map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.labels[digest]
+}
+
 // Has returns true if the digest exists.
 func (f *FakeImageStore) Has(_ context.Context, digest string) (bool, error) {
 	f.mu.Lock()
@@ -92,8 +145,73 @@ func (f *FakeImageStore) Remove(_ context.Context, imageRef string) error {
 	return fmt.Errorf("image %s not found", imageRef)
 }
 
+// Verify returns an *IncompleteImageError if missing blobs were set for the
+// digest, or an error if the image does not exist.
+func (f *FakeImageStore) Verify(_ context.Context, digest string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.images[digest]; !ok {
+		return fmt.Errorf("image %s not found", digest)
+	}
+	if missing := f.broken[digest]; len(missing) > 0 {
+		return &IncompleteImageError{Digest: digest, Missing: missing}
+	}
+	return nil
+}
+
+// Check behaves like Verify; the fake has no content to re-hash. A platform
+// not listed via SetPlatforms is reported as having no matching manifest.
+func (f *FakeImageStore) Check(ctx context.Context, digest, platform string) error {
+	if platform != "" {
+		f.mu.Lock()
+		supported, restricted := f.platforms[digest]
+		f.mu.Unlock()
+		if restricted && !slices.Contains(supported, platform) {
+			return fmt.Errorf("image %s has no manifest for platform %s", digest, platform)
+		}
+	}
+	return f.Verify(ctx, digest)
+}
+
+// ExportBlob writes the stored blob data if the image exists.
+func (f *FakeImageStore) ExportBlob(_ context.Context, digest, blob string, w io.Writer) error {
+	f.mu.Lock()
+	_, ok := f.images[digest]
+	data, hasBlob := f.blobs[blob]
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("image %s not found", digest)
+	}
+	if !hasBlob {
+		return fmt.Errorf("blob %s not found", blob)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ImportBlob stores the blob and clears it from any missing-blob lists.
+func (f *FakeImageStore) ImportBlob(_ context.Context, blob string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[blob] = data
+	for digest, missing := range f.broken {
+		var remaining []string
+		for _, b := range missing {
+			if b != blob {
+				remaining = append(remaining, b)
+			}
+		}
+		f.broken[digest] = remaining
+	}
+	return nil
+}
+
 // Export writes the stored tar data for the given digest.
-func (f *FakeImageStore) Export(_ context.Context, digest string, w io.Writer) error {
+func (f *FakeImageStore) Export(_ context.Context, digest, _ string, w io.Writer) error {
 	f.mu.Lock()
 	data, ok := f.images[digest]
 	f.mu.Unlock()
@@ -105,7 +223,7 @@ func (f *FakeImageStore) Export(_ context.Context, digest string, w io.Writer) e
 }
 
 // Import reads tar data and stores it under a deterministic digest.
-func (f *FakeImageStore) Import(_ context.Context, r io.Reader) (string, error) {
+func (f *FakeImageStore) Import(_ context.Context, r io.Reader, opts ImportOptions) (string, error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return "", err
@@ -113,6 +231,12 @@ func (f *FakeImageStore) Import(_ context.Context, r io.Reader) (string, error)
 	digest := fmt.Sprintf("sha256:fake-%d", len(data))
 	f.mu.Lock()
 	f.images[digest] = data
+	for _, name := range opts.Names {
+		f.tags[name] = digest
+	}
+	if opts.Labels != nil {
+		f.labels[digest] = opts.Labels
+	}
 	f.mu.Unlock()
 	return digest, nil
 }
@@ -122,10 +246,19 @@ type FailingImageStore struct {
 	Err error
 }
 
-func (f *FailingImageStore) List(_ context.Context) ([]string, error)               { return nil, f.Err }
-func (f *FailingImageStore) Has(_ context.Context, _ string) (bool, error)          { return false, f.Err }
-func (f *FailingImageStore) Size(_ context.Context, _ string) (int64, error)        { return 0, f.Err }
-func (f *FailingImageStore) ResolveTag(_ context.Context, _ string) (string, error) { return "", f.Err }
-func (f *FailingImageStore) Export(_ context.Context, _ string, _ io.Writer) error  { return f.Err }
-func (f *FailingImageStore) Import(_ context.Context, _ io.Reader) (string, error)  { return "", f.Err }
-func (f *FailingImageStore) Remove(_ context.Context, _ string) error               { return f.Err }
+func (f *FailingImageStore) List(_ context.Context) ([]string, error)                 { return nil, f.Err }
+func (f *FailingImageStore) Has(_ context.Context, _ string) (bool, error)            { return false, f.Err }
+func (f *FailingImageStore) Size(_ context.Context, _ string) (int64, error)          { return 0, f.Err }
+func (f *FailingImageStore) ResolveTag(_ context.Context, _ string) (string, error)   { return "", f.Err }
+func (f *FailingImageStore) Export(_ context.Context, _, _ string, _ io.Writer) error { return f.Err }
+func (f *FailingImageStore) Import(_ context.Context, _ io.Reader, _ ImportOptions) (string, error) {
+	return "", f.Err
+}
+func (f *FailingImageStore) Names(_ context.Context, _ string) ([]string, error) { return nil, f.Err }
+func (f *FailingImageStore) Remove(_ context.Context, _ string) error            { return f.Err }
+func (f *FailingImageStore) Verify(_ context.Context, _ string) error            { return f.Err }
+func (f *FailingImageStore) Check(_ context.Context, _, _ string) error          { return f.Err }
+func (f *FailingImageStore) ExportBlob(_ context.Context, _, _ string, _ io.Writer) error {
+	return f.Err
+}
+func (f *FailingImageStore) ImportBlob(_ context.Context, _ string, _ io.Reader) error { return f.Err }
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+// Scanner periodically checks the content of every image in the store so
+// corruption is found before a pod restart trips over it. Each scan confirms
+// that every blob is present with the right size; a slower rehash scan also
+// re-computes blob digests to catch bit rot.
+type Scanner struct {
+	Store          ImageStore
+	Interval       time.Duration
+	RehashInterval time.Duration        // 0 = never re-hash
+	Metrics        *metrics.AgentGauges // nil = no metrics
+
+	mu         sync.Mutex
+	corrupt    []*IncompleteImageError
+	scannedAt  time.Time
+	lastRehash time.Time
+}
+
+// NewScanner creates a Scanner that checks the store every interval and
+// re-hashes content every rehashInterval.
+func NewScanner(store ImageStore, interval, rehashInterval time.Duration) *Scanner {
+	return &Scanner{
+		Store:          store,
+		Interval:       interval,
+		RehashInterval: rehashInterval,
+	}
+}
+
+// Start runs the scan loop until ctx is cancelled. The first presence scan
+// runs immediately; the first rehash waits a full RehashInterval so agent
+// rollouts do not re-read every node's content store at once.
+func (s *Scanner) Start(ctx context.Context) error {
+	s.lastRehash = time.Now()
+	s.scan(ctx)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.scan(ctx)
+		}
+	}
+}
+
+// Results returns the corrupt images found by the last completed scan and
+// when it finished. The time is zero until the first scan completes.
+func (s *Scanner) Results() ([]*IncompleteImageError, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*IncompleteImageError(nil), s.corrupt...), s.scannedAt
+}
+
+func (s *Scanner) scan(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("scanner")
+
+	rehash := s.RehashInterval > 0 && time.Since(s.lastRehash) >= s.RehashInterval
+	mode := "presence"
+	if rehash {
+		mode = "rehash"
+	}
+
+	start := time.Now()
+	digests, err := s.Store.List(ctx)
+	if err != nil {
+		logger.Error(err, "listing images for scan")
+		return
+	}
+
+	var corrupt []*IncompleteImageError
+	for _, digest := range digests {
+		if ctx.Err() != nil {
+			return
+		}
+		var err error
+		if rehash {
+			err = s.Store.Verify(ctx, digest)
+		} else {
+			err = s.Store.Check(ctx, digest, "")
+		}
+		if err == nil {
+			continue
+		}
+		var incomplete *IncompleteImageError
+		if !errors.As(err, &incomplete) {
+			// NotFound means the image was removed since List.
+			if !errdefs.IsNotFound(err) {
+				logger.Error(err, "checking image", "digest", digest)
+			}
+			continue
+		}
+		logger.Info("corrupt image found", "digest", digest, "missing", incomplete.Missing, "corrupt", incomplete.Corrupt)
+		corrupt = append(corrupt, incomplete)
+	}
+
+	if s.Metrics != nil {
+		s.Metrics.SetCorruptImages(len(corrupt))
+		s.Metrics.RecordScanDuration(mode, time.Since(start))
+	}
+	logger.V(1).Info("scan complete", "mode", mode, "images", len(digests), "corrupt", len(corrupt), "duration", time.Since(start))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.corrupt = corrupt
+	s.scannedAt = time.Now()
+	if rehash {
+		s.lastRehash = s.scannedAt
+	}
+}
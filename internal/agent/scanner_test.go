@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+// countingStore records whether scans used the presence check or a rehash.
+type countingStore struct {
+	*FakeImageStore
+	checks, verifies int
+}
+
+func (c *countingStore) Check(ctx context.Context, digest, platform string) error {
+	c.checks++
+	return c.FakeImageStore.Check(ctx, digest, platform)
+}
+
+func (c *countingStore) Verify(ctx context.Context, digest string) error {
+	c.verifies++
+	return c.FakeImageStore.Verify(ctx, digest)
+}
+
+func TestScanner_ReportsCorruptImages(t *testing.T) {
+	store := NewFakeImageStore()
+	store.AddImage("sha256:good", []byte("good"))
+	store.AddImage("sha256:bad", []byte("bad"))
+	store.SetMissingBlobs("sha256:bad", []string{"sha256:layer"})
+
+	gauges := metrics.NewAgentGauges(prometheus.NewRegistry())
+	s := NewScanner(store, time.Minute, 0)
+	s.Metrics = gauges
+	s.scan(context.Background())
+
+	corrupt, scannedAt := s.Results()
+	if len(corrupt) != 1 || corrupt[0].Digest != "sha256:bad" {
+		t.Fatalf("expected sha256:bad to be reported, got %v", corrupt)
+	}
+	if scannedAt.IsZero() {
+		t.Error("expected scan time to be set")
+	}
+	if val := testutil.ToFloat64(gauges.CorruptImages); val != 1 {
+		t.Errorf("expected gauge 1, got %f", val)
+	}
+
+	// Once repaired, the next scan clears the finding.
+	store.SetMissingBlobs("sha256:bad", nil)
+	s.scan(context.Background())
+	if corrupt, _ := s.Results(); len(corrupt) != 0 {
+		t.Errorf("expected no corrupt images after repair, got %v", corrupt)
+	}
+	if val := testutil.ToFloat64(gauges.CorruptImages); val != 0 {
+		t.Errorf("expected gauge 0, got %f", val)
+	}
+}
+
+func TestScanner_RehashOnSlowSchedule(t *testing.T) {
+	store := &countingStore{FakeImageStore: NewFakeImageStore()}
+	store.AddImage("sha256:aaa", []byte("data"))
+
+	s := NewScanner(store, time.Minute, time.Hour)
+	s.lastRehash = time.Now()
+	s.scan(context.Background())
+	if store.checks != 1 || store.verifies != 0 {
+		t.Fatalf("expected presence check, got checks=%d verifies=%d", store.checks, store.verifies)
+	}
+
+	s.lastRehash = time.Now().Add(-2 * time.Hour)
+	s.scan(context.Background())
+	if store.verifies != 1 {
+		t.Fatalf("expected rehash once interval elapsed, got verifies=%d", store.verifies)
+	}
+	if time.Since(s.lastRehash) > time.Minute {
+		t.Error("expected rehash time to be updated")
+	}
+}
+
+func TestScanner_ListError(t *testing.T) {
+	s := NewScanner(&FailingImageStore{Err: errors.New("containerd down")}, time.Minute, 0)
+	s.scan(context.Background())
+	if _, scannedAt := s.Results(); !scannedAt.IsZero() {
+		t.Error("failed scan must not be reported as completed")
+	}
+}
+
+func TestListCorruptImages(t *testing.T) {
+	store := NewFakeImageStore()
+	store.AddImage("sha256:bad", []byte("bad"))
+	store.SetMissingBlobs("sha256:bad", []string{"sha256:layer"})
+
+	srv := &Server{Store: store}
+	resp, err := srv.ListCorruptImages(context.Background(), &v1.ListCorruptImagesRequest{})
+	if err != nil {
+		t.Fatalf("ListCorruptImages: %v", err)
+	}
+	if len(resp.Images) != 0 || resp.ScannedAt != 0 {
+		t.Errorf("expected empty response without scanner, got %v", resp)
+	}
+
+	srv.Scanner = NewScanner(store, time.Minute, 0)
+	srv.Scanner.scan(context.Background())
+	resp, err = srv.ListCorruptImages(context.Background(), &v1.ListCorruptImagesRequest{})
+	if err != nil {
+		t.Fatalf("ListCorruptImages: %v", err)
+	}
+	if len(resp.Images) != 1 || resp.Images[0].Digest != "sha256:bad" || resp.Images[0].MissingBlobs[0] != "sha256:layer" {
+		t.Errorf("unexpected images: %v", resp.Images)
+	}
+	if resp.ScannedAt == 0 {
+		t.Error("expected scanned_at to be set")
+	}
+}
@@ -2,30 +2,37 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/metrics"
 	"github.com/ppiankov/tote/internal/registry"
 	"github.com/ppiankov/tote/internal/session"
 )
 
-const exportChunkSize = 32 * 1024 // 32 KiB
-
 // Server implements the ToteAgent gRPC service.
 type Server struct {
 	v1.UnimplementedToteAgentServer
 	Store       ImageStore
 	Sessions    *session.Store
 	Port        int
+	Scanner     *Scanner                         // nil = background scanning disabled
+	Throttle    *Throttle                        // nil = unlimited bandwidth and exports
+	ChunkSize   int                              // 0 = config.DefaultAgentChunkSize
+	Compression string                           // export stream compression; "" = none
 	ServerCreds credentials.TransportCredentials // nil = insecure
 	ClientCreds credentials.TransportCredentials // nil = insecure (for agent-to-agent)
 }
@@ -68,6 +75,15 @@ func (s *Server) PrepareExport(ctx context.Context, req *v1.PrepareExportRequest
 		return nil, fmt.Errorf("session_token and digest are required")
 	}
 
+	// Turn the export away now rather than queue it, so the controller can
+	// pick another source.
+	if !s.Throttle.ExportsAvailable() {
+		if s.Throttle.metrics != nil {
+			s.Throttle.metrics.RecordRejectedExport()
+		}
+		return nil, status.Errorf(codes.ResourceExhausted, "agent is serving its maximum of %d exports", cap(s.Throttle.exports))
+	}
+
 	has, err := s.Store.Has(ctx, req.Digest)
 	if err != nil {
 		return nil, fmt.Errorf("checking image: %w", err)
@@ -76,16 +92,30 @@ func (s *Server) PrepareExport(ctx context.Context, req *v1.PrepareExportRequest
 		return nil, fmt.Errorf("image %s not found locally", req.Digest)
 	}
 
+	// A node only holds the layers of the platforms it pulled; refuse early
+	// if the target's variant is not complete here so the controller can
+	// pick another source.
+	if req.Platform != "" {
+		if err := s.Store.Check(ctx, req.Digest, req.Platform); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "image %s has no complete %s variant on this node: %v", req.Digest, req.Platform, err)
+		}
+	}
+
 	sizeBytes, err := s.Store.Size(ctx, req.Digest)
 	if err != nil {
 		return nil, fmt.Errorf("getting image size: %w", err)
 	}
 
+	names, err := s.Store.Names(ctx, req.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("listing image names: %w", err)
+	}
+
 	// Register the session locally so ExportImage can look up the digest.
 	// The token was created by the controller's orchestrator.
-	s.Sessions.Register(req.SessionToken, req.Digest, 5*time.Minute)
+	s.Sessions.Register(req.SessionToken, req.Digest, req.Platform, 5*time.Minute)
 
-	return &v1.PrepareExportResponse{SizeBytes: sizeBytes}, nil
+	return &v1.PrepareExportResponse{SizeBytes: sizeBytes, ImageNames: names}, nil
 }
 
 // ExportImage streams the image tar for the session's digest.
@@ -99,29 +129,35 @@ func (s *Server) ExportImage(req *v1.ExportImageRequest, stream v1.ToteAgent_Exp
 		return fmt.Errorf("invalid or expired session token")
 	}
 
+	return s.streamChunks(stream, func(w io.Writer) error {
+		return s.Store.Export(stream.Context(), sess.Digest, sess.Platform, w)
+	})
+}
+
+// streamChunks runs export in the background and sends everything it writes
+// to the stream as checksummed chunks. It takes an export slot and is held
+// to the export bandwidth limit.
+func (s *Server) streamChunks(stream grpc.ServerStreamingServer[v1.DataChunk], export func(w io.Writer) error) error {
+	ctx := stream.Context()
+	release, err := s.Throttle.acquireExport(ctx)
+	if err != nil {
+		return fmt.Errorf("waiting for export slot: %w", err)
+	}
+	defer release()
+	s.negotiateCompression(ctx)
+
 	pr, pw := io.Pipe()
 	errCh := make(chan error, 1)
 
 	go func() {
-		errCh <- s.Store.Export(stream.Context(), sess.Digest, pw)
-		_ = pw.Close()
+		err := export(s.Throttle.exportWriter(ctx, pw))
+		errCh <- err
+		_ = pw.CloseWithError(err)
 	}()
 
-	buf := make([]byte, exportChunkSize)
-	for {
-		n, err := pr.Read(buf)
-		if n > 0 {
-			if sendErr := stream.Send(&v1.DataChunk{Data: buf[:n]}); sendErr != nil {
-				_ = pr.Close()
-				return fmt.Errorf("sending chunk: %w", sendErr)
-			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("reading export: %w", err)
-		}
+	if err := sendChunks(stream, pr, s.ChunkSize); err != nil {
+		_ = pr.Close()
+		return err
 	}
 
 	if err := <-errCh; err != nil {
@@ -137,11 +173,7 @@ func (s *Server) ImportFrom(ctx context.Context, req *v1.ImportFromRequest) (*v1
 		return &v1.ImportFromResponse{Success: false, Error: "session_token, digest, and source_endpoint are required"}, nil
 	}
 
-	dialCreds := grpc.WithTransportCredentials(insecure.NewCredentials())
-	if s.ClientCreds != nil {
-		dialCreds = grpc.WithTransportCredentials(s.ClientCreds)
-	}
-	conn, err := grpc.NewClient(req.SourceEndpoint, dialCreds)
+	conn, err := grpc.NewClient(req.SourceEndpoint, s.dialOption())
 	if err != nil {
 		return &v1.ImportFromResponse{Success: false, Error: fmt.Sprintf("connecting to source: %v", err)}, nil
 	}
@@ -155,26 +187,23 @@ func (s *Server) ImportFrom(ctx context.Context, req *v1.ImportFromRequest) (*v1
 
 	pr, pw := io.Pipe()
 	errCh := make(chan error, 1)
+	w := s.Throttle.importWriter(ctx, pw)
 
+	// A corrupt or truncated stream aborts the import at once instead of
+	// surfacing later as an unreadable archive.
 	go func() {
-		defer func() { _ = pw.Close() }()
-		for {
-			chunk, err := stream.Recv()
-			if err == io.EOF {
-				return
-			}
-			if err != nil {
-				errCh <- fmt.Errorf("receiving chunk: %w", err)
-				return
-			}
-			if _, err := pw.Write(chunk.Data); err != nil {
-				errCh <- fmt.Errorf("writing to pipe: %w", err)
-				return
-			}
+		err := newChunkReceiver(stream.Recv, s.gauges()).copyTo(w)
+		if err != nil {
+			errCh <- err
 		}
+		_ = pw.CloseWithError(err)
 	}()
 
-	digest, err := s.Store.Import(ctx, pr)
+	opts := ImportOptions{Names: req.ImageNames}
+	if req.SourceNode != "" {
+		opts.Labels = map[string]string{config.LabelSalvagedFrom: req.SourceNode}
+	}
+	digest, err := s.Store.Import(ctx, pr, opts)
 	if err != nil {
 		return &v1.ImportFromResponse{Success: false, Error: fmt.Sprintf("importing image: %v", err)}, nil
 	}
@@ -198,6 +227,17 @@ func (s *Server) ImportFrom(ctx context.Context, req *v1.ImportFromRequest) (*v1
 		}
 	}
 
+	// An image record is not enough: kubelet fails with CreateContainerError
+	// if any referenced blob is missing, so check the whole content tree.
+	if err := s.Store.Verify(ctx, req.Digest); err != nil {
+		resp := &v1.ImportFromResponse{Success: false, Error: fmt.Sprintf("verifying imported image: %v", err)}
+		var incomplete *IncompleteImageError
+		if errors.As(err, &incomplete) {
+			resp.MissingBlobs = incomplete.Blobs()
+		}
+		return resp, nil
+	}
+
 	return &v1.ImportFromResponse{Success: true}, nil
 }
 
@@ -248,8 +288,14 @@ func (s *Server) PushImage(ctx context.Context, req *v1.PushImageRequest) (*v1.P
 		return &v1.PushImageResponse{Success: false, Error: fmt.Sprintf("image %s not found locally", req.Digest)}, nil
 	}
 
+	release, err := s.Throttle.acquireExport(ctx)
+	if err != nil {
+		return &v1.PushImageResponse{Success: false, Error: fmt.Sprintf("waiting for export slot: %v", err)}, nil
+	}
+	defer release()
+
 	exportFn := func(ctx context.Context, digest string, w io.Writer) error {
-		return s.Store.Export(ctx, digest, w)
+		return s.Store.Export(ctx, digest, "", s.Throttle.exportWriter(ctx, w))
 	}
 	if err := registry.Push(ctx, exportFn, req.Digest, req.TargetRef, req.RegistryUsername, req.RegistryPassword, req.Insecure); err != nil {
 		return &v1.PushImageResponse{Success: false, Error: fmt.Sprintf("push failed: %v", err)}, nil
@@ -257,3 +303,148 @@ func (s *Server) PushImage(ctx context.Context, req *v1.PushImageRequest) (*v1.P
 
 	return &v1.PushImageResponse{Success: true}, nil
 }
+
+// CheckImage resolves an image reference in containerd and reports which of
+// its blobs are missing or corrupt on this node.
+func (s *Server) CheckImage(ctx context.Context, req *v1.CheckImageRequest) (*v1.CheckImageResponse, error) {
+	if req.ImageRef == "" {
+		return nil, fmt.Errorf("image_ref is required")
+	}
+	digest, err := s.Store.ResolveTag(ctx, req.ImageRef)
+	if err != nil {
+		return nil, fmt.Errorf("resolving image: %w", err)
+	}
+	if digest == "" {
+		return nil, fmt.Errorf("image %s not found locally", req.ImageRef)
+	}
+
+	resp := &v1.CheckImageResponse{Digest: digest}
+	if err := s.Store.Verify(ctx, digest); err != nil {
+		var incomplete *IncompleteImageError
+		if !errors.As(err, &incomplete) {
+			return nil, fmt.Errorf("verifying image: %w", err)
+		}
+		resp.MissingBlobs = incomplete.Blobs()
+	}
+	return resp, nil
+}
+
+// ExportBlob streams a single blob of the session's image.
+func (s *Server) ExportBlob(req *v1.ExportBlobRequest, stream v1.ToteAgent_ExportBlobServer) error {
+	if req.SessionToken == "" || req.BlobDigest == "" {
+		return fmt.Errorf("session_token and blob_digest are required")
+	}
+
+	sess, ok := s.Sessions.Validate(req.SessionToken)
+	if !ok {
+		return fmt.Errorf("invalid or expired session token")
+	}
+
+	return s.streamChunks(stream, func(w io.Writer) error {
+		return s.Store.ExportBlob(stream.Context(), sess.Digest, req.BlobDigest, w)
+	})
+}
+
+// ListCorruptImages returns the images the background scanner found with
+// missing or corrupt content. Empty when scanning is disabled.
+func (s *Server) ListCorruptImages(_ context.Context, _ *v1.ListCorruptImagesRequest) (*v1.ListCorruptImagesResponse, error) {
+	resp := &v1.ListCorruptImagesResponse{}
+	if s.Scanner == nil {
+		return resp, nil
+	}
+	corrupt, scannedAt := s.Scanner.Results()
+	for _, img := range corrupt {
+		resp.Images = append(resp.Images, &v1.CorruptImage{Digest: img.Digest, MissingBlobs: img.Blobs()})
+	}
+	if !scannedAt.IsZero() {
+		resp.ScannedAt = scannedAt.Unix()
+	}
+	return resp, nil
+}
+
+// RepairImage fetches the requested blobs from a peer agent (or a registry
+// when no peer is given), writes them into the local content store, and
+// re-verifies the image.
+func (s *Server) RepairImage(ctx context.Context, req *v1.RepairImageRequest) (*v1.RepairImageResponse, error) {
+	if req.Digest == "" || len(req.Blobs) == 0 {
+		return &v1.RepairImageResponse{Success: false, Error: "digest and blobs are required"}, nil
+	}
+	if req.SourceEndpoint == "" && req.RegistryRef == "" {
+		return &v1.RepairImageResponse{Success: false, Error: "source_endpoint or registry_ref is required"}, nil
+	}
+
+	fetch := s.fetchFromRegistry(req)
+	if req.SourceEndpoint != "" {
+		if req.SessionToken == "" {
+			return &v1.RepairImageResponse{Success: false, Error: "session_token is required with source_endpoint"}, nil
+		}
+		conn, err := grpc.NewClient(req.SourceEndpoint, s.dialOption())
+		if err != nil {
+			return &v1.RepairImageResponse{Success: false, Error: fmt.Sprintf("connecting to source: %v", err)}, nil
+		}
+		defer func() { _ = conn.Close() }()
+		fetch = fetchFromPeer(v1.NewToteAgentClient(conn), req.SessionToken, s.gauges())
+	}
+
+	for _, blob := range req.Blobs {
+		rc, err := fetch(ctx, blob)
+		if err != nil {
+			return &v1.RepairImageResponse{Success: false, Error: fmt.Sprintf("fetching blob %s: %v", blob, err)}, nil
+		}
+		err = s.Store.ImportBlob(ctx, blob, rc)
+		_ = rc.Close()
+		if err != nil {
+			return &v1.RepairImageResponse{Success: false, Error: fmt.Sprintf("importing blob %s: %v", blob, err)}, nil
+		}
+	}
+
+	if err := s.Store.Verify(ctx, req.Digest); err != nil {
+		resp := &v1.RepairImageResponse{Success: false, Error: fmt.Sprintf("verifying repaired image: %v", err)}
+		var incomplete *IncompleteImageError
+		if errors.As(err, &incomplete) {
+			resp.MissingBlobs = incomplete.Blobs()
+		}
+		return resp, nil
+	}
+
+	return &v1.RepairImageResponse{Success: true}, nil
+}
+
+// blobFetcher opens a single blob for reading.
+type blobFetcher func(ctx context.Context, blob string) (io.ReadCloser, error)
+
+func (s *Server) fetchFromRegistry(req *v1.RepairImageRequest) blobFetcher {
+	return func(ctx context.Context, blob string) (io.ReadCloser, error) {
+		return registry.FetchBlob(ctx, req.RegistryRef, blob, req.RegistryUsername, req.RegistryPassword, req.Insecure)
+	}
+}
+
+// fetchFromPeer streams a blob from another agent's ExportBlob RPC.
+func fetchFromPeer(source v1.ToteAgentClient, token string, m *metrics.AgentGauges) blobFetcher {
+	return func(ctx context.Context, blob string) (io.ReadCloser, error) {
+		stream, err := source.ExportBlob(ctx, &v1.ExportBlobRequest{SessionToken: token, BlobDigest: blob})
+		if err != nil {
+			return nil, fmt.Errorf("starting blob stream: %w", err)
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			_ = pw.CloseWithError(newChunkReceiver(stream.Recv, m).copyTo(pw))
+		}()
+		return pr, nil
+	}
+}
+
+// gauges returns the agent's metrics, or nil if it has none.
+func (s *Server) gauges() *metrics.AgentGauges {
+	if s.Throttle == nil {
+		return nil
+	}
+	return s.Throttle.metrics
+}
+
+func (s *Server) dialOption() grpc.DialOption {
+	if s.ClientCreds != nil {
+		return grpc.WithTransportCredentials(s.ClientCreds)
+	}
+	return grpc.WithTransportCredentials(insecure.NewCredentials())
+}
@@ -5,18 +5,31 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/config"
 	"github.com/ppiankov/tote/internal/session"
 )
 
 func startTestServer(t *testing.T, store ImageStore, sessions *session.Store) (v1.ToteAgentClient, func()) {
 	t.Helper()
+	client, _, cleanup := startTestServerAddr(t, store, sessions)
+	return client, cleanup
+}
+
+// startTestServerAddr is like startTestServer but also returns the listen
+// address so another agent can stream from it.
+func startTestServerAddr(t *testing.T, store ImageStore, sessions *session.Store) (v1.ToteAgentClient, string, func()) {
+	t.Helper()
 
 	lis, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -40,7 +53,7 @@ func startTestServer(t *testing.T, store ImageStore, sessions *session.Store) (v
 		_ = conn.Close()
 		srv.Stop()
 	}
-	return client, cleanup
+	return client, lis.Addr().String(), cleanup
 }
 
 func TestListImages(t *testing.T) {
@@ -149,6 +162,40 @@ func TestPrepareExport_RegistersSession(t *testing.T) {
 	}
 }
 
+func TestPrepareExport_Platform(t *testing.T) {
+	store := NewFakeImageStore()
+	store.AddImage("sha256:aaa", []byte("data"))
+	store.SetPlatforms("sha256:aaa", "linux/amd64")
+	sessions := session.NewStore()
+
+	client, cleanup := startTestServer(t, store, sessions)
+	defer cleanup()
+
+	_, err := client.PrepareExport(context.Background(), &v1.PrepareExportRequest{
+		SessionToken: "arm-token",
+		Digest:       "sha256:aaa",
+		Platform:     "linux/arm64",
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for uncovered platform, got %v", err)
+	}
+	if _, ok := sessions.Validate("arm-token"); ok {
+		t.Error("session must not be registered when the platform is not covered")
+	}
+
+	_, err = client.PrepareExport(context.Background(), &v1.PrepareExportRequest{
+		SessionToken: "amd-token",
+		Digest:       "sha256:aaa",
+		Platform:     "linux/amd64",
+	})
+	if err != nil {
+		t.Fatalf("PrepareExport: %v", err)
+	}
+	if sess, ok := sessions.Validate("amd-token"); !ok || sess.Platform != "linux/amd64" {
+		t.Errorf("expected session with platform linux/amd64, got %+v", sess)
+	}
+}
+
 func TestExportImage_Success(t *testing.T) {
 	store := NewFakeImageStore()
 	store.AddImage("sha256:aaa", []byte("image-tar-data"))
@@ -260,3 +307,117 @@ func TestListImages_StoreError(t *testing.T) {
 		t.Fatal("expected error when store fails")
 	}
 }
+
+func TestImportFrom_Success(t *testing.T) {
+	source := NewFakeImageStore()
+	source.AddImage("sha256:fake-14", []byte("image-tar-data"))
+	sourceSessions := session.NewStore()
+	sess := sourceSessions.Create("sha256:fake-14", "node-a", "node-b", 5*time.Minute)
+	_, sourceAddr, sourceCleanup := startTestServerAddr(t, source, sourceSessions)
+	defer sourceCleanup()
+
+	target := NewFakeImageStore()
+	client, cleanup := startTestServer(t, target, session.NewStore())
+	defer cleanup()
+
+	resp, err := client.ImportFrom(context.Background(), &v1.ImportFromRequest{
+		SessionToken:   sess.Token,
+		Digest:         "sha256:fake-14",
+		SourceEndpoint: sourceAddr,
+	})
+	if err != nil {
+		t.Fatalf("ImportFrom: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error %q", resp.Error)
+	}
+}
+
+func TestImportFrom_RecordsNamesAndSource(t *testing.T) {
+	source := NewFakeImageStore()
+	source.AddImage("sha256:fake-14", []byte("image-tar-data"))
+	sourceSessions := session.NewStore()
+	sess := sourceSessions.Create("sha256:fake-14", "node-a", "node-b", 5*time.Minute)
+	_, sourceAddr, sourceCleanup := startTestServerAddr(t, source, sourceSessions)
+	defer sourceCleanup()
+
+	target := NewFakeImageStore()
+	client, cleanup := startTestServer(t, target, session.NewStore())
+	defer cleanup()
+
+	resp, err := client.ImportFrom(context.Background(), &v1.ImportFromRequest{
+		SessionToken:   sess.Token,
+		Digest:         "sha256:fake-14",
+		SourceEndpoint: sourceAddr,
+		ImageNames:     []string{"registry.example.com/app:v1", "registry.example.com/app:latest"},
+		SourceNode:     "node-a",
+	})
+	if err != nil {
+		t.Fatalf("ImportFrom: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error %q", resp.Error)
+	}
+
+	names, _ := target.Names(context.Background(), "sha256:fake-14")
+	if len(names) != 2 {
+		t.Errorf("expected both names recorded, got %v", names)
+	}
+	if got := target.Labels("sha256:fake-14")[config.LabelSalvagedFrom]; got != "node-a" {
+		t.Errorf("expected salvaged-from label node-a, got %q", got)
+	}
+}
+
+func TestPrepareExport_ReturnsImageNames(t *testing.T) {
+	store := NewFakeImageStore()
+	store.AddImage("sha256:aaa", []byte("data"))
+	store.AddTag("registry.example.com/app:v1", "sha256:aaa")
+	store.AddTag("registry.example.com/app:stable", "sha256:aaa")
+
+	client, cleanup := startTestServer(t, store, session.NewStore())
+	defer cleanup()
+
+	resp, err := client.PrepareExport(context.Background(), &v1.PrepareExportRequest{
+		SessionToken: "test-token",
+		Digest:       "sha256:aaa",
+	})
+	if err != nil {
+		t.Fatalf("PrepareExport: %v", err)
+	}
+	want := []string{"registry.example.com/app:stable", "registry.example.com/app:v1"}
+	if !slices.Equal(resp.ImageNames, want) {
+		t.Errorf("expected %v, got %v", want, resp.ImageNames)
+	}
+}
+
+func TestImportFrom_IncompleteContent(t *testing.T) {
+	source := NewFakeImageStore()
+	source.AddImage("sha256:fake-14", []byte("image-tar-data"))
+	sourceSessions := session.NewStore()
+	sess := sourceSessions.Create("sha256:fake-14", "node-a", "node-b", 5*time.Minute)
+	_, sourceAddr, sourceCleanup := startTestServerAddr(t, source, sourceSessions)
+	defer sourceCleanup()
+
+	target := NewFakeImageStore()
+	target.SetMissingBlobs("sha256:fake-14", []string{"sha256:layer1", "sha256:layer2"})
+	client, cleanup := startTestServer(t, target, session.NewStore())
+	defer cleanup()
+
+	resp, err := client.ImportFrom(context.Background(), &v1.ImportFromRequest{
+		SessionToken:   sess.Token,
+		Digest:         "sha256:fake-14",
+		SourceEndpoint: sourceAddr,
+	})
+	if err != nil {
+		t.Fatalf("ImportFrom: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected failure for incomplete image")
+	}
+	if len(resp.MissingBlobs) != 2 || resp.MissingBlobs[0] != "sha256:layer1" {
+		t.Errorf("expected missing blobs [sha256:layer1 sha256:layer2], got %v", resp.MissingBlobs)
+	}
+	if !strings.Contains(resp.Error, "sha256:layer2") {
+		t.Errorf("expected error to list missing blobs, got %q", resp.Error)
+	}
+}
@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+// Transfer directions, used as metric labels.
+const (
+	directionExport = "export"
+	directionImport = "import"
+)
+
+// maxThrottleBurst caps how many bytes pass a bandwidth limit at once.
+const maxThrottleBurst = 1024 * 1024 // 1 MiB
+
+// ThrottleConfig holds the agent's transfer limits. Zero means unlimited.
+type ThrottleConfig struct {
+	ExportBytesPerSec int64
+	ImportBytesPerSec int64
+	MaxExports        int
+}
+
+// ParseBandwidth parses a bytes-per-second value such as "50Mi" or
+// "100M". Empty or "0" means unlimited.
+func ParseBandwidth(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q: %w", s, err)
+	}
+	if q.Sign() < 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q: must not be negative", s)
+	}
+	return q.Value(), nil
+}
+
+// WithNodeLabels returns c with any limits set by the tote.dev throttle
+// labels on the agent's node applied on top.
+func (c ThrottleConfig) WithNodeLabels(labels map[string]string) (ThrottleConfig, error) {
+	if v, ok := labels[config.LabelExportBandwidth]; ok {
+		bps, err := ParseBandwidth(v)
+		if err != nil {
+			return c, fmt.Errorf("label %s: %w", config.LabelExportBandwidth, err)
+		}
+		c.ExportBytesPerSec = bps
+	}
+	if v, ok := labels[config.LabelImportBandwidth]; ok {
+		bps, err := ParseBandwidth(v)
+		if err != nil {
+			return c, fmt.Errorf("label %s: %w", config.LabelImportBandwidth, err)
+		}
+		c.ImportBytesPerSec = bps
+	}
+	if v, ok := labels[config.LabelMaxExports]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c, fmt.Errorf("label %s: invalid value %q", config.LabelMaxExports, v)
+		}
+		c.MaxExports = n
+	}
+	return c, nil
+}
+
+// Throttle limits the agent's transfer bandwidth and how many exports it
+// serves at once, so a salvage does not saturate a node serving production
+// traffic.
+type Throttle struct {
+	export  *rate.Limiter // nil = unlimited
+	imp     *rate.Limiter // nil = unlimited
+	exports chan struct{} // nil = unlimited
+	metrics *metrics.AgentGauges
+}
+
+// NewThrottle creates a Throttle for cfg. m may be nil.
+func NewThrottle(cfg ThrottleConfig, m *metrics.AgentGauges) *Throttle {
+	t := &Throttle{
+		export:  newLimiter(cfg.ExportBytesPerSec),
+		imp:     newLimiter(cfg.ImportBytesPerSec),
+		metrics: m,
+	}
+	if cfg.MaxExports > 0 {
+		t.exports = make(chan struct{}, cfg.MaxExports)
+	}
+	return t
+}
+
+func newLimiter(bps int64) *rate.Limiter {
+	if bps <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bps), int(min(bps, maxThrottleBurst)))
+}
+
+// ExportsAvailable reports whether another export could start now.
+func (t *Throttle) ExportsAvailable() bool {
+	return t == nil || t.exports == nil || len(t.exports) < cap(t.exports)
+}
+
+// acquireExport waits for an export slot. The returned func releases it.
+func (t *Throttle) acquireExport(ctx context.Context) (func(), error) {
+	if t == nil || t.exports == nil {
+		return func() {}, nil
+	}
+	select {
+	case t.exports <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	t.setActiveExports()
+	return func() {
+		<-t.exports
+		t.setActiveExports()
+	}, nil
+}
+
+func (t *Throttle) setActiveExports() {
+	if t.metrics != nil {
+		t.metrics.SetActiveExports(len(t.exports))
+	}
+}
+
+// exportWriter wraps w with the export bandwidth limit.
+func (t *Throttle) exportWriter(ctx context.Context, w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	return t.writer(ctx, w, t.export, directionExport)
+}
+
+// importWriter wraps w with the import bandwidth limit.
+func (t *Throttle) importWriter(ctx context.Context, w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	return t.writer(ctx, w, t.imp, directionImport)
+}
+
+func (t *Throttle) writer(ctx context.Context, w io.Writer, l *rate.Limiter, direction string) io.Writer {
+	return &throttledWriter{ctx: ctx, w: w, limiter: l, direction: direction, metrics: t.metrics}
+}
+
+// throttledWriter delays writes to stay under a bandwidth limit and counts
+// bytes and time spent throttled.
+type throttledWriter struct {
+	ctx       context.Context
+	w         io.Writer
+	limiter   *rate.Limiter // nil = unlimited
+	direction string
+	metrics   *metrics.AgentGauges
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if tw.limiter != nil {
+			n = min(n, tw.limiter.Burst())
+			if err := tw.wait(n); err != nil {
+				return written, err
+			}
+		}
+		m, err := tw.w.Write(p[:n])
+		written += m
+		if tw.metrics != nil {
+			tw.metrics.RecordTransferBytes(tw.direction, m)
+		}
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (tw *throttledWriter) wait(n int) error {
+	r := tw.limiter.ReserveN(time.Now(), n)
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-tw.ctx.Done():
+		r.Cancel()
+		return tw.ctx.Err()
+	}
+	if tw.metrics != nil {
+		tw.metrics.RecordThrottled(tw.direction, delay)
+	}
+	return nil
+}
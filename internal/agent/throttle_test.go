@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/session"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"50Mi", 50 * 1024 * 1024, false},
+		{"100M", 100_000_000, false},
+		{"-1", 0, true},
+		{"fast", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBandwidth(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBandwidth(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBandwidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestThrottleConfig_WithNodeLabels(t *testing.T) {
+	flags := ThrottleConfig{ExportBytesPerSec: 1000, ImportBytesPerSec: 2000, MaxExports: 2}
+
+	got, err := flags.WithNodeLabels(map[string]string{
+		config.LabelExportBandwidth: "10Mi",
+		config.LabelMaxExports:      "1",
+		"kubernetes.io/hostname":    "node-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ThrottleConfig{ExportBytesPerSec: 10 * 1024 * 1024, ImportBytesPerSec: 2000, MaxExports: 1}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := flags.WithNodeLabels(map[string]string{config.LabelMaxExports: "many"}); err == nil {
+		t.Error("expected error for invalid max-exports label")
+	}
+}
+
+func TestThrottledWriter_LimitsBandwidth(t *testing.T) {
+	gauges := metrics.NewAgentGauges(prometheus.NewRegistry())
+	th := NewThrottle(ThrottleConfig{ExportBytesPerSec: 1024 * 1024}, gauges)
+
+	var buf bytes.Buffer
+	w := th.exportWriter(context.Background(), &buf)
+	start := time.Now()
+	// The first MiB passes as burst; the rest has to wait about 0.5s.
+	if _, err := w.Write(make([]byte, 1536*1024)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected write to be throttled, took %v", elapsed)
+	}
+	if buf.Len() != 1536*1024 {
+		t.Errorf("expected all bytes written, got %d", buf.Len())
+	}
+	if v := testutil.ToFloat64(gauges.TransferBytes.WithLabelValues(directionExport)); v != 1536*1024 {
+		t.Errorf("expected transfer bytes to be counted, got %f", v)
+	}
+	if v := testutil.ToFloat64(gauges.ThrottledSeconds.WithLabelValues(directionExport)); v <= 0 {
+		t.Error("expected throttled time to be recorded")
+	}
+}
+
+func TestThrottledWriter_CancelledContext(t *testing.T) {
+	th := NewThrottle(ThrottleConfig{ImportBytesPerSec: 1024}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := th.importWriter(ctx, &bytes.Buffer{})
+	if _, err := w.Write(make([]byte, 4096)); err == nil {
+		t.Error("expected cancelled write to fail")
+	}
+}
+
+func TestThrottle_ExportSlots(t *testing.T) {
+	th := NewThrottle(ThrottleConfig{MaxExports: 1}, nil)
+
+	release, err := th.acquireExport(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if th.ExportsAvailable() {
+		t.Error("expected no export slot to be available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := th.acquireExport(ctx); err == nil {
+		t.Error("expected second export to wait until the context expired")
+	}
+
+	release()
+	if !th.ExportsAvailable() {
+		t.Error("expected export slot to be released")
+	}
+}
+
+func TestPrepareExport_RejectsWhenExportsBusy(t *testing.T) {
+	store := NewFakeImageStore()
+	store.AddImage("sha256:aaa", []byte("data"))
+	gauges := metrics.NewAgentGauges(prometheus.NewRegistry())
+	srv := &Server{Store: store, Sessions: session.NewStore(), Throttle: NewThrottle(ThrottleConfig{MaxExports: 1}, gauges)}
+
+	release, _ := srv.Throttle.acquireExport(context.Background())
+	defer release()
+
+	_, err := srv.PrepareExport(context.Background(), &v1.PrepareExportRequest{SessionToken: "tok", Digest: "sha256:aaa"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if v := testutil.ToFloat64(gauges.RejectedExports); v != 1 {
+		t.Errorf("expected 1 rejected export, got %f", v)
+	}
+}
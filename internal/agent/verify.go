@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	ctrimg "github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// IncompleteImageError reports the blobs of an image that are missing from
+// the content store or whose size or digest does not match the descriptor
+// that references them.
+type IncompleteImageError struct {
+	Digest  string
+	Missing []string
+	Corrupt []string
+}
+
+func (e *IncompleteImageError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing blobs [%s]", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Corrupt) > 0 {
+		parts = append(parts, fmt.Sprintf("corrupt blobs [%s]", strings.Join(e.Corrupt, ", ")))
+	}
+	return fmt.Sprintf("image %s is incomplete: %s", e.Digest, strings.Join(parts, "; "))
+}
+
+// Blobs returns every blob digest that must be fetched again for the image
+// to be usable: missing blobs first, then corrupt ones.
+func (e *IncompleteImageError) Blobs() []string {
+	blobs := make([]string, 0, len(e.Missing)+len(e.Corrupt))
+	blobs = append(blobs, e.Missing...)
+	return append(blobs, e.Corrupt...)
+}
+
+// verifyContent walks the manifest/index tree rooted at target and checks
+// that every blob kubelet needs to run the image on the given platform is
+// present in the content store with the expected size and, when rehash is
+// set, the expected digest. Only the best-matching manifest of an index is
+// followed, mirroring what the CRI plugin pulls. Returns an
+// *IncompleteImageError listing every bad blob.
+func verifyContent(ctx context.Context, provider content.Provider, target ocispec.Descriptor, platform platforms.MatchComparer, rehash bool) error {
+	incomplete := &IncompleteImageError{Digest: target.Digest.String()}
+	children := ctrimg.LimitManifests(ctrimg.FilterPlatforms(ctrimg.ChildrenHandler(provider), platform), platform, 1)
+
+	handler := ctrimg.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if err := verifyBlob(ctx, provider, desc, rehash); err != nil {
+			switch {
+			case errdefs.IsNotFound(err):
+				incomplete.Missing = append(incomplete.Missing, desc.Digest.String())
+			case errors.Is(err, errBlobMismatch):
+				incomplete.Corrupt = append(incomplete.Corrupt, desc.Digest.String())
+			default:
+				return nil, err
+			}
+			// Children of a bad blob cannot be read; skip them.
+			return nil, ctrimg.ErrSkipDesc
+		}
+		return children(ctx, desc)
+	})
+
+	if err := ctrimg.Walk(ctx, handler, target); err != nil {
+		return fmt.Errorf("walking image %s: %w", target.Digest, err)
+	}
+	if len(incomplete.Missing) > 0 || len(incomplete.Corrupt) > 0 {
+		return incomplete
+	}
+	return nil
+}
+
+// findBlob walks every platform of the image rooted at target and returns
+// the descriptor of the blob with the given digest.
+func findBlob(ctx context.Context, provider content.Provider, target ocispec.Descriptor, blob string) (ocispec.Descriptor, error) {
+	var found *ocispec.Descriptor
+	children := ctrimg.ChildrenHandler(provider)
+	handler := ctrimg.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if found != nil {
+			return nil, ctrimg.ErrSkipDesc
+		}
+		if desc.Digest.String() == blob {
+			found = &desc
+			return nil, ctrimg.ErrSkipDesc
+		}
+		descs, err := children(ctx, desc)
+		if errdefs.IsNotFound(err) {
+			// A missing manifest on this node; its blobs are not servable.
+			return nil, ctrimg.ErrSkipDesc
+		}
+		return descs, err
+	})
+	if err := ctrimg.Walk(ctx, handler, target); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("walking image %s: %w", target.Digest, err)
+	}
+	if found == nil {
+		return ocispec.Descriptor{}, fmt.Errorf("blob %s not referenced by image %s: %w", blob, target.Digest, errdefs.ErrNotFound)
+	}
+	return *found, nil
+}
+
+var errBlobMismatch = errors.New("blob does not match descriptor")
+
+// verifyBlob compares the size of a single blob with the descriptor and, when
+// rehash is set, re-hashes it to compare the digest as well.
+func verifyBlob(ctx context.Context, provider content.Provider, desc ocispec.Descriptor, rehash bool) error {
+	ra, err := provider.ReaderAt(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ra.Close() }()
+
+	if ra.Size() != desc.Size {
+		return fmt.Errorf("%s: size %d, expected %d: %w", desc.Digest, ra.Size(), desc.Size, errBlobMismatch)
+	}
+	if !rehash {
+		return nil
+	}
+	verifier := desc.Digest.Verifier()
+	if _, err := io.Copy(verifier, content.NewReader(ra)); err != nil {
+		return fmt.Errorf("reading %s: %w", desc.Digest, err)
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("%s: digest mismatch: %w", desc.Digest, errBlobMismatch)
+	}
+	return nil
+}
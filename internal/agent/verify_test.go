@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testImage writes a single-platform image (index -> manifest -> config +
+// layer) into a local content store and returns the store, the index
+// descriptor and the layer descriptor.
+func testImage(t *testing.T, skipLayer bool) (content.Store, ocispec.Descriptor, ocispec.Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("creating content store: %v", err)
+	}
+
+	write := func(mediaType string, data []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+		if err := content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(data), desc); err != nil {
+			t.Fatalf("writing blob: %v", err)
+		}
+		return desc
+	}
+	marshal := func(v any) []byte {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return data
+	}
+
+	layerData := []byte("layer-data")
+	layer := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: digest.FromBytes(layerData), Size: int64(len(layerData))}
+	if !skipLayer {
+		write(layer.MediaType, layerData)
+	}
+	config := write(ocispec.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux"}`))
+	manifest := write(ocispec.MediaTypeImageManifest, marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	}))
+	platform := platforms.DefaultSpec()
+	manifest.Platform = &platform
+	index := write(ocispec.MediaTypeImageIndex, marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifest},
+	}))
+	return cs, index, layer
+}
+
+func TestVerifyContent_Complete(t *testing.T) {
+	cs, index, _ := testImage(t, false)
+	if err := verifyContent(context.Background(), cs, index, platforms.Default(), true); err != nil {
+		t.Fatalf("expected complete image, got %v", err)
+	}
+}
+
+func TestVerifyContent_MissingLayer(t *testing.T) {
+	cs, index, layer := testImage(t, true)
+	err := verifyContent(context.Background(), cs, index, platforms.Default(), true)
+
+	var incomplete *IncompleteImageError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("expected IncompleteImageError, got %v", err)
+	}
+	if len(incomplete.Missing) != 1 || incomplete.Missing[0] != layer.Digest.String() {
+		t.Errorf("expected missing [%s], got %v", layer.Digest, incomplete.Missing)
+	}
+}
+
+func TestVerifyContent_SizeMismatch(t *testing.T) {
+	cs, index, _ := testImage(t, false)
+	index.Size++
+	err := verifyContent(context.Background(), cs, index, platforms.Default(), true)
+
+	var incomplete *IncompleteImageError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("expected IncompleteImageError, got %v", err)
+	}
+	if len(incomplete.Corrupt) != 1 || incomplete.Corrupt[0] != index.Digest.String() {
+		t.Errorf("expected corrupt [%s], got %v", index.Digest, incomplete.Corrupt)
+	}
+}
+
+func TestVerifyContent_NoMatchingPlatform(t *testing.T) {
+	cs, index, _ := testImage(t, true)
+	// A matcher for a platform the index does not contain filters out the
+	// only manifest, so the missing layer is never looked at.
+	other := platforms.Only(ocispec.Platform{OS: "plan9", Architecture: "mips"})
+	err := verifyContent(context.Background(), cs, index, other, true)
+	if err == nil {
+		t.Fatal("expected error when no manifest matches the platform")
+	}
+	var incomplete *IncompleteImageError
+	if errors.As(err, &incomplete) {
+		t.Errorf("expected platform mismatch error, got %v", err)
+	}
+}
+
+func TestVerifyBlob_RehashDetectsBitRot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cs, err := local.NewStore(dir)
+	if err != nil {
+		t.Fatalf("creating content store: %v", err)
+	}
+	data := []byte("layer-data")
+	desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	if err := content.WriteBlob(ctx, cs, "bitrot", bytes.NewReader(data), desc); err != nil {
+		t.Fatalf("writing blob: %v", err)
+	}
+
+	// Flip the content on disk without changing its size.
+	path := filepath.Join(dir, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("LAYER-DATA"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyBlob(ctx, cs, desc, false); err != nil {
+		t.Errorf("presence check should pass on same-size content, got %v", err)
+	}
+	if err := verifyBlob(ctx, cs, desc, true); !errors.Is(err, errBlobMismatch) {
+		t.Errorf("expected digest mismatch on rehash, got %v", err)
+	}
+}
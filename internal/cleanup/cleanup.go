@@ -7,7 +7,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 )
 
 // Reaper periodically deletes expired SalvageRecords.
@@ -52,7 +52,7 @@ func (r *Reaper) Start(ctx context.Context) error {
 }
 
 func (r *Reaper) sweep(ctx context.Context, logger interface{ Info(string, ...interface{}) }) {
-	var list v1alpha1.SalvageRecordList
+	var list v1alpha2.SalvageRecordList
 	if err := r.Client.List(ctx, &list); err != nil {
 		return
 	}
@@ -60,14 +60,10 @@ func (r *Reaper) sweep(ctx context.Context, logger interface{ Info(string, ...in
 	cutoff := time.Now().Add(-r.TTL)
 	for i := range list.Items {
 		rec := &list.Items[i]
-		if rec.Status.CompletedAt == "" {
+		if rec.Status.CompletedAt == nil {
 			continue
 		}
-		completed, err := time.Parse(time.RFC3339, rec.Status.CompletedAt)
-		if err != nil {
-			continue
-		}
-		if completed.Before(cutoff) {
+		if rec.Status.CompletedAt.Time.Before(cutoff) {
 			if err := r.Client.Delete(ctx, rec); err == nil {
 				logger.Info("deleted expired SalvageRecord", "name", rec.Name, "namespace", rec.Namespace)
 			}
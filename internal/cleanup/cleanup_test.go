@@ -9,28 +9,28 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
-	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 )
 
 func newScheme() *runtime.Scheme {
 	s := runtime.NewScheme()
-	_ = v1alpha1.AddToScheme(s)
+	_ = v1alpha2.AddToScheme(s)
 	return s
 }
 
 func TestReaper_DeletesExpiredRecords(t *testing.T) {
-	old := &v1alpha1.SalvageRecord{
+	old := &v1alpha2.SalvageRecord{
 		ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default"},
-		Status: v1alpha1.SalvageRecordStatus{
-			Phase:       "Completed",
-			CompletedAt: time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339),
+		Status: v1alpha2.SalvageRecordStatus{
+			Phase:       v1alpha2.PhaseCompleted,
+			CompletedAt: &metav1.Time{Time: time.Now().Add(-48 * time.Hour)},
 		},
 	}
-	recent := &v1alpha1.SalvageRecord{
+	recent := &v1alpha2.SalvageRecord{
 		ObjectMeta: metav1.ObjectMeta{Name: "recent", Namespace: "default"},
-		Status: v1alpha1.SalvageRecordStatus{
-			Phase:       "Completed",
-			CompletedAt: time.Now().Add(-1 * time.Hour).UTC().Format(time.RFC3339),
+		Status: v1alpha2.SalvageRecordStatus{
+			Phase:       v1alpha2.PhaseCompleted,
+			CompletedAt: &metav1.Time{Time: time.Now().Add(-1 * time.Hour)},
 		},
 	}
 
@@ -40,7 +40,7 @@ func TestReaper_DeletesExpiredRecords(t *testing.T) {
 	r := NewReaper(cl, 24*time.Hour, 5*time.Minute)
 	r.sweep(context.Background(), &nopLogger{})
 
-	var list v1alpha1.SalvageRecordList
+	var list v1alpha2.SalvageRecordList
 	if err := cl.List(context.Background(), &list); err != nil {
 		t.Fatal(err)
 	}
@@ -53,9 +53,9 @@ func TestReaper_DeletesExpiredRecords(t *testing.T) {
 }
 
 func TestReaper_SkipsNoCompletedAt(t *testing.T) {
-	rec := &v1alpha1.SalvageRecord{
+	rec := &v1alpha2.SalvageRecord{
 		ObjectMeta: metav1.ObjectMeta{Name: "no-time", Namespace: "default"},
-		Status:     v1alpha1.SalvageRecordStatus{Phase: "Failed"},
+		Status:     v1alpha2.SalvageRecordStatus{Phase: v1alpha2.PhaseFailed},
 	}
 
 	cl := fake.NewClientBuilder().WithScheme(newScheme()).
@@ -64,7 +64,7 @@ func TestReaper_SkipsNoCompletedAt(t *testing.T) {
 	r := NewReaper(cl, 24*time.Hour, 5*time.Minute)
 	r.sweep(context.Background(), &nopLogger{})
 
-	var list v1alpha1.SalvageRecordList
+	var list v1alpha2.SalvageRecordList
 	if err := cl.List(context.Background(), &list); err != nil {
 		t.Fatal(err)
 	}
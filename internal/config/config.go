@@ -12,15 +12,90 @@ const (
 	// AnnotationPodAutoSalvage is required on the Pod.
 	AnnotationPodAutoSalvage = "tote.dev/auto-salvage"
 
+	// AnnotationNamespacePriority is an optional integer on the Namespace;
+	// queued salvages of higher-priority namespaces run first.
+	AnnotationNamespacePriority = "tote.dev/priority"
+
+	// AnnotationLastSalvage is set on a pod's owning workload and summarizes
+	// the last salvage of one of its pods as JSON.
+	AnnotationLastSalvage = "tote.dev/last-salvage"
+
+	// AnnotationSalvageStrategy on a Pod, its workload or its Namespace
+	// picks how a pull failure is fixed: StrategyTransfer or
+	// StrategyReschedule. It overrides --salvage-strategy.
+	AnnotationSalvageStrategy = "tote.dev/salvage-strategy"
+
+	// AnnotationReschedule is set on a workload whose pod was rescheduled
+	// and lists, as JSON, the nodes its new pods should prefer.
+	AnnotationReschedule = "tote.dev/reschedule"
+
+	// AnnotationRescheduled is set by the pod webhook on a pod it steered
+	// and holds the image digest, so the pod is not rescheduled twice.
+	AnnotationRescheduled = "tote.dev/rescheduled"
+
+	// AnnotationRecovery on a Pod, its workload or its Namespace picks how
+	// a pod is restarted once its image is fixed: RecoveryDelete,
+	// RecoveryWait, RecoveryEvict or RecoveryRestart. It overrides
+	// --recovery.
+	AnnotationRecovery = "tote.dev/recovery"
+
+	// StrategyTransfer copies the image onto the failing pod's node.
+	StrategyTransfer = "transfer"
+
+	// StrategyReschedule deletes the failing pod and steers its
+	// replacement onto nodes that already cache the image.
+	StrategyReschedule = "reschedule"
+
+	// RecoveryDelete deletes the pod so its controller recreates it.
+	RecoveryDelete = "delete"
+
+	// RecoveryWait leaves the pod to kubelet's image pull backoff.
+	RecoveryWait = "wait"
+
+	// RecoveryEvict evicts the pod through the Eviction API, so
+	// PodDisruptionBudgets are honoured.
+	RecoveryEvict = "evict"
+
+	// RecoveryRestart restarts the pod's Deployment, StatefulSet or
+	// DaemonSet, as kubectl rollout restart does.
+	RecoveryRestart = "restart"
+
+	// LabelSalvagedFrom is set on containerd image records created by a
+	// salvage and holds the source node name.
+	LabelSalvagedFrom = "tote.dev/salvaged-from"
+
+	// LabelExportBandwidth, LabelImportBandwidth and LabelMaxExports on a
+	// Node override the agent's transfer limits there. Bandwidths are
+	// bytes per second as a quantity ("50Mi"); "0" means unlimited.
+	LabelExportBandwidth = "tote.dev/export-bandwidth"
+	LabelImportBandwidth = "tote.dev/import-bandwidth"
+	LabelMaxExports      = "tote.dev/max-exports"
+
 	// DefaultContainerdSocket is the default containerd socket path.
 	DefaultContainerdSocket = "/run/containerd/containerd.sock"
 
 	// DefaultAgentGRPCPort is the default gRPC port for the agent.
 	DefaultAgentGRPCPort = 9090
 
+	// DefaultWebhookPort is the default port of the controller's webhook server.
+	DefaultWebhookPort = 9443
+
 	// DefaultMaxConcurrentSalvages is the default concurrent salvage limit.
 	DefaultMaxConcurrentSalvages = 2
 
+	// DefaultMaxSalvagesPerNode is the default concurrent salvage limit onto one node.
+	DefaultMaxSalvagesPerNode = 1
+
+	// DefaultMaxSalvagesPerSource is the default concurrent salvage limit served by one node.
+	DefaultMaxSalvagesPerSource = 2
+
+	// DefaultPreseedMaxNodes is the default number of nodes an image is pre-seeded onto per failure.
+	DefaultPreseedMaxNodes = 5
+
+	// DefaultRescheduleTTL is how long new pods of a rescheduled workload
+	// prefer the nodes caching the image.
+	DefaultRescheduleTTL = 10 * time.Minute
+
 	// DefaultSessionTTL is the default session lifetime.
 	DefaultSessionTTL = 5 * time.Minute
 
@@ -29,6 +104,52 @@ const (
 
 	// DefaultRegistryResolveTimeout is the default timeout for registry tag resolution.
 	DefaultRegistryResolveTimeout = 5 * time.Second
+
+	// DefaultAgentMaxExports is the default number of exports an agent serves at once.
+	DefaultAgentMaxExports = 2
+
+	// DefaultAgentChunkSize is the default size of a transfer stream chunk (32 KiB).
+	DefaultAgentChunkSize = 32 * 1024
+
+	// DefaultAgentCompression is the default transfer stream compression.
+	DefaultAgentCompression = "zstd"
+
+	// DefaultAgentScanInterval is how often agents check their images for missing content.
+	DefaultAgentScanInterval = 10 * time.Minute
+
+	// DefaultCorruptScanPollInterval is how often the controller collects agent scan results.
+	DefaultCorruptScanPollInterval = 5 * time.Minute
+
+	// DefaultImageRiskInterval is how often ClusterImageRisk reports are refreshed.
+	DefaultImageRiskInterval = 30 * time.Minute
+
+	// DefaultRegistryOutageThreshold is how many pods failing to pull from one registry declare an outage.
+	DefaultRegistryOutageThreshold = 5
+
+	// DefaultRegistryOutageWindow is how recent those failures must be; it outlasts kubelet's 5m pull backoff.
+	DefaultRegistryOutageWindow = 10 * time.Minute
+
+	// DefaultNotifyQueueSize is how many notifications may wait for delivery.
+	DefaultNotifyQueueSize = 1000
+
+	// DefaultNotifyWorkers is the number of concurrent notification deliveries.
+	DefaultNotifyWorkers = 4
+
+	// DefaultNotifyMaxAttempts is how often a notification is tried before it is dead-lettered.
+	DefaultNotifyMaxAttempts = 5
+
+	// NotifyRetryBackoff is the delay before the first notification retry; it doubles up to NotifyMaxRetryBackoff.
+	NotifyRetryBackoff    = time.Second
+	NotifyMaxRetryBackoff = time.Minute
+
+	// DefaultNotifyAggregationWindow is how long workload events are held to coalesce pods.
+	DefaultNotifyAggregationWindow = 30 * time.Second
+
+	// DefaultNotifyMaxPerMinute caps notifications sent per minute across all sinks.
+	DefaultNotifyMaxPerMinute = 60
+
+	// NotifySigningKeyField is the Secret key holding the webhook HMAC signing key.
+	NotifySigningKeyField = "signing-key"
 )
 
 // DefaultDeniedNamespaces are always excluded regardless of annotations.
@@ -49,6 +170,35 @@ type Config struct {
 	// MaxConcurrentSalvages limits parallel salvage operations.
 	MaxConcurrentSalvages int
 
+	// MaxSalvagesPerNode limits parallel salvages onto one node.
+	MaxSalvagesPerNode int
+
+	// MaxSalvagesPerSource limits parallel salvages served by one node.
+	MaxSalvagesPerSource int
+
+	// PreseedUnscheduled copies a failing workload's image onto nodes its
+	// unscheduled replicas can land on.
+	PreseedUnscheduled bool
+
+	// PreseedMaxNodes caps the nodes pre-seeded per failure.
+	PreseedMaxNodes int
+
+	// SalvageStrategy is the default StrategyTransfer or StrategyReschedule
+	// for pods without a tote.dev/salvage-strategy annotation.
+	SalvageStrategy string
+
+	// PodWebhook is set when the pod webhook steering rescheduled pods is
+	// configured. Without it StrategyReschedule falls back to transfer.
+	PodWebhook bool
+
+	// Recovery is the default RecoveryDelete, RecoveryWait, RecoveryEvict
+	// or RecoveryRestart for pods without a tote.dev/recovery annotation.
+	Recovery string
+
+	// RegistryOutagePriority is added to the queue priority of salvages whose
+	// image is on a registry in an outage. 0 = no boost.
+	RegistryOutagePriority int
+
 	// SessionTTL is the lifetime for salvage sessions.
 	SessionTTL time.Duration
 
@@ -97,6 +247,11 @@ func New() Config {
 		Enabled:               true,
 		DeniedNamespaces:      denied,
 		MaxConcurrentSalvages: DefaultMaxConcurrentSalvages,
+		MaxSalvagesPerNode:    DefaultMaxSalvagesPerNode,
+		MaxSalvagesPerSource:  DefaultMaxSalvagesPerSource,
+		PreseedMaxNodes:       DefaultPreseedMaxNodes,
+		SalvageStrategy:       StrategyTransfer,
+		Recovery:              RecoveryDelete,
 		SessionTTL:            DefaultSessionTTL,
 		AgentGRPCPort:         DefaultAgentGRPCPort,
 		MaxImageSize:          DefaultMaxImageSize,
@@ -108,6 +263,23 @@ func (c Config) IsDenied(namespace string) bool {
 	return c.DeniedNamespaces[namespace]
 }
 
+// ValidateSalvageStrategy returns an error unless s is a known strategy.
+func ValidateSalvageStrategy(s string) error {
+	if s != StrategyTransfer && s != StrategyReschedule {
+		return fmt.Errorf("unknown salvage strategy %q: want %s or %s", s, StrategyTransfer, StrategyReschedule)
+	}
+	return nil
+}
+
+// ValidateRecovery returns an error unless s is a known recovery strategy.
+func ValidateRecovery(s string) error {
+	switch s {
+	case RecoveryDelete, RecoveryWait, RecoveryEvict, RecoveryRestart:
+		return nil
+	}
+	return fmt.Errorf("unknown recovery strategy %q: want %s, %s, %s or %s", s, RecoveryDelete, RecoveryWait, RecoveryEvict, RecoveryRestart)
+}
+
 // TLSEnabled returns true if all three TLS paths are set.
 func TLSEnabled(cert, key, ca string) bool {
 	return cert != "" && key != "" && ca != ""
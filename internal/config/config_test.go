@@ -23,6 +23,9 @@ func TestNew_SalvageDefaults(t *testing.T) {
 	if cfg.AgentGRPCPort != DefaultAgentGRPCPort {
 		t.Errorf("expected AgentGRPCPort=%d, got %d", DefaultAgentGRPCPort, cfg.AgentGRPCPort)
 	}
+	if cfg.SalvageStrategy != StrategyTransfer {
+		t.Errorf("expected SalvageStrategy=%s, got %s", StrategyTransfer, cfg.SalvageStrategy)
+	}
 }
 
 func TestNew_DefaultDeniedNamespaces(t *testing.T) {
@@ -75,3 +78,25 @@ func TestValidateTLSFlags(t *testing.T) {
 		t.Error("2 of 3 should be invalid")
 	}
 }
+
+func TestValidateSalvageStrategy(t *testing.T) {
+	for _, s := range []string{StrategyTransfer, StrategyReschedule} {
+		if err := ValidateSalvageStrategy(s); err != nil {
+			t.Errorf("%s should be valid: %v", s, err)
+		}
+	}
+	if err := ValidateSalvageStrategy("move"); err == nil {
+		t.Error("unknown strategy should be invalid")
+	}
+}
+
+func TestValidateRecovery(t *testing.T) {
+	for _, s := range []string{RecoveryDelete, RecoveryWait, RecoveryEvict, RecoveryRestart} {
+		if err := ValidateRecovery(s); err != nil {
+			t.Errorf("%s should be valid: %v", s, err)
+		}
+	}
+	if err := ValidateRecovery("drain"); err == nil {
+		t.Error("unknown recovery strategy should be invalid")
+	}
+}
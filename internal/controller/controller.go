@@ -2,11 +2,11 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
-	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -14,16 +14,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 	"github.com/ppiankov/tote/internal/config"
 	"github.com/ppiankov/tote/internal/detector"
 	"github.com/ppiankov/tote/internal/events"
 	"github.com/ppiankov/tote/internal/inventory"
 	"github.com/ppiankov/tote/internal/metrics"
 	"github.com/ppiankov/tote/internal/notify"
+	"github.com/ppiankov/tote/internal/outage"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/recovery"
 	"github.com/ppiankov/tote/internal/registry"
 	"github.com/ppiankov/tote/internal/resolver"
 	"github.com/ppiankov/tote/internal/transfer"
+	"github.com/ppiankov/tote/internal/workload"
 )
 
 // PodReconciler watches Pods for image pull failures.
@@ -34,9 +38,14 @@ type PodReconciler struct {
 	Emitter       *events.Emitter
 	Metrics       *metrics.Counters
 	Orchestrator  *transfer.Orchestrator
+	Queue         *transfer.Queue // nil = salvage inline
 	AgentResolver *transfer.Resolver
 	TagResolver   registry.TagResolver
 	Notifier      *notify.Notifier
+	Owners        *owners.Resolver    // nil = uncached lookups through Client
+	Recoverer     *recovery.Recoverer // nil = built from Config.Recovery
+	Outages       *outage.Tracker     // nil = no registry outage detection
+	APIReader     client.Reader       // uncached reads; nil = Client
 }
 
 // Reconcile handles a single Pod reconciliation.
@@ -60,7 +69,7 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		return reconcile.Result{}, nil
 	}
 
-	if !isAutoSalvageEnabled(ctx, r.Client, &pod) {
+	if !isAutoSalvageEnabled(ctx, ownerResolver(r.Owners, r.Client), &pod) {
 		return reconcile.Result{}, nil
 	}
 
@@ -69,11 +78,26 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		return reconcile.Result{}, nil
 	}
 
+	workloadKind, workloadName := workload.KindName(ctx, ownerResolver(r.Owners, r.Client), &pod)
+	var requeue bool
 	for _, f := range failures {
 		r.Metrics.RecordDetected()
+		if r.Outages != nil {
+			r.Outages.Observe(ctx, &pod, f)
+		}
+		// Every notification about this container, including those sent
+		// by the orchestrator, carries the incident context.
+		ictx := notify.WithIncident(ctx, notify.Incident{
+			CorrelationID: notify.CorrelationID(string(pod.UID), f.ContainerName),
+			WorkloadKind:  workloadKind,
+			WorkloadName:  workloadName,
+			Container:     f.ContainerName,
+			Reason:        f.Reason,
+			Message:       f.Message,
+		})
 		if r.Notifier != nil {
-			_ = r.Notifier.Notify(ctx, notify.Event{
-				Type:      "detected",
+			_ = r.Notifier.Notify(ictx, notify.Event{
+				Type:      notify.EventDetected,
 				PodName:   pod.Name,
 				Namespace: pod.Namespace,
 				ImageRef:  f.Image,
@@ -81,27 +105,34 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		}
 
 		// Corrupt image: record exists but content blobs are missing.
-		// Remove the stale record and restart the pod for a clean pull.
+		// Try to fetch the missing blobs from a peer; otherwise remove the
+		// stale record. Either way, restart the pod.
 		if f.CorruptImage {
 			r.Metrics.RecordCorruptImage()
 			if r.AgentResolver != nil && pod.Spec.NodeName != "" {
-				logger.Info("corrupt image detected, removing stale record", "image", f.Image, "node", pod.Spec.NodeName)
-				r.Emitter.EmitCorruptImage(&pod, f.Image, pod.Spec.NodeName)
-				if err := r.AgentResolver.RemoveImageOnNode(ctx, pod.Spec.NodeName, f.Image); err != nil {
-					logger.Error(err, "failed to remove corrupt image", "image", f.Image, "node", pod.Spec.NodeName)
-					continue
+				repaired, err := r.repairCorruptImage(ctx, &pod, f.Image)
+				if errors.Is(err, transfer.ErrRateLimited) {
+					return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 				}
-				if len(pod.OwnerReferences) > 0 {
-					if err := r.Client.Delete(ctx, &pod); err != nil {
-						logger.Error(err, "failed to delete pod after corrupt image cleanup", "pod", pod.Name)
-					} else {
-						logger.Info("deleted pod after corrupt image cleanup", "pod", pod.Name, "namespace", pod.Namespace)
+				if !repaired {
+					logger.Info("corrupt image detected, removing stale record", "image", f.Image, "node", pod.Spec.NodeName)
+					r.Emitter.EmitCorruptImage(ctx, &pod, f.Image, pod.Spec.NodeName)
+					if err := r.AgentResolver.RemoveImageOnNode(ctx, pod.Spec.NodeName, f.Image); err != nil {
+						logger.Error(err, "failed to remove corrupt image", "image", f.Image, "node", pod.Spec.NodeName)
+						continue
 					}
 				}
+				r.recoverer().Recover(ctx, &pod, f.Image)
 			}
 			continue
 		}
 
+		// Invalid names and rejected credentials: no node cache fixes
+		// the pod's configuration.
+		if r.handleConfigError(ctx, &pod, f) {
+			continue
+		}
+
 		res := resolver.Resolve(f.Image)
 
 		var digest string
@@ -141,8 +172,9 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 			}
 
 			if digest == "" {
-				// Step 2.5: Registry-assisted resolution (opt-in).
-				if r.TagResolver != nil {
+				// Step 2.5: Registry-assisted resolution (opt-in). Pointless
+				// when the registry already said it does not have the image.
+				if r.TagResolver != nil && f.Class != detector.ClassNotFound {
 					logger.V(1).Info("querying source registry for tag resolution", "image", f.Image)
 					start := time.Now()
 					regDigest, regErr := r.TagResolver.ResolveTag(ctx, f.Image)
@@ -163,7 +195,7 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 						} else {
 							// Digest exists in registry but no node has it.
 							logger.V(1).Info("resolved via registry but no node has digest cached", "image", f.Image, "digest", regDigest)
-							r.Emitter.EmitResolvedButUncached(&pod, f.Image, regDigest)
+							r.Emitter.EmitResolvedButUncached(ctx, &pod, f.Image, regDigest)
 							r.Metrics.RecordNotActionable()
 							continue
 						}
@@ -173,10 +205,14 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 				}
 			}
 
+			if digest == "" && f.Class == detector.ClassNotFound {
+				r.emitImageNotFound(ctx, &pod, f)
+				continue
+			}
 			if digest == "" {
 				logger.V(1).Info("image not actionable (tag-only, no cached digest found)", "container", f.ContainerName, "image", f.Image)
 				r.Metrics.RecordNotActionable()
-				r.Emitter.EmitNotActionable(&pod, f.Image)
+				r.Emitter.EmitNotActionable(ctx, &pod, f.Image)
 				continue
 			}
 			if len(nodes) == 0 {
@@ -185,52 +221,133 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		}
 
 		if len(nodes) > 0 {
-			logger.Info("image salvageable", "container", f.ContainerName, "digest", digest, "nodes", nodes)
+			logger.Info("image salvageable", "container", f.ContainerName, "kind", f.Kind, "digest", digest, "nodes", nodes)
 			r.Metrics.RecordSalvageable()
-			r.Emitter.EmitSalvageable(&pod, f.Image, nodes)
+			r.Emitter.EmitSalvageable(ctx, &pod, f.Image, nodes)
+
+			// Ephemeral debug containers are not part of the workload: its
+			// other replicas do not need the image, and replacing the pod
+			// would end the debug session.
+			ephemeral := f.Kind == detector.KindEphemeral
+			if r.Orchestrator != nil && r.Config.PreseedUnscheduled && !ephemeral {
+				err := r.preseed(ictx, &pod, digest, f.Image, workloadKind, nodes)
+				switch {
+				case errors.Is(err, transfer.ErrRateLimited):
+					requeue = true
+				case err != nil:
+					logger.Error(err, "failed to pre-seed image for unscheduled replicas", "digest", digest)
+				}
+			}
+
+			if pod.Spec.NodeName != "" && !ephemeral && r.salvageStrategy(ctx, &pod) == config.StrategyReschedule {
+				rescheduled, err := r.reschedule(ictx, &pod, f.Image, digest, nodes)
+				if err != nil {
+					logger.Error(err, "reschedule failed, transferring instead", "digest", digest)
+				}
+				if rescheduled {
+					// The pod is gone; its replacement is reconciled on its own.
+					return reconcile.Result{}, nil
+				}
+			}
 
 			if r.Orchestrator != nil && pod.Spec.NodeName != "" {
 				if hasSalvageRecord(ctx, r.Client, pod.Namespace, digest) {
 					continue
 				}
-				// Pick a source node that isn't the target node.
-				sourceNode := ""
+				// Source candidates are every node but the target; the
+				// orchestrator picks one that covers the target's platform.
+				var sourceNodes []string
 				for _, n := range nodes {
 					if n != pod.Spec.NodeName {
-						sourceNode = n
-						break
+						sourceNodes = append(sourceNodes, n)
 					}
 				}
-				if sourceNode == "" {
+				if len(sourceNodes) == 0 {
 					logger.V(1).Info("image already on target node, skipping salvage", "digest", digest, "node", pod.Spec.NodeName)
 					continue
 				}
-				if err := r.Orchestrator.Salvage(ctx, &pod, digest, f.Image, sourceNode); err != nil {
+				if r.Queue != nil {
+					if r.Queue.Enqueue(ictx, &transfer.Job{
+						Pod:         &pod,
+						Digest:      digest,
+						ImageRef:    f.Image,
+						SourceNodes: sourceNodes,
+						Priority:    r.salvagePriority(ctx, &pod, f.Image),
+						Kind:        workloadKind,
+					}) {
+						logger.Info("salvage queued", "digest", digest, "node", pod.Spec.NodeName)
+					}
+					continue
+				}
+				if err := r.Orchestrator.Salvage(ictx, &pod, digest, f.Image, sourceNodes); err != nil {
 					logger.Error(err, "salvage failed", "digest", digest)
 					if isTransientError(err) {
 						return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 					}
 				}
 			}
+		} else if f.Class == detector.ClassNotFound {
+			r.emitImageNotFound(ctx, &pod, f)
 		}
 	}
 
+	if requeue {
+		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+	}
 	return reconcile.Result{}, nil
 }
 
 // SetupWithManager registers the reconciler with the controller manager.
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	ctx := context.Background()
-	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.SalvageRecord{}, "spec.digest", func(obj client.Object) []string {
-		return []string{obj.(*v1alpha1.SalvageRecord).Spec.Digest}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha2.SalvageRecord{}, "spec.digest", func(obj client.Object) []string {
+		return []string{obj.(*v1alpha2.SalvageRecord).Spec.Digest}
 	}); err != nil {
 		return err
 	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &corev1.Pod{}, podControllerField, podControllerUID); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &corev1.Pod{}, podNodeField, podNodeName); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Pod{}).
 		Complete(r)
 }
 
+// repairCorruptImage asks the agent on the pod's node which blobs of image
+// are missing and has the orchestrator fetch them from another node or the
+// backup registry. Returns true if the image was repaired in place.
+func (r *PodReconciler) repairCorruptImage(ctx context.Context, pod *corev1.Pod, image string) (bool, error) {
+	logger := log.FromContext(ctx)
+	if r.Orchestrator == nil {
+		return false, nil
+	}
+
+	digest, missing, err := r.AgentResolver.CheckImageOnNode(ctx, pod.Spec.NodeName, image)
+	if err != nil {
+		logger.Error(err, "failed to check corrupt image", "image", image, "node", pod.Spec.NodeName)
+		return false, nil
+	}
+	if len(missing) == 0 {
+		// Content store is intact; the corruption is elsewhere (e.g. snapshots).
+		logger.V(1).Info("corrupt image has no missing blobs", "image", image, "digest", digest)
+		return false, nil
+	}
+
+	nodes, err := r.Finder.FindNodes(ctx, digest)
+	if err != nil {
+		logger.Error(err, "failed to find nodes with image", "digest", digest)
+	}
+	logger.Info("corrupt image detected, repairing missing blobs", "image", image, "digest", digest, "missing", len(missing), "nodes", nodes)
+	if err := r.Orchestrator.Repair(ctx, pod, digest, image, missing, nodes); err != nil {
+		logger.Error(err, "corrupt image repair failed", "image", image, "digest", digest)
+		return false, err
+	}
+	return true, nil
+}
+
 func namespaceOptedIn(ctx context.Context, c client.Reader, namespace string) bool {
 	var ns corev1.Namespace
 	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
@@ -239,73 +356,70 @@ func namespaceOptedIn(ctx context.Context, c client.Reader, namespace string) bo
 	return ns.Annotations[config.AnnotationNamespaceAllow] == "true"
 }
 
+// namespacePriority returns the namespace's tote.dev/priority, or 0 if it
+// is unset or not an integer.
+func namespacePriority(ctx context.Context, c client.Reader, namespace string) int {
+	var ns corev1.Namespace
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return 0
+	}
+	priority, _ := strconv.Atoi(ns.Annotations[config.AnnotationNamespacePriority])
+	return priority
+}
+
+// salvagePriority returns the queue priority of a salvage of image for the
+// pod: its namespace's priority, raised by Config.RegistryOutagePriority
+// while the image's registry is in an outage, since every pull from it fails
+// until the registry recovers.
+func (r *PodReconciler) salvagePriority(ctx context.Context, pod *corev1.Pod, image string) int {
+	priority := namespacePriority(ctx, r.Client, pod.Namespace)
+	if r.Outages.Active(resolver.Registry(image)) {
+		priority += r.Config.RegistryOutagePriority
+	}
+	return priority
+}
+
 // isAutoSalvageEnabled checks whether the pod or any owner in its chain has
-// the auto-salvage annotation. Walks ownerReferences up to 2 levels
-// (Pod → ReplicaSet → Deployment).
-func isAutoSalvageEnabled(ctx context.Context, c client.Reader, pod *corev1.Pod) bool {
+// the auto-salvage annotation. Owners of any kind are walked to any depth
+// (Pod → Job → CronJob, Pod → ReplicaSet → Rollout, ...).
+func isAutoSalvageEnabled(ctx context.Context, res *owners.Resolver, pod *corev1.Pod) bool {
 	if pod.Annotations[config.AnnotationPodAutoSalvage] == "true" {
 		return true
 	}
-	for _, ref := range pod.OwnerReferences {
-		key := types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}
-		switch ref.Kind {
-		case "ReplicaSet":
-			var rs appsv1.ReplicaSet
-			if err := c.Get(ctx, key, &rs); err != nil {
-				continue
-			}
-			if rs.Annotations[config.AnnotationPodAutoSalvage] == "true" {
-				return true
-			}
-			for _, parent := range rs.OwnerReferences {
-				if parent.Kind == "Deployment" {
-					var dep appsv1.Deployment
-					if err := c.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: parent.Name}, &dep); err != nil {
-						continue
-					}
-					if dep.Annotations[config.AnnotationPodAutoSalvage] == "true" {
-						return true
-					}
-				}
-			}
-		case "StatefulSet":
-			var ss appsv1.StatefulSet
-			if err := c.Get(ctx, key, &ss); err != nil {
-				continue
-			}
-			if ss.Annotations[config.AnnotationPodAutoSalvage] == "true" {
-				return true
-			}
-		case "DaemonSet":
-			var ds appsv1.DaemonSet
-			if err := c.Get(ctx, key, &ds); err != nil {
-				continue
-			}
-			if ds.Annotations[config.AnnotationPodAutoSalvage] == "true" {
-				return true
-			}
-		case "Job":
-			var job batchv1.Job
-			if err := c.Get(ctx, key, &job); err != nil {
-				continue
-			}
-			if job.Annotations[config.AnnotationPodAutoSalvage] == "true" {
-				return true
-			}
+	for _, owner := range res.Chain(ctx, pod) {
+		if owner.Annotations[config.AnnotationPodAutoSalvage] == "true" {
+			return true
 		}
 	}
 	return false
 }
 
+// recoverer returns r.Recoverer, or one using Config.Recovery by default.
+func (r *PodReconciler) recoverer() *recovery.Recoverer {
+	if r.Recoverer != nil {
+		return r.Recoverer
+	}
+	return recovery.New(r.Client, ownerResolver(r.Owners, r.Client), r.Emitter, r.Metrics, r.Config.Recovery)
+}
+
+// ownerResolver returns res, or an uncached resolver reading through c when
+// res is nil.
+func ownerResolver(res *owners.Resolver, c client.Reader) *owners.Resolver {
+	if res != nil {
+		return res
+	}
+	return owners.NewResolver(c, 0)
+}
+
 // hasSalvageRecord checks whether a completed SalvageRecord exists for the
 // given digest in the namespace. Uses a field index for efficient lookup.
 func hasSalvageRecord(ctx context.Context, c client.Reader, namespace, digest string) bool {
-	var list v1alpha1.SalvageRecordList
+	var list v1alpha2.SalvageRecordList
 	if err := c.List(ctx, &list, client.InNamespace(namespace), client.MatchingFields{"spec.digest": digest}); err != nil {
 		return false
 	}
 	for i := range list.Items {
-		if list.Items[i].Status.Phase == "Completed" {
+		if list.Items[i].Status.Phase == v1alpha2.PhaseCompleted {
 			return true
 		}
 	}
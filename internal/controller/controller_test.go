@@ -2,32 +2,42 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"google.golang.org/grpc"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	k8sevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/ppiankov/tote/api/v1"
 	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 	"github.com/ppiankov/tote/internal/agent"
 	"github.com/ppiankov/tote/internal/config"
 	"github.com/ppiankov/tote/internal/events"
 	"github.com/ppiankov/tote/internal/inventory"
 	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/notify"
+	"github.com/ppiankov/tote/internal/outage"
+	"github.com/ppiankov/tote/internal/owners"
 	"github.com/ppiankov/tote/internal/session"
 	"github.com/ppiankov/tote/internal/transfer"
 )
@@ -40,6 +50,7 @@ func newScheme() *runtime.Scheme {
 	_ = appsv1.AddToScheme(s)
 	_ = batchv1.AddToScheme(s)
 	_ = v1alpha1.AddToScheme(s)
+	_ = v1alpha2.AddToScheme(s)
 	return s
 }
 
@@ -99,9 +110,11 @@ type testFixture struct {
 func setupReconciler(objs ...runtime.Object) testFixture {
 	scheme := newScheme()
 	cb := fake.NewClientBuilder().WithScheme(scheme).
-		WithIndex(&v1alpha1.SalvageRecord{}, "spec.digest", func(obj client.Object) []string {
-			return []string{obj.(*v1alpha1.SalvageRecord).Spec.Digest}
-		})
+		WithIndex(&v1alpha2.SalvageRecord{}, "spec.digest", func(obj client.Object) []string {
+			return []string{obj.(*v1alpha2.SalvageRecord).Spec.Digest}
+		}).
+		WithIndex(&corev1.Pod{}, podControllerField, podControllerUID).
+		WithIndex(&corev1.Pod{}, podNodeField, podNodeName)
 	for _, obj := range objs {
 		cb = cb.WithRuntimeObjects(obj)
 	}
@@ -117,6 +130,7 @@ func setupReconciler(objs ...runtime.Object) testFixture {
 			Finder:  inventory.NewFinder(cl),
 			Emitter: events.NewEmitter(rec),
 			Metrics: metrics.NewCounters(reg),
+			Owners:  owners.NewResolver(cl, 0),
 		},
 		recorder: rec,
 	}
@@ -384,7 +398,9 @@ func TestReconcile_CorruptImage_WithAgent(t *testing.T) {
 		Kind:       "ReplicaSet",
 		Name:       "app-abc",
 		UID:        "test-uid",
+		Controller: ptr.To(true),
 	}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-abc", Namespace: "default", UID: "test-uid"}}
 
 	agentPod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -403,7 +419,7 @@ func TestReconcile_CorruptImage_WithAgent(t *testing.T) {
 		},
 	}
 
-	fixture := setupReconciler(optedInNamespace("default"), pod, agentPod)
+	fixture := setupReconciler(optedInNamespace("default"), pod, rs, agentPod)
 	fixture.reconciler.AgentResolver = transfer.NewResolver(fixture.reconciler.Client, "tote", port)
 
 	_, err = fixture.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app"))
@@ -435,6 +451,89 @@ func TestReconcile_CorruptImage_WithAgent(t *testing.T) {
 	}
 }
 
+func TestReconcile_CorruptImage_RepairedFromPeer(t *testing.T) {
+	image := "registry.example.com/app:v1"
+	store := agent.NewFakeImageStore()
+	store.AddTag(image, testDigest)
+	store.AddImage(testDigest, []byte("image-tar-data"))
+	store.AddBlob("sha256:layer", []byte("layer-data"))
+	store.SetMissingBlobs(testDigest, []string{"sha256:layer"})
+
+	sessions := session.NewStore()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	srv := grpc.NewServer()
+	v1.RegisterToteAgentServer(srv, agent.NewServer(store, sessions, port))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.GracefulStop()
+
+	pod := corruptImagePod("default", "app", image, "node-1")
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       "app-abc",
+		UID:        "test-uid",
+		Controller: ptr.To(true),
+	}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-abc", Namespace: "default", UID: "test-uid"}}
+
+	agentPodOn := func(node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tote-agent-" + node,
+				Namespace: "tote",
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "tote",
+					"app.kubernetes.io/component": "agent",
+				},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{PodIP: "127.0.0.1"},
+		}
+	}
+
+	fixture := setupReconciler(
+		optedInNamespace("default"), pod, rs,
+		agentPodOn("node-1"), agentPodOn("node-2"),
+		nodeWithImage("node-2", "registry.example.com/app@"+testDigest),
+	)
+	resolver := transfer.NewResolver(fixture.reconciler.Client, "tote", port)
+	fixture.reconciler.AgentResolver = resolver
+	fixture.reconciler.Orchestrator = transfer.NewOrchestrator(
+		sessions, resolver, fixture.reconciler.Emitter, fixture.reconciler.Metrics,
+		fixture.reconciler.Client, 2, 5*time.Minute, 0,
+	)
+
+	_, err = fixture.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case event := <-fixture.recorder.Events:
+		if !strings.Contains(event, events.ReasonRepaired) {
+			t.Errorf("expected repaired event, got: %s", event)
+		}
+	default:
+		t.Error("expected a repaired event")
+	}
+
+	// The image record must survive a successful repair.
+	digest, _ := store.ResolveTag(context.Background(), image)
+	if digest != testDigest {
+		t.Errorf("expected tag to be kept, ResolveTag returned %q", digest)
+	}
+
+	var got corev1.Pod
+	if err := fixture.reconciler.Client.Get(context.Background(), types.NamespacedName{Name: "app", Namespace: "default"}, &got); err == nil {
+		t.Error("expected pod to be deleted after repair")
+	}
+}
+
 func TestIsTransientError(t *testing.T) {
 	tests := []struct {
 		err       string
@@ -478,27 +577,100 @@ func TestReconcile_SalvageFailure_Requeues(t *testing.T) {
 	}
 }
 
+func TestReconcile_QueuesSalvage(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+	pod := failingPod("default", "app", image)
+	pod.Spec.NodeName = "node-target"
+	ns := optedInNamespace("default")
+	ns.Annotations[config.AnnotationNamespacePriority] = "5"
+
+	f := setupReconciler(ns, pod, nodeWithImage("node-source", "registry.example.com/app@"+testDigest))
+	f.reconciler.Orchestrator = transfer.NewOrchestrator(
+		session.NewStore(), transfer.NewResolver(f.reconciler.Client, "tote", 9090), f.reconciler.Emitter,
+		f.reconciler.Metrics, f.reconciler.Client, 2, 5*time.Minute, 0,
+	)
+	f.reconciler.Queue = transfer.NewQueue(f.reconciler.Orchestrator, f.reconciler.Metrics)
+
+	for range 2 {
+		result, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.RequeueAfter != 0 {
+			t.Errorf("expected no requeue once queued, got %v", result.RequeueAfter)
+		}
+	}
+	if n := f.reconciler.Queue.Len(); n != 1 {
+		t.Errorf("expected 1 queued salvage, got %d", n)
+	}
+}
+
+func TestNamespacePriority(t *testing.T) {
+	ns := optedInNamespace("default")
+	ns.Annotations[config.AnnotationNamespacePriority] = "7"
+	bad := optedInNamespace("bad")
+	bad.Annotations[config.AnnotationNamespacePriority] = "high"
+	f := setupReconciler(ns, bad, optedInNamespace("plain"))
+
+	for name, want := range map[string]int{"default": 7, "bad": 0, "plain": 0, "missing": 0} {
+		if got := namespacePriority(context.Background(), f.reconciler.Client, name); got != want {
+			t.Errorf("namespacePriority(%s) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestReconcile_RegistryOutageRaisesPriority(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+	var objs []runtime.Object
+	for _, name := range []string{"app-1", "app-2"} {
+		pod := failingPod("default", name, image)
+		pod.Status.ContainerStatuses[0].State.Waiting.Message = "dial tcp: lookup registry.example.com: no such host"
+		objs = append(objs, pod)
+	}
+	ns := optedInNamespace("default")
+	ns.Annotations[config.AnnotationNamespacePriority] = "5"
+	f := setupReconciler(append(objs, ns)...)
+	f.reconciler.Config.RegistryOutagePriority = 100
+	f.reconciler.Outages = outage.NewTracker(2, 10*time.Minute, f.reconciler.Emitter, f.reconciler.Metrics)
+	pod := objs[0].(*corev1.Pod)
+
+	if got := f.reconciler.salvagePriority(context.Background(), pod, image); got != 5 {
+		t.Errorf("expected the namespace priority before an outage, got %d", got)
+	}
+	for _, name := range []string{"app-1", "app-2"} {
+		if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", name)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !f.reconciler.Outages.Active("registry.example.com") {
+		t.Fatal("expected a registry outage after two unreachable pulls")
+	}
+	if got := f.reconciler.salvagePriority(context.Background(), pod, image); got != 105 {
+		t.Errorf("expected the outage to raise priority to 105, got %d", got)
+	}
+}
+
 func TestReconcile_AlreadySalvaged_Skips(t *testing.T) {
 	image := "registry.example.com/app@" + testDigest
 	pod := failingPod("default", "app", image)
 	pod.Spec.NodeName = "node-target"
 
 	// Create a completed SalvageRecord for this digest.
-	record := &v1alpha1.SalvageRecord{
+	record := &v1alpha2.SalvageRecord{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-e3b0c442",
 			Namespace: "default",
 		},
-		Spec: v1alpha1.SalvageRecordSpec{
+		Spec: v1alpha2.SalvageRecordSpec{
 			PodName:    "app",
 			Digest:     testDigest,
 			ImageRef:   image,
 			SourceNode: "node-source",
 			TargetNode: "node-target",
 		},
-		Status: v1alpha1.SalvageRecordStatus{
-			Phase:       "Completed",
-			CompletedAt: time.Now().UTC().Format(time.RFC3339),
+		Status: v1alpha2.SalvageRecordStatus{
+			Phase:       v1alpha2.PhaseCompleted,
+			CompletedAt: &metav1.Time{Time: time.Now()},
 		},
 	}
 
@@ -540,6 +712,7 @@ func TestReconcile_InheritAnnotationFromReplicaSet(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-rs",
 			Namespace: "default",
+			UID:       "rs-uid",
 			Annotations: map[string]string{
 				config.AnnotationPodAutoSalvage: "true",
 			},
@@ -577,6 +750,7 @@ func TestReconcile_InheritAnnotationFromDeployment(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-rs",
 			Namespace: "default",
+			UID:       "rs-uid",
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion: "apps/v1",
 				Kind:       "Deployment",
@@ -590,6 +764,7 @@ func TestReconcile_InheritAnnotationFromDeployment(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-deploy",
 			Namespace: "default",
+			UID:       "deploy-uid",
 			Annotations: map[string]string{
 				config.AnnotationPodAutoSalvage: "true",
 			},
@@ -627,6 +802,7 @@ func TestReconcile_NoAnnotationInOwnerChain(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-rs",
 			Namespace: "default",
+			UID:       "rs-uid",
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion: "apps/v1",
 				Kind:       "Deployment",
@@ -640,6 +816,7 @@ func TestReconcile_NoAnnotationInOwnerChain(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-deploy",
 			Namespace: "default",
+			UID:       "deploy-uid",
 		},
 	}
 
@@ -656,3 +833,221 @@ func TestReconcile_NoAnnotationInOwnerChain(t *testing.T) {
 	default:
 	}
 }
+
+func TestReconcile_InheritAnnotationFromAnyOwner(t *testing.T) {
+	optedIn := map[string]string{config.AnnotationPodAutoSalvage: "true"}
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name: "report-1", Namespace: "default", UID: "job-uid",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "report", UID: "cron-uid"}},
+	}}
+	cron := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{
+		Name: "report", Namespace: "default", UID: "cron-uid", Annotations: optedIn,
+	}}
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "app-rs", Namespace: "default", UID: "rs-uid",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "app", UID: "rollout-uid"}},
+	}}
+	rollout := &unstructured.Unstructured{}
+	rollout.SetAPIVersion("argoproj.io/v1alpha1")
+	rollout.SetKind("Rollout")
+	rollout.SetNamespace("default")
+	rollout.SetName("app")
+	rollout.SetUID("rollout-uid")
+	rollout.SetAnnotations(optedIn)
+
+	tests := []struct {
+		name  string
+		owner metav1.OwnerReference
+		objs  []runtime.Object
+	}{
+		{"cronjob via job", metav1.OwnerReference{APIVersion: "batch/v1", Kind: "Job", Name: "report-1", UID: "job-uid"}, []runtime.Object{job, cron}},
+		{"custom resource via replicaset", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app-rs", UID: "rs-uid"}, []runtime.Object{rs, rollout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := failingPod("default", "app", "nginx:latest")
+			delete(pod.Annotations, config.AnnotationPodAutoSalvage)
+			pod.OwnerReferences = []metav1.OwnerReference{tt.owner}
+
+			f := setupReconciler(append([]runtime.Object{optedInNamespace("default"), pod}, tt.objs...)...)
+			if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			select {
+			case <-f.recorder.Events:
+			default:
+				t.Errorf("expected event when annotation inherited from %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestReconcile_DetectedNotificationCarriesIncident(t *testing.T) {
+	var received notify.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	pod := failingPod("default", "app", "nginx:latest")
+	pod.UID = "pod-uid"
+	pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "app", Controller: ptr.To(true)}}
+	f := setupReconciler(optedInNamespace("default"), pod)
+	f.reconciler.Notifier = notify.NewNotifier(srv.URL, []string{notify.EventDetected})
+	f.reconciler.Notifier.Cluster = "prod-eu"
+
+	if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if received.Cluster != "prod-eu" || received.WorkloadKind != "StatefulSet" || received.WorkloadName != "app" {
+		t.Errorf("expected cluster and workload context, got %+v", received)
+	}
+	if received.Container != "app" || received.Reason != "ImagePullBackOff" || received.Message != "pull failed" {
+		t.Errorf("expected container failure details, got %+v", received)
+	}
+	if received.CorrelationID != notify.CorrelationID("pod-uid", "app") {
+		t.Errorf("unexpected correlation ID %q", received.CorrelationID)
+	}
+}
+
+// pullErrorPod returns a failing pod whose pull failed with reason and msg.
+func pullErrorPod(image, reason, msg string, secrets ...string) *corev1.Pod {
+	pod := failingPod("default", "app", image)
+	pod.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: reason, Message: msg}
+	for _, s := range secrets {
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: s})
+	}
+	return pod
+}
+
+func TestReconcile_ConfigErrors(t *testing.T) {
+	const denied = "unexpected status from HEAD request to https://registry.example.com/v2/app/manifests/v1: 401 Unauthorized"
+	tests := []struct {
+		name   string
+		pod    *corev1.Pod
+		objs   []runtime.Object
+		reason string
+		want   string
+		class  string
+	}{
+		{
+			name:   "invalid name",
+			pod:    pullErrorPod("Registry.Example.com/App:v1", "InvalidImageName", `Failed to apply default image tag "Registry.Example.com/App:v1": couldn't parse image name`),
+			reason: events.ReasonInvalidImageName,
+			want:   "couldn't parse image name",
+			class:  "invalid-name",
+		},
+		{
+			name:   "no pull secrets",
+			pod:    pullErrorPod("registry.example.com/app:v1", "ErrImagePull", denied),
+			reason: events.ReasonRegistryAuthFailed,
+			want:   "Registry registry.example.com denied the pull",
+			class:  "auth",
+		},
+		{
+			name:   "pull secret missing",
+			pod:    pullErrorPod("registry.example.com/app:v1", "ErrImagePull", denied, "regcred"),
+			reason: events.ReasonPullSecretMissing,
+			want:   "Pull secret regcred missing in namespace default",
+			class:  "auth",
+		},
+		{
+			name:   "credentials rejected",
+			pod:    pullErrorPod("registry.example.com/app:v1", "ErrImagePull", denied, "regcred"),
+			objs:   []runtime.Object{&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"}}},
+			reason: events.ReasonRegistryAuthFailed,
+			want:   "[regcred]",
+			class:  "auth",
+		},
+		{
+			name:   "not found",
+			pod:    pullErrorPod("registry.example.com/app@"+testDigest, "ErrImagePull", "registry.example.com/app: not found"),
+			reason: events.ReasonImageNotFound,
+			want:   "registry.example.com",
+			class:  "not-found",
+		},
+		{
+			name:   "docker hub missing repository",
+			pod:    pullErrorPod("docker.io/library/ngnix@"+testDigest, "ErrImagePull", "pull access denied, repository does not exist or may require authorization: server message: insufficient_scope: authorization failed"),
+			reason: events.ReasonImageNotFound,
+			want:   "docker.io",
+			class:  "not-found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupReconciler(append([]runtime.Object{optedInNamespace("default"), tt.pod}, tt.objs...)...)
+
+			if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case event := <-f.recorder.Events:
+				if !strings.Contains(event, tt.reason) || !strings.Contains(event, tt.want) {
+					t.Errorf("expected %s event containing %q, got %q", tt.reason, tt.want, event)
+				}
+			default:
+				t.Fatalf("expected a %s event", tt.reason)
+			}
+			if len(f.recorder.Events) != 0 {
+				t.Errorf("expected no further events, got %q", <-f.recorder.Events)
+			}
+			if v := testutil.ToFloat64(f.reconciler.Metrics.ConfigErrors.WithLabelValues(tt.class)); v != 1 {
+				t.Errorf("expected one %s config error, got %v", tt.class, v)
+			}
+		})
+	}
+}
+
+func TestReconcile_NeverPullSalvageable(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+	f := setupReconciler(
+		optedInNamespace("default"),
+		pullErrorPod(image, "ErrImageNeverPull", `Container image "`+image+`" is not present with pull policy of Never`),
+		nodeWithImage("node-1", image),
+	)
+
+	if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case event := <-f.recorder.Events:
+		if !strings.Contains(event, events.ReasonSalvageable) {
+			t.Errorf("expected salvageable event, got %q", event)
+		}
+	default:
+		t.Error("expected a salvageable event")
+	}
+}
+
+func TestReconcile_InitContainerFromCache(t *testing.T) {
+	// The pod as the informer cache holds it: containers reduced to name
+	// and image, statuses to their waiting state.
+	image := "registry.example.com/migrate@" + testDigest
+	pod := failingPod("default", "app", "registry.example.com/app:v1")
+	pod.Spec.InitContainers = []corev1.Container{{Name: "migrate", Image: image}}
+	pod.Status.InitContainerStatuses = pod.Status.ContainerStatuses
+	pod.Status.InitContainerStatuses[0].Name = "migrate"
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", State: corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+	}}}
+	f := setupReconciler(optedInNamespace("default"), pod, nodeWithImage("node-1", image))
+
+	if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case event := <-f.recorder.Events:
+		if !strings.Contains(event, events.ReasonSalvageable) || !strings.Contains(event, "migrate") {
+			t.Errorf("expected salvageable event for the init container image, got %q", event)
+		}
+	default:
+		t.Error("expected a salvageable event")
+	}
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/detector"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/transfer"
+)
+
+// CorruptScanPoller collects the findings of the agents' background content
+// scanners and warns pods whose image is corrupt on their node, before a
+// container restart trips over it.
+// It implements manager.Runnable and manager.LeaderElectionRunnable.
+type CorruptScanPoller struct {
+	Client   client.Client
+	Config   config.Config
+	Resolver *transfer.Resolver
+	Emitter  *events.Emitter
+	Interval time.Duration
+	Owners   *owners.Resolver // nil = uncached lookups through Client
+
+	// reported holds the node/digest/pod UID findings already warned
+	// about, so a finding that persists across polls produces one event per
+	// pod, not one per poll, while pods scheduled onto the node later are
+	// still warned.
+	reported map[string]bool
+}
+
+// NewCorruptScanPoller creates a poller that queries agents every interval.
+func NewCorruptScanPoller(c client.Client, cfg config.Config, resolver *transfer.Resolver, emitter *events.Emitter, interval time.Duration) *CorruptScanPoller {
+	return &CorruptScanPoller{
+		Client:   c,
+		Config:   cfg,
+		Resolver: resolver,
+		Emitter:  emitter,
+		Interval: interval,
+	}
+}
+
+// NeedLeaderElection returns true so only the leader emits events.
+func (p *CorruptScanPoller) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the poll loop until ctx is cancelled.
+func (p *CorruptScanPoller) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+func (p *CorruptScanPoller) poll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("corrupt-scan")
+	if !p.Config.Enabled {
+		return
+	}
+
+	byNode, err := p.Resolver.CorruptImagesByNode(ctx)
+	if err != nil {
+		logger.Error(err, "collecting agent scan results")
+		return
+	}
+	if len(byNode) == 0 {
+		p.reported = nil
+		return
+	}
+
+	var pods corev1.PodList
+	if err := p.Client.List(ctx, &pods); err != nil {
+		// Keep what was reported; the findings are retried next poll.
+		logger.Error(err, "listing pods")
+		return
+	}
+
+	// Findings that were repaired, or whose pod is gone, drop out, so a
+	// recurrence is reported again.
+	current := make(map[string]bool)
+	optedIn := make(map[string]bool)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		images := byNode[pod.Spec.NodeName]
+		if len(images) == 0 || p.Config.IsDenied(pod.Namespace) {
+			continue
+		}
+		for _, img := range images {
+			image, ok := podUsesDigest(pod, img.Digest)
+			if !ok {
+				continue
+			}
+			key := pod.Spec.NodeName + "/" + img.Digest + "/" + string(pod.UID)
+			if p.reported[key] {
+				current[key] = true
+				continue
+			}
+			allowed, seen := optedIn[pod.Namespace]
+			if !seen {
+				allowed = namespaceOptedIn(ctx, p.Client, pod.Namespace)
+				optedIn[pod.Namespace] = allowed
+			}
+			if !allowed || !isAutoSalvageEnabled(ctx, ownerResolver(p.Owners, p.Client), pod) {
+				break
+			}
+			logger.Info("pod runs corrupt image", "pod", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName, "digest", img.Digest)
+			p.Emitter.EmitCorruptContent(ctx, pod, image, pod.Spec.NodeName, len(img.MissingBlobs))
+			current[key] = true
+		}
+	}
+	p.reported = current
+}
+
+// podUsesDigest reports whether any container of the pod runs the image with
+// the given digest, returning the image reference to show in the event.
+// Running containers are matched by the image ID kubelet recorded; containers
+// that have not started yet only match when pinned by digest.
+func podUsesDigest(pod *corev1.Pod, digest string) (string, bool) {
+	matches := func(ref string) bool {
+		return ref == digest || strings.HasSuffix(ref, "@"+digest)
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses, pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, s := range statuses {
+			if matches(s.ImageID) {
+				return s.Image, true
+			}
+		}
+	}
+	for _, c := range detector.Containers(pod) {
+		if matches(c.Image) {
+			return c.Image, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	"github.com/ppiankov/tote/internal/agent"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/session"
+	"github.com/ppiankov/tote/internal/transfer"
+)
+
+// startScanningAgent serves an agent whose scanner has already run over store.
+func startScanningAgent(t *testing.T, store *agent.FakeImageStore) int {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	srv := agent.NewServer(store, session.NewStore(), port)
+	srv.Scanner = agent.NewScanner(store, time.Hour, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = srv.Scanner.Start(ctx) }()
+
+	grpcSrv := grpc.NewServer()
+	v1.RegisterToteAgentServer(grpcSrv, srv)
+	go func() { _ = grpcSrv.Serve(lis) }()
+	t.Cleanup(func() {
+		cancel()
+		grpcSrv.Stop()
+	})
+
+	// Wait for the initial scan to complete.
+	for i := 0; i < 100; i++ {
+		if _, at := srv.Scanner.Results(); !at.IsZero() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return port
+}
+
+func runningPod(ns, name, node, imageID string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   ns,
+			UID:         types.UID(name + "-uid"),
+			Annotations: map[string]string{config.AnnotationPodAutoSalvage: "true"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{{Name: "app", Image: "registry.example.com/app:v1"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "app",
+				Image:   "registry.example.com/app:v1",
+				ImageID: imageID,
+			}},
+		},
+	}
+}
+
+func TestCorruptScanPoller_WarnsPodsOnce(t *testing.T) {
+	store := agent.NewFakeImageStore()
+	store.AddImage(testDigest, []byte("data"))
+	store.SetMissingBlobs(testDigest, []string{"sha256:layer"})
+	port := startScanningAgent(t, store)
+
+	agentPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tote-agent-node-1",
+			Namespace: "tote",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":      "tote",
+				"app.kubernetes.io/component": "agent",
+			},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{PodIP: "127.0.0.1"},
+	}
+	fixture := setupReconciler(
+		optedInNamespace("default"), agentPod,
+		runningPod("default", "affected", "node-1", "registry.example.com/app@"+testDigest),
+		runningPod("default", "other-node", "node-2", "registry.example.com/app@"+testDigest),
+		runningPod("default", "other-image", "node-1", "registry.example.com/app@sha256:other"),
+	)
+	cl := fixture.reconciler.Client
+	poller := NewCorruptScanPoller(cl, config.New(), transfer.NewResolver(cl, "tote", port), fixture.reconciler.Emitter, time.Minute)
+
+	poller.poll(context.Background())
+	select {
+	case event := <-fixture.recorder.Events:
+		if !strings.Contains(event, events.ReasonCorruptContent) || !strings.Contains(event, "node-1") {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Fatal("expected a corrupt content event")
+	}
+	select {
+	case event := <-fixture.recorder.Events:
+		t.Errorf("expected only the affected pod to be warned, got: %s", event)
+	default:
+	}
+
+	// A finding that persists is not reported again.
+	poller.poll(context.Background())
+	select {
+	case event := <-fixture.recorder.Events:
+		t.Errorf("expected no repeat event, got: %s", event)
+	default:
+	}
+
+	// A pod scheduled onto the node later is warned too.
+	if err := cl.Create(context.Background(), runningPod("default", "late", "node-1", "registry.example.com/app@"+testDigest)); err != nil {
+		t.Fatal(err)
+	}
+	poller.poll(context.Background())
+	select {
+	case event := <-fixture.recorder.Events:
+		if !strings.Contains(event, events.ReasonCorruptContent) {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Fatal("expected the late pod to be warned")
+	}
+	select {
+	case event := <-fixture.recorder.Events:
+		t.Errorf("expected only the late pod to be warned, got: %s", event)
+	default:
+	}
+}
+
+func TestPodUsesDigest(t *testing.T) {
+	pod := runningPod("default", "app", "node-1", "docker.io/library/app@"+testDigest)
+	if image, ok := podUsesDigest(pod, testDigest); !ok || image != "registry.example.com/app:v1" {
+		t.Errorf("expected match on image ID, got %q %v", image, ok)
+	}
+	if _, ok := podUsesDigest(pod, "sha256:other"); ok {
+		t.Error("expected no match for other digest")
+	}
+
+	pinned := failingPod("default", "pinned", "registry.example.com/app@"+testDigest)
+	if _, ok := podUsesDigest(pinned, testDigest); !ok {
+		t.Error("expected match on digest-pinned spec image")
+	}
+}
@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/detector"
+	"github.com/ppiankov/tote/internal/inventory"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/registry"
+	"github.com/ppiankov/tote/internal/resolver"
+	"github.com/ppiankov/tote/internal/workload"
+)
+
+// riskSeverity orders risk levels; higher is worse.
+var riskSeverity = map[string]int{
+	v1alpha1.RiskLow:           0,
+	v1alpha1.RiskSingleSource:  1,
+	v1alpha1.RiskNoSource:      2,
+	v1alpha1.RiskNotActionable: 3,
+}
+
+// ImageRiskReporter periodically assesses the images of every opted-in
+// workload and keeps one ClusterImageRisk per workload up to date, so
+// unsalvageable images are visible before the registry fails.
+// It implements manager.Runnable and manager.LeaderElectionRunnable.
+type ImageRiskReporter struct {
+	Client   client.Client
+	Config   config.Config
+	Finder   *inventory.Finder
+	Interval time.Duration
+	Owners   *owners.Resolver // nil = uncached lookups through Client
+
+	// TagResolver checks that the source registry still serves an image.
+	// nil = availability is reported as Unknown.
+	TagResolver registry.TagResolver
+
+	// BackupResolver checks the backup registry for a pushed copy.
+	// nil or empty BackupRegistry = availability is reported as Unknown.
+	BackupResolver registry.TagResolver
+	BackupRegistry string
+}
+
+// NewImageRiskReporter creates a reporter that refreshes every interval.
+func NewImageRiskReporter(c client.Client, cfg config.Config, finder *inventory.Finder, interval time.Duration) *ImageRiskReporter {
+	return &ImageRiskReporter{
+		Client:   c,
+		Config:   cfg,
+		Finder:   finder,
+		Interval: interval,
+	}
+}
+
+// NeedLeaderElection returns true so only the leader writes reports.
+func (r *ImageRiskReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start reports once, then every Interval until ctx is cancelled.
+func (r *ImageRiskReporter) Start(ctx context.Context) error {
+	r.report(ctx)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.report(ctx)
+		}
+	}
+}
+
+type workloadKey struct {
+	namespace, kind, name string
+}
+
+func (r *ImageRiskReporter) report(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("image-risk")
+	if !r.Config.Enabled {
+		return
+	}
+
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods); err != nil {
+		logger.Error(err, "listing pods")
+		return
+	}
+
+	optedIn := make(map[string]bool)
+	workloads := make(map[workloadKey][]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if r.Config.IsDenied(pod.Namespace) {
+			continue
+		}
+		allowed, seen := optedIn[pod.Namespace]
+		if !seen {
+			allowed = namespaceOptedIn(ctx, r.Client, pod.Namespace)
+			optedIn[pod.Namespace] = allowed
+		}
+		if !allowed || !isAutoSalvageEnabled(ctx, ownerResolver(r.Owners, r.Client), pod) {
+			continue
+		}
+		kind, name := workload.KindName(ctx, ownerResolver(r.Owners, r.Client), pod)
+		key := workloadKey{pod.Namespace, kind, name}
+		workloads[key] = append(workloads[key], pod)
+	}
+
+	// Images are usually shared between workloads; assess each once per run.
+	assessed := make(map[string]v1alpha1.ImageRisk)
+	now := time.Now().UTC().Format(time.RFC3339)
+	current := make(map[string]bool, len(workloads))
+	for key, pods := range workloads {
+		status := v1alpha1.ClusterImageRiskStatus{Risk: v1alpha1.RiskLow, LastUpdated: now}
+		seen := make(map[string]bool)
+		for _, pod := range pods {
+			for _, c := range detector.Containers(pod) {
+				// Debug containers are not part of the workload.
+				if c.Kind == detector.KindEphemeral || seen[c.Name+"|"+c.Image] {
+					continue
+				}
+				seen[c.Name+"|"+c.Image] = true
+
+				risk, ok := assessed[c.Image]
+				if !ok {
+					risk = r.assess(ctx, c.Image)
+					assessed[c.Image] = risk
+				}
+				risk.Container = c.Name
+				status.Images = append(status.Images, risk)
+				if risk.Risk != v1alpha1.RiskLow {
+					status.AtRisk++
+				}
+				if riskSeverity[risk.Risk] > riskSeverity[status.Risk] {
+					status.Risk = risk.Risk
+				}
+			}
+		}
+		sort.Slice(status.Images, func(i, j int) bool {
+			if status.Images[i].Container != status.Images[j].Container {
+				return status.Images[i].Container < status.Images[j].Container
+			}
+			return status.Images[i].Image < status.Images[j].Image
+		})
+
+		name := riskName(key)
+		current[name] = true
+		if err := r.upsert(ctx, name, key, status); err != nil {
+			logger.Error(err, "writing image risk report", "namespace", key.namespace, "workload", key.kind+"/"+key.name)
+		}
+	}
+
+	// Remove reports for workloads that are gone or no longer opted in.
+	var existing v1alpha1.ClusterImageRiskList
+	if err := r.Client.List(ctx, &existing); err != nil {
+		logger.Error(err, "listing image risk reports")
+		return
+	}
+	for i := range existing.Items {
+		if current[existing.Items[i].Name] {
+			continue
+		}
+		if err := r.Client.Delete(ctx, &existing.Items[i]); client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "deleting stale image risk report", "name", existing.Items[i].Name)
+		}
+	}
+}
+
+// assess resolves the image the way the reconciler would on a pull failure
+// and checks which fallbacks exist for it.
+func (r *ImageRiskReporter) assess(ctx context.Context, image string) v1alpha1.ImageRisk {
+	res := resolver.Resolve(image)
+	risk := v1alpha1.ImageRisk{
+		Image:             image,
+		Pinned:            res.Actionable,
+		Digest:            res.Digest,
+		RegistryAvailable: v1alpha1.AvailabilityUnknown,
+		BackupAvailable:   v1alpha1.AvailabilityUnknown,
+	}
+
+	var registryDigest string
+	if r.TagResolver != nil {
+		registryDigest, risk.RegistryAvailable = availability(ctx, r.TagResolver, image)
+	}
+	if r.BackupResolver != nil && r.BackupRegistry != "" {
+		if ref, err := registry.BackupRef(image, r.BackupRegistry); err == nil {
+			_, risk.BackupAvailable = availability(ctx, r.BackupResolver, ref)
+		}
+	}
+
+	var nodes []string
+	if risk.Digest != "" {
+		nodes, _ = r.Finder.FindNodes(ctx, risk.Digest)
+	} else {
+		risk.Digest, nodes, _ = r.Finder.FindNodesByTag(ctx, image)
+		if risk.Digest == "" && registryDigest != "" {
+			risk.Digest = registryDigest
+			nodes, _ = r.Finder.FindNodes(ctx, registryDigest)
+		}
+	}
+	risk.CachedNodes = len(nodes)
+
+	switch {
+	case risk.Digest == "":
+		risk.Risk = v1alpha1.RiskNotActionable
+	case len(nodes) == 0:
+		risk.Risk = v1alpha1.RiskNoSource
+	case len(nodes) == 1:
+		risk.Risk = v1alpha1.RiskSingleSource
+	default:
+		risk.Risk = v1alpha1.RiskLow
+	}
+	return risk
+}
+
+// availability asks a registry for ref, returning the digest it serves and
+// Yes, No (not found) or Unknown (lookup failed).
+func availability(ctx context.Context, tr registry.TagResolver, ref string) (string, string) {
+	digest, err := tr.ResolveTag(ctx, ref)
+	switch {
+	case err != nil:
+		return "", v1alpha1.AvailabilityUnknown
+	case digest == "":
+		return "", v1alpha1.AvailabilityNo
+	}
+	return digest, v1alpha1.AvailabilityYes
+}
+
+// upsert creates or refreshes the report for one workload.
+func (r *ImageRiskReporter) upsert(ctx context.Context, name string, key workloadKey, status v1alpha1.ClusterImageRiskStatus) error {
+	var report v1alpha1.ClusterImageRisk
+	err := r.Client.Get(ctx, client.ObjectKey{Name: name}, &report)
+	if apierrors.IsNotFound(err) {
+		report = v1alpha1.ClusterImageRisk{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.ClusterImageRiskSpec{
+				Namespace:    key.namespace,
+				WorkloadKind: key.kind,
+				WorkloadName: key.name,
+			},
+		}
+		if err := r.Client.Create(ctx, &report); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	report.Status = status
+	return r.Client.Status().Update(ctx, &report)
+}
+
+// riskName is the cluster-unique report name for a workload,
+// e.g. "payments.deployment.api".
+func riskName(key workloadKey) string {
+	return strings.ToLower(key.namespace + "." + key.kind + "." + key.name)
+}
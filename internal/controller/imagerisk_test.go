@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/inventory"
+)
+
+type mapTagResolver map[string]string
+
+func (m mapTagResolver) ResolveTag(_ context.Context, ref string) (string, error) {
+	if ref == "unreachable" {
+		return "", errors.New("timeout")
+	}
+	return m[ref], nil
+}
+
+func TestImageRiskReporter(t *testing.T) {
+	cached := "sha256:" + strings.Repeat("a", 64)
+	uncached := "sha256:" + strings.Repeat("b", 64)
+	tagged := "sha256:" + strings.Repeat("c", 64)
+
+	pinned := "registry.example.com/pinned@" + cached
+	lost := "registry.example.com/lost@" + uncached
+	tag := "registry.example.com/app:v1"
+
+	nodeA := nodeWithImage("node-a", pinned)
+	nodeA.Status.Images = append(nodeA.Status.Images, corev1.ContainerImage{
+		Names: []string{tag, "registry.example.com/app@" + tagged},
+	})
+	nodeB := nodeWithImage("node-b", pinned)
+
+	web := failingPod("default", "web", pinned)
+	web.Spec.Containers = append(web.Spec.Containers,
+		corev1.Container{Name: "sidecar", Image: tag},
+		corev1.Container{Name: "cache", Image: lost},
+	)
+	adhoc := failingPod("default", "adhoc", "registry.example.com/unknown:latest")
+	ignored := failingPod("other", "ignored", lost)
+
+	stale := &v1alpha1.ClusterImageRisk{ObjectMeta: metav1.ObjectMeta{Name: "default.pod.gone"}}
+
+	cl := fake.NewClientBuilder().WithScheme(newScheme()).
+		WithStatusSubresource(&v1alpha1.ClusterImageRisk{}).
+		WithRuntimeObjects(optedInNamespace("default"), nodeA, nodeB, web, adhoc, ignored, stale).
+		Build()
+
+	cfg := config.New()
+	r := NewImageRiskReporter(cl, cfg, inventory.NewFinder(cl), 0)
+	r.TagResolver = mapTagResolver{pinned: cached, tag: tagged}
+	r.BackupResolver = mapTagResolver{"backup.example.com/pinned:latest": cached}
+	r.BackupRegistry = "backup.example.com"
+	r.report(context.Background())
+
+	var list v1alpha1.ClusterImageRiskList
+	if err := cl.List(context.Background(), &list); err != nil {
+		t.Fatal(err)
+	}
+	reports := make(map[string]v1alpha1.ClusterImageRisk)
+	for _, item := range list.Items {
+		reports[item.Name] = item
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected reports for web and adhoc only, got %v", reports)
+	}
+
+	webReport := reports["default.pod.web"]
+	if webReport.Spec.WorkloadKind != "Pod" || webReport.Spec.WorkloadName != "web" {
+		t.Errorf("unexpected spec %+v", webReport.Spec)
+	}
+	if webReport.Status.AtRisk != 2 || webReport.Status.Risk != v1alpha1.RiskNoSource {
+		t.Errorf("expected 2 images at risk, worst NoSource, got %d %s", webReport.Status.AtRisk, webReport.Status.Risk)
+	}
+	byContainer := make(map[string]v1alpha1.ImageRisk)
+	for _, img := range webReport.Status.Images {
+		byContainer[img.Container] = img
+	}
+	want := map[string]v1alpha1.ImageRisk{
+		"app": {Container: "app", Image: pinned, Digest: cached, Pinned: true, CachedNodes: 2,
+			RegistryAvailable: "Yes", BackupAvailable: "Yes", Risk: v1alpha1.RiskLow},
+		"sidecar": {Container: "sidecar", Image: tag, Digest: tagged, CachedNodes: 1,
+			RegistryAvailable: "Yes", BackupAvailable: "No", Risk: v1alpha1.RiskSingleSource},
+		"cache": {Container: "cache", Image: lost, Digest: uncached, Pinned: true,
+			RegistryAvailable: "No", BackupAvailable: "No", Risk: v1alpha1.RiskNoSource},
+	}
+	for name, w := range want {
+		if got := byContainer[name]; got != w {
+			t.Errorf("container %s: expected %+v, got %+v", name, w, got)
+		}
+	}
+
+	if got := reports["default.pod.adhoc"].Status; got.Risk != v1alpha1.RiskNotActionable || got.AtRisk != 1 {
+		t.Errorf("expected tag-only unresolved image to be NotActionable, got %+v", got)
+	}
+
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(stale), stale); err == nil {
+		t.Error("expected stale report to be deleted")
+	}
+}
+
+func TestImageRiskReporter_UnknownWithoutResolvers(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(newScheme()).Build()
+	r := NewImageRiskReporter(cl, config.New(), inventory.NewFinder(cl), 0)
+	risk := r.assess(context.Background(), "registry.example.com/app:v1")
+	if risk.RegistryAvailable != v1alpha1.AvailabilityUnknown || risk.BackupAvailable != v1alpha1.AvailabilityUnknown {
+		t.Errorf("expected Unknown availability, got %+v", risk)
+	}
+
+	r.TagResolver = mapTagResolver{}
+	if _, avail := availability(context.Background(), r.TagResolver, "unreachable"); avail != v1alpha1.AvailabilityUnknown {
+		t.Errorf("expected lookup errors to be Unknown, got %s", avail)
+	}
+}
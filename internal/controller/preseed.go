@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/placement"
+	"github.com/ppiankov/tote/internal/transfer"
+)
+
+// Pod field indexes, so pre-seeding reads one workload's pods and the pods
+// of a few nodes instead of every pod in the cache.
+const (
+	podControllerField = "metadata.controllerUID"
+	podNodeField       = "spec.nodeName"
+)
+
+// podControllerUID indexes a pod by the UID of its controller.
+func podControllerUID(obj client.Object) []string {
+	if ref := metav1.GetControllerOfNoCopy(obj); ref != nil {
+		return []string{string(ref.UID)}
+	}
+	return nil
+}
+
+// podNodeName indexes a pod by the node it is scheduled to.
+func podNodeName(obj client.Object) []string {
+	if node := obj.(*corev1.Pod).Spec.NodeName; node != "" {
+		return []string{node}
+	}
+	return nil
+}
+
+// preseed copies the image onto nodes that the pod's unscheduled sibling
+// replicas can schedule to, so they start without a registry pull. sources
+// are the nodes that already hold the image. At most
+// Config.PreseedMaxNodes nodes are seeded per call, picked by
+// placement.Rank as the ones the scheduler most likely chooses. It returns
+// transfer.ErrRateLimited when a transfer had to wait for a free slot.
+func (r *PodReconciler) preseed(ctx context.Context, pod *corev1.Pod, digest, image, kind string, sources []string) error {
+	logger := log.FromContext(ctx)
+
+	pending, err := unscheduledSiblings(ctx, r.Client, pod)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	var nodes corev1.NodeList
+	if err := r.Client.List(ctx, &nodes); err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+
+	// Replicas share a pod template, so one stands in for all of them.
+	sibling := pending[0]
+	var targets []string
+	for _, node := range placement.Nodes(sibling, nodes.Items) {
+		if !slices.Contains(sources, node) {
+			targets = append(targets, node)
+		}
+	}
+	replicas, pods, err := nodeLoad(ctx, r.Client, pod.Namespace, metav1.GetControllerOf(pod).UID, targets)
+	if err != nil {
+		return err
+	}
+	targets = placement.Rank(targets, nodes.Items, replicas, pods)
+	if limit := r.Config.PreseedMaxNodes; limit > 0 && len(targets) > limit {
+		targets = targets[:limit]
+	}
+	if len(targets) == 0 {
+		logger.V(1).Info("no node to pre-seed for unscheduled replicas", "digest", digest, "pending", len(pending))
+		return nil
+	}
+	logger.Info("pre-seeding image for unscheduled replicas", "digest", digest, "pending", len(pending), "nodes", targets)
+
+	sources = slices.Clone(sources)
+	var rateLimited bool
+	for _, target := range targets {
+		if r.Queue != nil {
+			r.Queue.Enqueue(ctx, &transfer.Job{
+				Pod:         sibling,
+				Digest:      digest,
+				ImageRef:    image,
+				SourceNodes: sources,
+				Priority:    r.salvagePriority(ctx, pod, image),
+				Kind:        kind,
+				Target:      target,
+			})
+			continue
+		}
+		err := r.Orchestrator.Preseed(ctx, sibling, digest, image, target, sources)
+		switch {
+		case errors.Is(err, transfer.ErrRateLimited):
+			rateLimited = true
+		case err != nil:
+			logger.Error(err, "pre-seed failed", "digest", digest, "node", target)
+		default:
+			// The node can now serve the remaining targets.
+			sources = append(sources, target)
+		}
+	}
+	if rateLimited {
+		return transfer.ErrRateLimited
+	}
+	return nil
+}
+
+// unscheduledSiblings returns the pending, not yet scheduled pods with the
+// same controller as pod, oldest first. Standalone pods have no siblings.
+func unscheduledSiblings(ctx context.Context, c client.Reader, pod *corev1.Pod) ([]*corev1.Pod, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, nil
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(pod.Namespace), client.MatchingFields{podControllerField: string(owner.UID)}); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	var out []*corev1.Pod
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.Spec.NodeName == "" && p.Status.Phase == corev1.PodPending && p.DeletionTimestamp == nil {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].CreationTimestamp.Before(&out[j].CreationTimestamp)
+	})
+	return out, nil
+}
+
+// nodeLoad returns the node of each scheduled pod in namespace controlled
+// by owner, and the number of running or pending pods on each of nodes.
+func nodeLoad(ctx context.Context, c client.Reader, namespace string, owner types.UID, nodes []string) ([]string, map[string]int, error) {
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(namespace), client.MatchingFields{podControllerField: string(owner)}); err != nil {
+		return nil, nil, fmt.Errorf("listing pods: %w", err)
+	}
+	var replicas []string
+	for i := range pods.Items {
+		if p := &pods.Items[i]; p.Spec.NodeName != "" && !terminated(p) {
+			replicas = append(replicas, p.Spec.NodeName)
+		}
+	}
+
+	counts := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		var pods corev1.PodList
+		if err := c.List(ctx, &pods, client.MatchingFields{podNodeField: node}); err != nil {
+			return nil, nil, fmt.Errorf("listing pods on node %s: %w", node, err)
+		}
+		for i := range pods.Items {
+			if !terminated(&pods.Items[i]) {
+				counts[node]++
+			}
+		}
+	}
+	return replicas, counts, nil
+}
+
+// terminated reports whether the pod has finished and no longer uses its
+// node's resources.
+func terminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/ppiankov/tote/internal/session"
+	"github.com/ppiankov/tote/internal/transfer"
+)
+
+func readyNode(name, pool string, images ...string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	for _, image := range images {
+		node.Status.Images = append(node.Status.Images, corev1.ContainerImage{Names: []string{image}})
+	}
+	return node
+}
+
+func replica(pod *corev1.Pod, owner types.UID) *corev1.Pod {
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app-" + string(owner), UID: owner, Controller: ptr.To(true),
+	}}
+	pod.Spec.NodeSelector = map[string]string{"pool": "web"}
+	return pod
+}
+
+func pendingReplica(name string, owner types.UID) *corev1.Pod {
+	return replica(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}, owner)
+}
+
+func TestReconcile_PreseedsUnscheduledReplicas(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		maxNodes int
+		want     int
+	}{
+		{"disabled", false, 5, 1},
+		{"enabled", true, 5, 3},
+		{"capped", true, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := replica(failingPod("default", "app-1", image), "rs")
+			failing.Spec.NodeName = "node-x"
+			cordoned := readyNode("node-cordoned", "web")
+			cordoned.Spec.Unschedulable = true
+
+			f := setupReconciler([]runtime.Object{
+				optedInNamespace("default"),
+				failing,
+				pendingReplica("app-2", "rs"),
+				pendingReplica("app-3", "rs"),
+				pendingReplica("other", "other-rs"),
+				readyNode("node-source", "web", image),
+				readyNode("node-web-1", "web"),
+				readyNode("node-web-2", "web"),
+				readyNode("node-db", "db"),
+				cordoned,
+			}...)
+			f.reconciler.Config.PreseedUnscheduled = tt.enabled
+			f.reconciler.Config.PreseedMaxNodes = tt.maxNodes
+			f.reconciler.Orchestrator = transfer.NewOrchestrator(
+				session.NewStore(), transfer.NewResolver(f.reconciler.Client, "tote", 9090), f.reconciler.Emitter,
+				f.reconciler.Metrics, f.reconciler.Client, 2, 5*time.Minute, 0,
+			)
+			f.reconciler.Queue = transfer.NewQueue(f.reconciler.Orchestrator, f.reconciler.Metrics)
+
+			if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app-1")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// The salvage onto node-x plus one job per pre-seeded node.
+			if n := f.reconciler.Queue.Len(); n != tt.want {
+				t.Errorf("expected %d queued jobs, got %d", tt.want, n)
+			}
+		})
+	}
+}
+
+func TestUnscheduledSiblings(t *testing.T) {
+	pod := replica(failingPod("default", "app-1", "app:v1"), "rs")
+	pod.Spec.NodeName = "node-x"
+	scheduled := pendingReplica("app-scheduled", "rs")
+	scheduled.Spec.NodeName = "node-y"
+	older := pendingReplica("app-older", "rs")
+	older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := pendingReplica("app-newer", "rs")
+	newer.CreationTimestamp = metav1.NewTime(time.Now())
+
+	f := setupReconciler(pod, scheduled, newer, older, pendingReplica("other", "other-rs"))
+	got, err := unscheduledSiblings(context.Background(), f.reconciler.Client, pod)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "app-older" || got[1].Name != "app-newer" {
+		names := make([]string, len(got))
+		for i, p := range got {
+			names[i] = p.Name
+		}
+		t.Errorf("expected [app-older app-newer], got %v", names)
+	}
+
+	standalone := failingPod("default", "bare", "app:v1")
+	if got, _ := unscheduledSiblings(context.Background(), f.reconciler.Client, standalone); len(got) != 0 {
+		t.Errorf("expected no siblings for a standalone pod, got %d", len(got))
+	}
+}
+
+func TestNodeLoad(t *testing.T) {
+	running := func(name, node string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	f := setupReconciler(
+		replica(running("app-1", "node-a", corev1.PodRunning), "rs"),
+		replica(running("app-2", "node-b", corev1.PodRunning), "rs"),
+		replica(running("app-done", "node-c", corev1.PodSucceeded), "rs"),
+		pendingReplica("app-pending", "rs"),
+		running("other", "node-a", corev1.PodRunning),
+		running("failed", "node-b", corev1.PodFailed),
+		running("elsewhere", "node-d", corev1.PodRunning),
+	)
+	system := running("dns", "node-b", corev1.PodRunning)
+	system.Namespace = "kube-system"
+	if err := f.reconciler.Client.Create(context.Background(), system); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas, pods, err := nodeLoad(context.Background(), f.reconciler.Client, "default", "rs", []string{"node-a", "node-b", "node-c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(replicas)
+	if want := []string{"node-a", "node-b"}; !slices.Equal(replicas, want) {
+		t.Errorf("expected replicas on %v, got %v", want, replicas)
+	}
+	if pods["node-a"] != 2 || pods["node-b"] != 2 || pods["node-c"] != 0 || pods["node-d"] != 0 {
+		t.Errorf("unexpected pod counts %v", pods)
+	}
+}
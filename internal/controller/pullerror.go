@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/detector"
+	"github.com/ppiankov/tote/internal/resolver"
+)
+
+// handleConfigError reports a pull failure caused by the pod's configuration
+// and returns true when salvaging cannot fix it, so the reconciler skips the
+// node search.
+func (r *PodReconciler) handleConfigError(ctx context.Context, pod *corev1.Pod, f detector.Failure) bool {
+	if !f.Class.Futile() {
+		return false
+	}
+	logger := log.FromContext(ctx)
+	r.Metrics.RecordConfigError(string(f.Class))
+
+	if f.Class == detector.ClassInvalidName {
+		logger.Info("invalid image name, not salvaging", "container", f.ContainerName, "image", f.Image)
+		r.Emitter.EmitInvalidImageName(ctx, pod, f.Image, f.Message)
+		return true
+	}
+
+	registry := resolver.Registry(f.Image)
+	var names []string
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		exists, err := r.secretExists(ctx, pod.Namespace, ref.Name)
+		if err != nil {
+			logger.Error(err, "failed to look up pull secret", "secret", ref.Name)
+		} else if !exists {
+			logger.Info("pull secret missing, not salvaging", "container", f.ContainerName, "image", f.Image, "secret", ref.Name)
+			r.Emitter.EmitPullSecretMissing(ctx, pod, f.Image, registry, ref.Name)
+			return true
+		}
+		names = append(names, ref.Name)
+	}
+	logger.Info("registry denied the pull, not salvaging", "container", f.ContainerName, "image", f.Image, "secrets", names)
+	r.Emitter.EmitRegistryAuthFailed(ctx, pod, f.Image, registry, names)
+	return true
+}
+
+// secretExists reports whether the secret exists, reading only its metadata
+// through the uncached reader so no informer watches every secret.
+func (r *PodReconciler) secretExists(ctx context.Context, namespace, name string) (bool, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	secret := &metav1.PartialObjectMetadata{}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// emitImageNotFound reports an image the registry does not have and no node
+// caches.
+func (r *PodReconciler) emitImageNotFound(ctx context.Context, pod *corev1.Pod, f detector.Failure) {
+	log.FromContext(ctx).Info("image not found in registry or any node cache", "container", f.ContainerName, "image", f.Image)
+	r.Metrics.RecordConfigError(string(f.Class))
+	r.Emitter.EmitImageNotFound(ctx, pod, f.Image, resolver.Registry(f.Image))
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/placement"
+	"github.com/ppiankov/tote/internal/recovery"
+	"github.com/ppiankov/tote/internal/workload"
+)
+
+// salvageStrategy returns the tote.dev/salvage-strategy of the pod, else of
+// its workload, else of its namespace, else Config.SalvageStrategy.
+// Reschedule needs the pod webhook to steer the replacement; without it the
+// strategy is transfer.
+func (r *PodReconciler) salvageStrategy(ctx context.Context, pod *corev1.Pod) string {
+	s := workload.Setting(ctx, r.Client, ownerResolver(r.Owners, r.Client), pod, config.AnnotationSalvageStrategy, config.ValidateSalvageStrategy)
+	if s == "" {
+		s = r.Config.SalvageStrategy
+	}
+	if s == config.StrategyReschedule && !r.Config.PodWebhook {
+		log.FromContext(ctx).V(1).Info("reschedule strategy needs the pod webhook, transferring instead")
+		return config.StrategyTransfer
+	}
+	if s == "" {
+		return config.StrategyTransfer
+	}
+	return s
+}
+
+// reschedule deletes a failing pod so its controller recreates it, after
+// recording on the workload which nodes cache the image and can run the pod.
+// The pod webhook steers the replacement onto those nodes. It returns false,
+// leaving the pod to a transfer, when the pod is standalone or a DaemonSet
+// pod, when its controller would not recreate it, when its recovery strategy
+// is wait or restart, when it is itself a replacement that did not land on a steered node,
+// or when no other eligible node caches the image.
+func (r *PodReconciler) reschedule(ctx context.Context, pod *corev1.Pod, image, digest string, nodes []string) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if pod.Annotations[config.AnnotationRescheduled] == digest {
+		logger.V(1).Info("pod was already rescheduled for this image, transferring instead", "digest", digest, "node", pod.Spec.NodeName)
+		return false, nil
+	}
+	// The pending reschedule is written by tote itself, so read it fresh.
+	res := ownerResolver(r.Owners, r.Client)
+	owner := workload.Owner(ctx, res.Uncached(), pod)
+	if owner == nil || workload.Kind(owner) == "DaemonSet" {
+		return false, nil
+	}
+	if !res.Recreates(ctx, pod) {
+		return false, nil
+	}
+	// Only the delete and evict recovery strategies allow removing the pod.
+	strategy := r.recoverer().Strategy(ctx, pod)
+	if strategy != config.RecoveryDelete && strategy != config.RecoveryEvict {
+		logger.V(1).Info("recovery strategy does not allow removing the pod, transferring instead", "recovery", strategy)
+		return false, nil
+	}
+	// Without the webhook, or when the scheduler ignored the preference, the
+	// replacement lands on a node without the image; do not delete it again.
+	// Creation timestamps only have second precision.
+	if prev, ok := workload.PendingReschedule(owner, time.Now()); ok && prev.Digest == digest && !pod.CreationTimestamp.Time.Before(prev.Time.Truncate(time.Second)) {
+		logger.V(1).Info("replacement pod missed the nodes caching the image, transferring instead", "digest", digest, "node", pod.Spec.NodeName)
+		return false, nil
+	}
+
+	eligible, err := eligibleNodes(ctx, r.Client, pod, nodes)
+	if err != nil {
+		return false, err
+	}
+	if len(eligible) == 0 {
+		logger.V(1).Info("no other eligible node caches the image, transferring instead", "digest", digest)
+		return false, nil
+	}
+
+	now := time.Now()
+	hint := workload.Reschedule{Time: now, Pod: pod.Name, Digest: digest, Nodes: eligible, Expires: now.Add(config.DefaultRescheduleTTL)}
+	if err := workload.RecordReschedule(ctx, r.Client, owner, hint); err != nil {
+		r.Metrics.RecordReschedule("failed")
+		return false, err
+	}
+	if strategy == config.RecoveryEvict {
+		err := recovery.Evict(ctx, r.Client, pod)
+		if apierrors.IsTooManyRequests(err) {
+			logger.Info("eviction blocked by a PodDisruptionBudget, transferring instead", "digest", digest)
+			r.Metrics.RecordReschedule("blocked")
+			return false, nil
+		}
+		if err != nil {
+			r.Metrics.RecordReschedule("failed")
+			return false, err
+		}
+	} else if err := r.Client.Delete(ctx, pod); err != nil {
+		r.Metrics.RecordReschedule("failed")
+		return false, fmt.Errorf("deleting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	r.Metrics.RecordReschedule("success")
+	r.Emitter.EmitRescheduled(ctx, pod, image, eligible)
+	logger.Info("rescheduled pod onto nodes caching the image", "digest", digest, "node", pod.Spec.NodeName, "nodes", eligible)
+	return true, nil
+}
+
+// eligibleNodes returns the nodes among nodes, other than the pod's own,
+// that the pod can be scheduled to.
+func eligibleNodes(ctx context.Context, c client.Reader, pod *corev1.Pod, nodes []string) ([]string, error) {
+	var out []string
+	for _, name := range nodes {
+		if name == pod.Spec.NodeName || slices.Contains(out, name) {
+			continue
+		}
+		var node corev1.Node
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, &node); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("getting node %s: %w", name, err)
+			}
+			continue
+		}
+		if placement.Fits(pod, &node) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
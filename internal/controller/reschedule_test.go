@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/workload"
+)
+
+func rescheduleNamespace() *corev1.Namespace {
+	ns := optedInNamespace("default")
+	ns.Annotations[config.AnnotationSalvageStrategy] = config.StrategyReschedule
+	return ns
+}
+
+// deploymentPod returns a failing pod on node-x owned by Deployment app
+// through ReplicaSet app-rs.
+func deploymentPod(image string) (*corev1.Pod, *appsv1.ReplicaSet, *appsv1.Deployment) {
+	pod := failingPod("default", "app-1", image)
+	pod.Spec.NodeName = "node-x"
+	pod.Spec.NodeSelector = map[string]string{"pool": "web"}
+	pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app-rs", UID: "rs-uid", Controller: ptr.To(true)}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "app-rs", Namespace: "default", UID: "rs-uid",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", UID: "app-uid", Controller: ptr.To(true)}},
+	}}
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid"}}
+	return pod, rs, dep
+}
+
+func TestReconcile_Reschedules(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+	pod, rs, dep := deploymentPod(image)
+	f := setupReconciler(
+		rescheduleNamespace(), pod, rs, dep,
+		readyNode("node-x", "web"),
+		readyNode("node-a", "web", image),
+		readyNode("node-db", "db", image),
+	)
+	f.reconciler.Config.PodWebhook = true
+
+	if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app-1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := f.reconciler.Client.Get(ctx, client.ObjectKeyFromObject(pod), &corev1.Pod{}); err == nil {
+		t.Error("expected the failing pod to be deleted")
+	}
+	if err := f.reconciler.Client.Get(ctx, client.ObjectKeyFromObject(dep), dep); err != nil {
+		t.Fatal(err)
+	}
+	var hint workload.Reschedule
+	if err := json.Unmarshal([]byte(dep.Annotations[config.AnnotationReschedule]), &hint); err != nil {
+		t.Fatalf("decoding %s: %v", config.AnnotationReschedule, err)
+	}
+	// node-db caches the image but does not match the pod's nodeSelector.
+	if hint.Digest != testDigest || len(hint.Nodes) != 1 || hint.Nodes[0] != "node-a" {
+		t.Errorf("unexpected reschedule hint %+v", hint)
+	}
+	if n := testutil.ToFloat64(f.reconciler.Metrics.Reschedules.WithLabelValues("success")); n != 1 {
+		t.Errorf("expected 1 reschedule, got %v", n)
+	}
+}
+
+func TestReconcile_RescheduleFallsBackToTransfer(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+	hint, _ := json.Marshal(workload.Reschedule{
+		Time: time.Now().Add(-time.Hour), Digest: testDigest, Nodes: []string{"node-a"}, Expires: time.Now().Add(time.Hour),
+	})
+
+	tests := []struct {
+		name  string
+		setup func(ns *corev1.Namespace, pod *corev1.Pod, dep *appsv1.Deployment) []runtime.Object
+	}{
+		{"transfer strategy", func(ns *corev1.Namespace, _ *corev1.Pod, _ *appsv1.Deployment) []runtime.Object {
+			ns.Annotations[config.AnnotationSalvageStrategy] = config.StrategyTransfer
+			return nil
+		}},
+		{"pod overrides namespace", func(_ *corev1.Namespace, pod *corev1.Pod, _ *appsv1.Deployment) []runtime.Object {
+			pod.Annotations[config.AnnotationSalvageStrategy] = config.StrategyTransfer
+			return nil
+		}},
+		{"already steered", func(_ *corev1.Namespace, pod *corev1.Pod, _ *appsv1.Deployment) []runtime.Object {
+			pod.Annotations[config.AnnotationRescheduled] = testDigest
+			return nil
+		}},
+		{"replacement missed", func(_ *corev1.Namespace, pod *corev1.Pod, dep *appsv1.Deployment) []runtime.Object {
+			pod.CreationTimestamp = metav1.Now()
+			dep.Annotations = map[string]string{config.AnnotationReschedule: string(hint)}
+			return nil
+		}},
+		{"replacement created in the same second", func(_ *corev1.Namespace, pod *corev1.Pod, dep *appsv1.Deployment) []runtime.Object {
+			now := time.Now()
+			recent, _ := json.Marshal(workload.Reschedule{
+				Time: now, Digest: testDigest, Nodes: []string{"node-a"}, Expires: now.Add(time.Hour),
+			})
+			pod.CreationTimestamp = metav1.NewTime(now.Truncate(time.Second))
+			dep.Annotations = map[string]string{config.AnnotationReschedule: string(recent)}
+			return nil
+		}},
+		{"wait recovery", func(_ *corev1.Namespace, _ *corev1.Pod, dep *appsv1.Deployment) []runtime.Object {
+			dep.Annotations = map[string]string{config.AnnotationRecovery: config.RecoveryWait}
+			return nil
+		}},
+		{"no eligible node", func(_ *corev1.Namespace, _ *corev1.Pod, _ *appsv1.Deployment) []runtime.Object {
+			return []runtime.Object{readyNode("node-db", "db", image)}
+		}},
+		{"daemonset pod", func(_ *corev1.Namespace, pod *corev1.Pod, _ *appsv1.Deployment) []runtime.Object {
+			pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", Controller: ptr.To(true)}}
+			return []runtime.Object{
+				&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"}},
+				readyNode("node-a", "web", image),
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := rescheduleNamespace()
+			pod, rs, dep := deploymentPod(image)
+			objs := []runtime.Object{ns, pod, rs, dep, readyNode("node-x", "web")}
+			if extra := tt.setup(ns, pod, dep); extra != nil {
+				objs = append(objs, extra...)
+			} else {
+				objs = append(objs, readyNode("node-a", "web", image))
+			}
+			f := setupReconciler(objs...)
+			f.reconciler.Config.PodWebhook = true
+
+			if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app-1")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := f.reconciler.Client.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+				t.Errorf("expected the pod to be kept for a transfer, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcile_EphemeralContainerNotRescheduled(t *testing.T) {
+	image := "registry.example.com/debug@" + testDigest
+	pod, rs, dep := deploymentPod("registry.example.com/app:v1")
+	pod.Status.ContainerStatuses = nil
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: image},
+	}}
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "debugger",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+	}}
+	f := setupReconciler(
+		rescheduleNamespace(), pod, rs, dep,
+		readyNode("node-x", "web"),
+		readyNode("node-a", "web", image),
+	)
+	f.reconciler.Config.PodWebhook = true
+
+	if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app-1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.reconciler.Client.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+		t.Errorf("expected the pod running a debug session to be kept, got %v", err)
+	}
+	if n := testutil.ToFloat64(f.reconciler.Metrics.Reschedules.WithLabelValues("success")); n != 0 {
+		t.Errorf("expected no reschedule, got %v", n)
+	}
+}
+
+func TestReconcile_RescheduleNeedsPodWebhook(t *testing.T) {
+	image := "registry.example.com/app@" + testDigest
+	pod, rs, dep := deploymentPod(image)
+	f := setupReconciler(
+		rescheduleNamespace(), pod, rs, dep,
+		readyNode("node-x", "web"),
+		readyNode("node-a", "web", image),
+	)
+
+	if _, err := f.reconciler.Reconcile(context.Background(), reconcileRequest("default", "app-1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f.reconciler.Client.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+		t.Errorf("expected the pod to be kept for a transfer without the pod webhook, got %v", err)
+	}
+}
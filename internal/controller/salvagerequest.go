@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/inventory"
+	"github.com/ppiankov/tote/internal/resolver"
+	"github.com/ppiankov/tote/internal/transfer"
+)
+
+// SalvageRequestReconciler carries out SalvageRequests: it copies the image
+// to every target node once per spec generation and reports the outcome in
+// status, so transfers can be requested declaratively and audited.
+type SalvageRequestReconciler struct {
+	Client       client.Client
+	Config       config.Config
+	Finder       *inventory.Finder
+	Orchestrator *transfer.Orchestrator
+
+	// Namespace is the only namespace requests are carried out in. A
+	// request can copy any image cached anywhere in the cluster onto any
+	// node and push it to the backup registry, so creating one must be as
+	// privileged as administering tote. Requests elsewhere fail.
+	Namespace string
+}
+
+// Reconcile handles a single SalvageRequest.
+func (r *SalvageRequestReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.Config.Enabled {
+		return reconcile.Result{}, nil
+	}
+
+	var sr v1alpha1.SalvageRequest
+	if err := r.Client.Get(ctx, req.NamespacedName, &sr); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if sr.Status.ObservedGeneration == sr.Generation &&
+		(sr.Status.Phase == v1alpha1.RequestCompleted || sr.Status.Phase == v1alpha1.RequestFailed) {
+		return reconcile.Result{}, nil
+	}
+	if sr.Status.ObservedGeneration != sr.Generation {
+		// A new spec starts over.
+		sr.Status = v1alpha1.SalvageRequestStatus{Conditions: sr.Status.Conditions}
+		sr.Status.ObservedGeneration = sr.Generation
+	}
+	sr.Status.Phase = v1alpha1.RequestRunning
+
+	if sr.Namespace != r.Namespace {
+		logger.Info("ignoring salvage request outside the controller namespace", "request", req.NamespacedName)
+		return reconcile.Result{}, r.finish(ctx, &sr, "NamespaceNotAllowed",
+			fmt.Sprintf("SalvageRequests are only carried out in namespace %s", r.Namespace))
+	}
+
+	digest, sources, err := r.resolve(ctx, &sr)
+	if err != nil {
+		return reconcile.Result{}, r.finish(ctx, &sr, "Unresolvable", err.Error())
+	}
+	sr.Status.Digest = digest
+
+	targets, err := r.targets(ctx, &sr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(targets) == 0 {
+		return reconcile.Result{}, r.finish(ctx, &sr, "NoTargets", "no nodes match targetNodes or nodeSelector")
+	}
+
+	for _, target := range targets {
+		if slices.ContainsFunc(sr.Status.Targets, func(t v1alpha1.TargetStatus) bool { return t.Node == target }) {
+			continue
+		}
+		status := v1alpha1.TargetStatus{Node: target, Phase: v1alpha1.RequestCompleted}
+		if !slices.Contains(sources, target) {
+			var from []string
+			for _, n := range sources {
+				if n != target {
+					from = append(from, n)
+				}
+			}
+			result, err := r.Orchestrator.Transfer(ctx, digest, sr.Spec.ImageRef, target, from)
+			switch {
+			case errors.Is(err, transfer.ErrRateLimited):
+				// Keep the progress so far and come back for the rest.
+				if err := r.Client.Status().Update(ctx, &sr); err != nil {
+					return reconcile.Result{}, err
+				}
+				return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+			case err != nil:
+				logger.Error(err, "salvage request transfer failed", "request", req.Name, "node", target)
+				status.Phase = v1alpha1.RequestFailed
+				status.Error = err.Error()
+			default:
+				status.SourceNode = result.SourceNode
+				// The target can now serve the remaining targets.
+				sources = append(sources, target)
+			}
+		}
+		sr.Status.Targets = append(sr.Status.Targets, status)
+	}
+
+	failed := 0
+	for _, t := range sr.Status.Targets {
+		if t.Phase == v1alpha1.RequestFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return reconcile.Result{}, r.finish(ctx, &sr, "TargetsFailed",
+			fmt.Sprintf("%d of %d target nodes failed", failed, len(sr.Status.Targets)))
+	}
+
+	if sr.Spec.PushToBackup && sr.Status.BackupRef == "" {
+		ref, err := r.Orchestrator.PushBackup(ctx, digest, sr.Spec.ImageRef, sources[0])
+		if err != nil {
+			return reconcile.Result{}, r.finish(ctx, &sr, "BackupPushFailed", fmt.Sprintf("pushing to backup registry: %v", err))
+		}
+		sr.Status.BackupRef = ref
+	}
+
+	logger.Info("salvage request complete", "request", req.Name, "digest", digest, "targets", len(targets))
+	return reconcile.Result{}, r.finish(ctx, &sr, "AllTargetsCompleted",
+		fmt.Sprintf("image is on %d target nodes", len(sr.Status.Targets)))
+}
+
+// resolve returns the digest to transfer and the nodes that can serve it.
+func (r *SalvageRequestReconciler) resolve(ctx context.Context, sr *v1alpha1.SalvageRequest) (string, []string, error) {
+	digest := sr.Spec.Digest
+	if digest == "" {
+		digest = resolver.Resolve(sr.Spec.ImageRef).Digest
+	}
+
+	var nodes []string
+	if digest == "" {
+		var err error
+		digest, nodes, err = r.Finder.FindNodesByTag(ctx, sr.Spec.ImageRef)
+		if err != nil {
+			return "", nil, fmt.Errorf("resolving %s: %w", sr.Spec.ImageRef, err)
+		}
+		if digest == "" && r.Orchestrator.Resolver != nil {
+			var node string
+			digest, node, _ = r.Orchestrator.Resolver.ResolveTagViaAgents(ctx, sr.Spec.ImageRef)
+			if node != "" {
+				nodes = []string{node}
+			}
+		}
+		if digest == "" {
+			return "", nil, fmt.Errorf("no node has %s cached; set spec.digest", sr.Spec.ImageRef)
+		}
+	} else {
+		var err error
+		if nodes, err = r.Finder.FindNodes(ctx, digest); err != nil {
+			return "", nil, fmt.Errorf("finding nodes with %s: %w", digest, err)
+		}
+	}
+
+	if sr.Spec.SourceNode != "" {
+		nodes = []string{sr.Spec.SourceNode}
+	}
+	return digest, nodes, nil
+}
+
+// targets returns the sorted union of spec.targetNodes and the nodes
+// matching spec.nodeSelector.
+func (r *SalvageRequestReconciler) targets(ctx context.Context, sr *v1alpha1.SalvageRequest) ([]string, error) {
+	set := make(map[string]bool)
+	for _, n := range sr.Spec.TargetNodes {
+		set[n] = true
+	}
+	if len(sr.Spec.NodeSelector) > 0 {
+		var nodes corev1.NodeList
+		if err := r.Client.List(ctx, &nodes, client.MatchingLabels(sr.Spec.NodeSelector)); err != nil {
+			return nil, fmt.Errorf("listing nodes: %w", err)
+		}
+		for _, n := range nodes.Items {
+			set[n.Name] = true
+		}
+	}
+	out := make([]string, 0, len(set))
+	for n := range set {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// finish records the final phase and Complete condition.
+func (r *SalvageRequestReconciler) finish(ctx context.Context, sr *v1alpha1.SalvageRequest, reason, message string) error {
+	cond := metav1.Condition{
+		Type:               v1alpha1.ConditionComplete,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: sr.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	sr.Status.Phase = v1alpha1.RequestFailed
+	if reason == "AllTargetsCompleted" {
+		cond.Status = metav1.ConditionTrue
+		sr.Status.Phase = v1alpha1.RequestCompleted
+	}
+	meta.SetStatusCondition(&sr.Status.Conditions, cond)
+	sr.Status.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	return r.Client.Status().Update(ctx, sr)
+}
+
+// SetupWithManager registers the reconciler with the controller manager.
+// Status updates do not change the generation and so do not retrigger it.
+func (r *SalvageRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SalvageRequest{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
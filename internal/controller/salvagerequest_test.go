@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sevents "k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	"github.com/ppiankov/tote/internal/agent"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/inventory"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/session"
+	"github.com/ppiankov/tote/internal/transfer"
+)
+
+// requestDigest matches the digest the fake store assigns to imported images,
+// so copies made by one target can serve the next.
+const requestDigest = "sha256:fake-14"
+
+// requestCluster starts one agent per node on its own loopback address.
+// Nodes listed in cached serve the image.
+func requestCluster(t *testing.T, sr *v1alpha1.SalvageRequest, nodes []*corev1.Node, cached ...string) *SalvageRequestReconciler {
+	t.Helper()
+
+	port := 0
+	objs := []runtime.Object{sr}
+	for i, node := range nodes {
+		store := agent.NewFakeImageStore()
+		for _, name := range cached {
+			if name == node.Name {
+				store.AddImage(requestDigest, []byte("image-tar-data"))
+				node.Status.Images = []corev1.ContainerImage{{Names: []string{"registry.example.com/app@" + requestDigest}}}
+			}
+		}
+		host := fmt.Sprintf("127.0.0.%d", i+1)
+		lis, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			t.Skipf("loopback address %s unavailable: %v", host, err)
+		}
+		port = lis.Addr().(*net.TCPAddr).Port
+
+		srv := grpc.NewServer()
+		v1.RegisterToteAgentServer(srv, &agent.Server{Store: store, Sessions: session.NewStore()})
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+
+		objs = append(objs, node, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "agent-" + node.Name,
+				Namespace: "tote-system",
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "tote",
+					"app.kubernetes.io/component": "agent",
+				},
+			},
+			Spec:   corev1.PodSpec{NodeName: node.Name},
+			Status: corev1.PodStatus{PodIP: host},
+		})
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(newScheme()).
+		WithRuntimeObjects(objs...).
+		WithStatusSubresource(&v1alpha1.SalvageRequest{}).
+		Build()
+	orch := transfer.NewOrchestrator(session.NewStore(), transfer.NewResolver(cl, "tote-system", port),
+		events.NewEmitter(k8sevents.NewFakeRecorder(10)), metrics.NewCounters(prometheus.NewRegistry()),
+		cl, 2, 5*time.Minute, 0)
+	return &SalvageRequestReconciler{
+		Client:       cl,
+		Config:       config.New(),
+		Finder:       inventory.NewFinder(cl),
+		Orchestrator: orch,
+		Namespace:    "tote-system",
+	}
+}
+
+func salvageRequest(spec v1alpha1.SalvageRequestSpec) *v1alpha1.SalvageRequest {
+	return &v1alpha1.SalvageRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "prewarm", Namespace: "tote-system", Generation: 1},
+		Spec:       spec,
+	}
+}
+
+func labelledNode(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func reconcileSalvageRequest(t *testing.T, r *SalvageRequestReconciler) v1alpha1.SalvageRequest {
+	t.Helper()
+	key := client.ObjectKey{Namespace: "tote-system", Name: "prewarm"}
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	var sr v1alpha1.SalvageRequest
+	if err := r.Client.Get(context.Background(), key, &sr); err != nil {
+		t.Fatal(err)
+	}
+	return sr
+}
+
+func TestSalvageRequest_CopiesToTargets(t *testing.T) {
+	sr := salvageRequest(v1alpha1.SalvageRequestSpec{
+		Digest:      requestDigest,
+		ImageRef:    "registry.example.com/app:v1",
+		TargetNodes: []string{"node-a", "node-b", "node-src"},
+	})
+	r := requestCluster(t, sr, []*corev1.Node{
+		labelledNode("node-src", nil),
+		labelledNode("node-a", nil),
+		labelledNode("node-b", nil),
+	}, "node-src")
+
+	got := reconcileSalvageRequest(t, r)
+	if got.Status.Phase != v1alpha1.RequestCompleted {
+		t.Fatalf("expected Completed, got %s (%+v)", got.Status.Phase, got.Status)
+	}
+	if got.Status.Digest != requestDigest {
+		t.Errorf("expected digest %s, got %s", requestDigest, got.Status.Digest)
+	}
+	if len(got.Status.Targets) != 3 {
+		t.Fatalf("expected 3 targets, got %+v", got.Status.Targets)
+	}
+	for _, target := range got.Status.Targets {
+		if target.Phase != v1alpha1.RequestCompleted {
+			t.Errorf("target %s: expected Completed, got %s (%s)", target.Node, target.Phase, target.Error)
+		}
+		switch target.Node {
+		case "node-src":
+			if target.SourceNode != "" {
+				t.Errorf("cached target should not need a source, got %s", target.SourceNode)
+			}
+		default:
+			if target.SourceNode == "" {
+				t.Errorf("target %s: expected a source node", target.Node)
+			}
+		}
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ConditionComplete)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "AllTargetsCompleted" {
+		t.Errorf("expected Complete=True, got %+v", cond)
+	}
+	if got.Status.ObservedGeneration != 1 || got.Status.CompletedAt == "" {
+		t.Errorf("expected observedGeneration and completedAt, got %+v", got.Status)
+	}
+}
+
+func TestSalvageRequest_NodeSelector(t *testing.T) {
+	pool := map[string]string{"pool": "new"}
+	sr := salvageRequest(v1alpha1.SalvageRequestSpec{
+		Digest:       requestDigest,
+		NodeSelector: pool,
+	})
+	r := requestCluster(t, sr, []*corev1.Node{
+		labelledNode("node-src", nil),
+		labelledNode("node-new", pool),
+		labelledNode("node-other", nil),
+	}, "node-src")
+
+	got := reconcileSalvageRequest(t, r)
+	if got.Status.Phase != v1alpha1.RequestCompleted {
+		t.Fatalf("expected Completed, got %s (%+v)", got.Status.Phase, got.Status)
+	}
+	if len(got.Status.Targets) != 1 || got.Status.Targets[0].Node != "node-new" {
+		t.Errorf("expected only node-new as target, got %+v", got.Status.Targets)
+	}
+	if got.Status.Targets[0].SourceNode != "node-src" {
+		t.Errorf("expected node-src as source, got %s", got.Status.Targets[0].SourceNode)
+	}
+}
+
+func TestSalvageRequest_Unresolvable(t *testing.T) {
+	sr := salvageRequest(v1alpha1.SalvageRequestSpec{
+		ImageRef:    "registry.example.com/app:missing",
+		TargetNodes: []string{"node-a"},
+	})
+	r := requestCluster(t, sr, []*corev1.Node{labelledNode("node-a", nil)})
+
+	got := reconcileSalvageRequest(t, r)
+	if got.Status.Phase != v1alpha1.RequestFailed {
+		t.Fatalf("expected Failed, got %s", got.Status.Phase)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ConditionComplete)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Unresolvable" {
+		t.Errorf("expected Complete=False/Unresolvable, got %+v", cond)
+	}
+}
+
+func TestSalvageRequest_OutsideControllerNamespace(t *testing.T) {
+	sr := salvageRequest(v1alpha1.SalvageRequestSpec{
+		Digest:       requestDigest,
+		TargetNodes:  []string{"node-a"},
+		PushToBackup: true,
+	})
+	r := requestCluster(t, sr, []*corev1.Node{
+		labelledNode("node-src", nil),
+		labelledNode("node-a", nil),
+	}, "node-src")
+	r.Namespace = "tote"
+
+	got := reconcileSalvageRequest(t, r)
+	if got.Status.Phase != v1alpha1.RequestFailed || len(got.Status.Targets) != 0 {
+		t.Fatalf("expected the request to fail without transfers, got %+v", got.Status)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ConditionComplete)
+	if cond == nil || cond.Reason != "NamespaceNotAllowed" {
+		t.Errorf("expected NamespaceNotAllowed, got %+v", cond)
+	}
+}
+
+func TestSalvageRequest_TargetWithoutAgentFails(t *testing.T) {
+	sr := salvageRequest(v1alpha1.SalvageRequestSpec{
+		Digest:      requestDigest,
+		TargetNodes: []string{"node-a", "node-gone"},
+	})
+	r := requestCluster(t, sr, []*corev1.Node{
+		labelledNode("node-src", nil),
+		labelledNode("node-a", nil),
+	}, "node-src")
+
+	got := reconcileSalvageRequest(t, r)
+	if got.Status.Phase != v1alpha1.RequestFailed {
+		t.Fatalf("expected Failed, got %s", got.Status.Phase)
+	}
+	for _, target := range got.Status.Targets {
+		switch target.Node {
+		case "node-a":
+			if target.Phase != v1alpha1.RequestCompleted {
+				t.Errorf("node-a: expected Completed, got %s (%s)", target.Phase, target.Error)
+			}
+		case "node-gone":
+			if target.Phase != v1alpha1.RequestFailed || target.Error == "" {
+				t.Errorf("node-gone: expected Failed with error, got %+v", target)
+			}
+		}
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ConditionComplete)
+	if cond == nil || cond.Reason != "TargetsFailed" {
+		t.Errorf("expected TargetsFailed, got %+v", cond)
+	}
+}
+
+func TestSalvageRequest_RunsOncePerGeneration(t *testing.T) {
+	sr := salvageRequest(v1alpha1.SalvageRequestSpec{
+		Digest:      requestDigest,
+		TargetNodes: []string{"node-a"},
+	})
+	r := requestCluster(t, sr, []*corev1.Node{
+		labelledNode("node-src", nil),
+		labelledNode("node-a", nil),
+	}, "node-src")
+
+	first := reconcileSalvageRequest(t, r)
+	if first.Status.Phase != v1alpha1.RequestCompleted {
+		t.Fatalf("expected Completed, got %s", first.Status.Phase)
+	}
+	second := reconcileSalvageRequest(t, r)
+	if second.Status.CompletedAt != first.Status.CompletedAt || len(second.Status.Targets) != 1 {
+		t.Errorf("expected the completed generation to be left alone, got %+v", second.Status)
+	}
+}
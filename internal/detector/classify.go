@@ -0,0 +1,104 @@
+package detector
+
+import "strings"
+
+// Class is the cause of an image pull failure, parsed from the kubelet
+// message.
+type Class string
+
+const (
+	// ClassUnknown is a failure whose message names no known cause, such as
+	// a back-off message without the underlying error.
+	ClassUnknown Class = ""
+
+	// ClassAuth is a failure to authenticate or an authorization denial.
+	ClassAuth Class = "auth"
+
+	// ClassNotFound is a repository, tag or manifest the registry does not have.
+	ClassNotFound Class = "not-found"
+
+	// ClassUnavailable is a registry that cannot be reached or answers with a
+	// server error: DNS failures, refused or reset connections, timeouts, 5xx.
+	ClassUnavailable Class = "unavailable"
+
+	// ClassRateLimited is a registry answering 429 Too Many Requests.
+	ClassRateLimited Class = "rate-limited"
+
+	// ClassTLS is a certificate or TLS handshake failure.
+	ClassTLS Class = "tls"
+
+	// ClassNeverPull is an image missing on the node of a container with
+	// imagePullPolicy Never (ErrImageNeverPull). Only another node's cache
+	// can provide it.
+	ClassNeverPull Class = "never-pull"
+
+	// ClassInvalidName is an image reference kubelet cannot parse
+	// (InvalidImageName). No registry or node can provide it.
+	ClassInvalidName Class = "invalid-name"
+)
+
+// Outage reports whether the class points at the registry rather than at
+// the pod: every pod pulling from an unreachable, rate limiting or
+// TLS-failing registry fails the same way.
+func (c Class) Outage() bool {
+	return c == ClassUnavailable || c == ClassRateLimited || c == ClassTLS
+}
+
+// classPatterns are matched in order against the lowercased message; the
+// first class with a matching pattern wins. Rate limiting and connectivity
+// come first because their messages may also mention authorization or a
+// handshake. Not found comes before auth because Docker Hub answers a pull
+// of a repository that does not exist with "pull access denied". Status codes
+// are matched with their text, since image digests in the message can
+// contain any digits.
+var classPatterns = []struct {
+	class    Class
+	patterns []string
+}{
+	{ClassRateLimited, []string{"too many requests", "toomanyrequests", "rate limit"}},
+	{ClassUnavailable, []string{
+		"no such host", "server misbehaving", "connection refused", "connection reset",
+		"network is unreachable", "no route to host", "i/o timeout", "handshake timeout",
+		"context deadline exceeded", "500 internal server error", "502 bad gateway",
+		"503 service unavailable", "504 gateway timeout",
+	}},
+	{ClassTLS, []string{"x509:", "tls:", "certificate", "handshake"}},
+	{ClassNotFound, []string{"repository does not exist", "not found", "manifest unknown", "name unknown"}},
+	{ClassAuth, []string{
+		"unauthorized", "authentication required", "no basic auth credentials",
+		"pull access denied", "access denied", "denied:", "403 forbidden", "insufficient_scope",
+	}},
+}
+
+// Futile reports whether salvaging cannot fix the failure because its cause
+// is in the pod's configuration: an unparseable image name, or credentials
+// the registry rejects. Copying the image onto the node would only hide the
+// error until the pod lands on another node.
+func (c Class) Futile() bool {
+	return c == ClassInvalidName || c == ClassAuth
+}
+
+// classifyReason returns the class of a failure with the kubelet waiting
+// reason, parsing msg for reasons that do not name the cause.
+func classifyReason(reason, msg string) Class {
+	switch reason {
+	case "ErrImageNeverPull":
+		return ClassNeverPull
+	case "InvalidImageName":
+		return ClassInvalidName
+	}
+	return Classify(msg)
+}
+
+// Classify returns the cause of a pull failure from its kubelet message.
+func Classify(msg string) Class {
+	msg = strings.ToLower(msg)
+	for _, cp := range classPatterns {
+		for _, p := range cp.patterns {
+			if strings.Contains(msg, p) {
+				return cp.class
+			}
+		}
+	}
+	return ClassUnknown
+}
@@ -0,0 +1,60 @@
+package detector
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want Class
+	}{
+		{`failed to pull and unpack image "registry.example.com/app:v1": failed to resolve reference "registry.example.com/app:v1": failed to do request: Head "https://registry.example.com/v2/app/manifests/v1": dial tcp: lookup registry.example.com on 10.96.0.10:53: no such host`, ClassUnavailable},
+		{`failed to do request: Head "https://registry.example.com/v2/app/manifests/v1": dial tcp 10.0.0.5:443: connect: connection refused`, ClassUnavailable},
+		{`unexpected status from HEAD request to https://registry.example.com/v2/app/manifests/v1: 503 Service Unavailable`, ClassUnavailable},
+		{`unexpected status from HEAD request to https://registry-1.docker.io/v2/library/nginx/manifests/latest: 429 Too Many Requests - Server message: toomanyrequests: You have reached your pull rate limit.`, ClassRateLimited},
+		{`failed to do request: Head "https://registry.example.com/v2/app/manifests/v1": tls: failed to verify certificate: x509: certificate signed by unknown authority`, ClassTLS},
+		{`failed to authorize: failed to fetch anonymous token: unexpected status from GET request to https://auth.example.com/token: 401 Unauthorized`, ClassAuth},
+		{`failed to resolve reference "docker.io/library/nginx:nope": docker.io/library/nginx:nope: not found`, ClassNotFound},
+		{`failed to pull and unpack image "docker.io/library/ngnix:latest": failed to resolve reference "docker.io/library/ngnix:latest": pull access denied, repository does not exist or may require authorization: server message: insufficient_scope: authorization failed`, ClassNotFound},
+		{`Error response from daemon: pull access denied for ngnix, repository does not exist or may require 'docker login': denied: requested access to the resource is denied`, ClassNotFound},
+		{`failed to pull and unpack image "registry.example.com/app:v1": failed to resolve reference "registry.example.com/app:v1": unexpected status from HEAD request to https://registry.example.com/v2/app/manifests/v1: 403 Forbidden`, ClassAuth},
+		{`Back-off pulling image "registry.example.com/app@sha256:4290404"`, ClassUnknown},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.msg); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestDetect_Class(t *testing.T) {
+	pod := podWithContainerStatus(waitingStatus("app", "ErrImagePull", "dial tcp: lookup registry.example.com: no such host"))
+	failures := Detect(pod)
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 failure, got %d", len(failures))
+	}
+	if failures[0].Class != ClassUnavailable || !failures[0].Class.Outage() {
+		t.Errorf("expected an outage class, got %q", failures[0].Class)
+	}
+}
+
+func TestDetect_ConfigReasons(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   Class
+		futile bool
+	}{
+		{"ErrImageNeverPull", ClassNeverPull, false},
+		{"InvalidImageName", ClassInvalidName, true},
+		{"ErrImagePull", ClassAuth, true},
+	}
+	for _, tt := range tests {
+		pod := podWithContainerStatus(waitingStatus("app", tt.reason, "unexpected status from HEAD request to https://registry.example.com/v2/app/manifests/v1: 401 Unauthorized"))
+		failures := Detect(pod)
+		if len(failures) != 1 {
+			t.Fatalf("%s: expected 1 failure, got %d", tt.reason, len(failures))
+		}
+		if got := failures[0].Class; got != tt.want || got.Futile() != tt.futile {
+			t.Errorf("%s: expected class %q (futile %v), got %q", tt.reason, tt.want, tt.futile, got)
+		}
+	}
+}
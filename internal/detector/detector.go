@@ -9,32 +9,110 @@ import (
 // Failure represents a detected image pull failure for a single container.
 type Failure struct {
 	ContainerName string
-	Image         string
-	Reason        string
-	Message       string
+	// Kind is the kind of container that failed: regular, init, native
+	// sidecar or ephemeral.
+	Kind    ContainerKind
+	Image   string
+	Reason  string
+	Message string
+	// Class is the cause of a pull failure, from Reason or parsed from
+	// Message.
+	Class Class
 	// CorruptImage is true when the image record exists but content blobs
 	// are missing (CreateContainerError with rootfs resolution failure).
 	CorruptImage bool
 }
 
+// ContainerKind is the kind of container within a pod.
+type ContainerKind string
+
+const (
+	// KindContainer is a regular container.
+	KindContainer ContainerKind = "container"
+
+	// KindInit is an init container that runs to completion before the
+	// regular containers start.
+	KindInit ContainerKind = "init"
+
+	// KindSidecar is a native sidecar: an init container with restartPolicy
+	// Always that keeps running alongside the regular containers.
+	KindSidecar ContainerKind = "sidecar"
+
+	// KindEphemeral is an ephemeral container added for debugging, e.g. by
+	// kubectl debug.
+	KindEphemeral ContainerKind = "ephemeral"
+)
+
 var imagePullFailureReasons = map[string]bool{
-	"ImagePullBackOff": true,
-	"ErrImagePull":     true,
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"ErrImageNeverPull": true,
+	"InvalidImageName":  true,
 }
 
 // Detect inspects a Pod and returns any image pull failures found across
-// both regular and init container statuses.
+// regular, init (including native sidecar) and ephemeral container statuses,
+// each with the Class of its cause.
 func Detect(pod *corev1.Pod) []Failure {
+	containers := Containers(pod)
 	var failures []Failure
-	failures = append(failures, detectInStatuses(pod.Status.ContainerStatuses, pod.Spec.Containers)...)
-	failures = append(failures, detectInStatuses(pod.Status.InitContainerStatuses, pod.Spec.InitContainers)...)
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses, pod.Status.EphemeralContainerStatuses,
+	} {
+		failures = append(failures, detectInStatuses(statuses, containers)...)
+	}
 	return failures
 }
 
-func detectInStatuses(statuses []corev1.ContainerStatus, specs []corev1.Container) []Failure {
-	specImage := make(map[string]string, len(specs))
-	for _, c := range specs {
-		specImage[c.Name] = c.Image
+// Container is the name, image and kind of one container of a pod.
+type Container struct {
+	Name  string
+	Image string
+	Kind  ContainerKind
+}
+
+// Containers returns every container of the pod: init containers and native
+// sidecars, regular containers and ephemeral containers, in that order.
+// Container names are unique across all of them.
+func Containers(pod *corev1.Pod) []Container {
+	containers := make([]Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers)+len(pod.Spec.EphemeralContainers))
+	for _, c := range pod.Spec.InitContainers {
+		kind := KindInit
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			kind = KindSidecar
+		}
+		containers = append(containers, Container{Name: c.Name, Image: c.Image, Kind: kind})
+	}
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, Container{Name: c.Name, Image: c.Image, Kind: KindContainer})
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		containers = append(containers, Container{Name: c.Name, Image: c.Image, Kind: KindEphemeral})
+	}
+	return containers
+}
+
+// EphemeralOnly reports whether image is only used by ephemeral containers
+// of the pod, so restarting the pod would not help its workload and would
+// end the debug session.
+func EphemeralOnly(pod *corev1.Pod, image string) bool {
+	ephemeral := false
+	for _, c := range Containers(pod) {
+		if c.Image != image {
+			continue
+		}
+		if c.Kind != KindEphemeral {
+			return false
+		}
+		ephemeral = true
+	}
+	return ephemeral
+}
+
+func detectInStatuses(statuses []corev1.ContainerStatus, containers []Container) []Failure {
+	byName := make(map[string]Container, len(containers))
+	for _, c := range containers {
+		byName[c.Name] = c
 	}
 
 	var failures []Failure
@@ -44,13 +122,16 @@ func detectInStatuses(statuses []corev1.ContainerStatus, specs []corev1.Containe
 		}
 		reason := cs.State.Waiting.Reason
 		msg := cs.State.Waiting.Message
+		c := byName[cs.Name]
 
 		if imagePullFailureReasons[reason] {
 			failures = append(failures, Failure{
 				ContainerName: cs.Name,
-				Image:         specImage[cs.Name],
+				Kind:          c.Kind,
+				Image:         c.Image,
 				Reason:        reason,
 				Message:       msg,
+				Class:         classifyReason(reason, msg),
 			})
 			continue
 		}
@@ -61,7 +142,8 @@ func detectInStatuses(statuses []corev1.ContainerStatus, specs []corev1.Containe
 		if reason == "CreateContainerError" && isCorruptImageMessage(msg) {
 			failures = append(failures, Failure{
 				ContainerName: cs.Name,
-				Image:         specImage[cs.Name],
+				Kind:          c.Kind,
+				Image:         c.Image,
 				Reason:        reason,
 				Message:       msg,
 				CorruptImage:  true,
@@ -98,6 +98,58 @@ func TestDetect_InitContainerFailure(t *testing.T) {
 	if failures[0].ContainerName != "init" {
 		t.Errorf("expected container name 'init', got %q", failures[0].ContainerName)
 	}
+	if failures[0].Image != "registry.example.com/init:v1" || failures[0].Kind != KindInit {
+		t.Errorf("expected init image and kind, got %q %q", failures[0].Image, failures[0].Kind)
+	}
+}
+
+func TestDetect_SidecarAndEphemeral(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "proxy", Image: "registry.example.com/proxy:v1", RestartPolicy: &always}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.36"},
+			}},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses:      []corev1.ContainerStatus{waitingStatus("proxy", "ErrImagePull", "")},
+			EphemeralContainerStatuses: []corev1.ContainerStatus{waitingStatus("debugger", "ImagePullBackOff", "")},
+		},
+	}
+	failures := Detect(pod)
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failures, got %d", len(failures))
+	}
+	if f := failures[0]; f.ContainerName != "proxy" || f.Kind != KindSidecar || f.Image != "registry.example.com/proxy:v1" {
+		t.Errorf("expected the sidecar failure, got %+v", f)
+	}
+	if f := failures[1]; f.ContainerName != "debugger" || f.Kind != KindEphemeral || f.Image != "busybox:1.36" {
+		t.Errorf("expected the ephemeral failure, got %+v", f)
+	}
+}
+
+func TestEphemeralOnly(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "app", Image: "busybox:1.36"}},
+		EphemeralContainers: []corev1.EphemeralContainer{
+			{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.36"}},
+			{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "shell", Image: "alpine:3.20"}},
+		},
+	}}
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"alpine:3.20", true},
+		{"busybox:1.36", false}, // also used by the app container
+		{"nginx:1.25", false},
+	}
+	for _, tt := range tests {
+		if got := EphemeralOnly(pod, tt.image); got != tt.want {
+			t.Errorf("EphemeralOnly(%q) = %v, want %v", tt.image, got, tt.want)
+		}
+	}
 }
 
 func TestDetect_MixedStates(t *testing.T) {
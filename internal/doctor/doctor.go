@@ -68,16 +68,16 @@ func checkCRD(ctx context.Context, clientset kubernetes.Interface) Check {
 		// Fake clients don't have a real discovery client; check API resources instead.
 		return checkCRDViaResources(ctx, clientset)
 	}
-	resources, err := dc.ServerResourcesForGroupVersion("tote.dev/v1alpha1")
+	resources, err := dc.ServerResourcesForGroupVersion("tote.dev/v1alpha2")
 	if err != nil {
-		return Check{Name: "crd", Status: StatusFail, Message: "salvagerecords.tote.dev CRD not installed"}
+		return Check{Name: "crd", Status: StatusFail, Message: "salvagerecords.tote.dev CRD not installed or does not serve v1alpha2"}
 	}
 	for _, r := range resources.APIResources {
 		if r.Kind == "SalvageRecord" {
 			return Check{Name: "crd", Status: StatusOK, Message: "salvagerecords.tote.dev installed"}
 		}
 	}
-	return Check{Name: "crd", Status: StatusFail, Message: "SalvageRecord kind not found in tote.dev/v1alpha1"}
+	return Check{Name: "crd", Status: StatusFail, Message: "SalvageRecord kind not found in tote.dev/v1alpha2; apply the current CRDs"}
 }
 
 func checkCRDViaResources(ctx context.Context, clientset kubernetes.Interface) Check {
@@ -85,7 +85,7 @@ func checkCRDViaResources(ctx context.Context, clientset kubernetes.Interface) C
 	if err != nil {
 		return Check{Name: "crd", Status: StatusFail, Message: fmt.Sprintf("cannot discover resources: %v", err)}
 	}
-	gv := schema.GroupVersion{Group: "tote.dev", Version: "v1alpha1"}.String()
+	gv := schema.GroupVersion{Group: "tote.dev", Version: "v1alpha2"}.String()
 	for _, rl := range resources {
 		if rl.GroupVersion == gv {
 			for _, r := range rl.APIResources {
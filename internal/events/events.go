@@ -1,10 +1,17 @@
 package events
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/events"
+
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/templates"
+	"github.com/ppiankov/tote/internal/workload"
 )
 
 const (
@@ -23,6 +30,12 @@ const (
 	// ReasonCorruptImage indicates the image record exists but content blobs are missing.
 	ReasonCorruptImage = "ImageCorrupt"
 
+	// ReasonCorruptContent indicates a running pod's image was found corrupt on its node by the agent scanner.
+	ReasonCorruptContent = "ImageContentCorrupt"
+
+	// ReasonRepaired indicates missing blobs of a corrupt image were fetched from another source.
+	ReasonRepaired = "ImageRepaired"
+
 	// ReasonResolvedUncached indicates the tag was resolved via registry but no node has the digest cached.
 	ReasonResolvedUncached = "ImageResolvedUncached"
 
@@ -32,16 +45,64 @@ const (
 	// ReasonPushFailed indicates the registry push failed.
 	ReasonPushFailed = "ImagePushFailed"
 
-	actionDetected  = "Detected"
-	actionSalvaged  = "Salvaged"
-	actionSalvaging = "Salvaging"
-	actionCleaning  = "Cleaning"
-	actionPushing   = "Pushing"
+	// ReasonPreseeded indicates the image was copied to a node an unscheduled pod can land on.
+	ReasonPreseeded = "ImagePreseeded"
+
+	// ReasonRescheduled indicates the pod was deleted so it is recreated on a node caching the image.
+	ReasonRescheduled = "ImageRescheduled"
+
+	// ReasonManualRestart indicates the image was fixed but nothing would recreate the pod.
+	ReasonManualRestart = "ManualRestartRequired"
+
+	// ReasonRestartUnsupported indicates tote.dev/recovery: restart cannot roll the pod's workload, so the pod was evicted instead.
+	ReasonRestartUnsupported = "RestartUnsupported"
+
+	// ReasonInvalidImageName indicates kubelet cannot parse the image reference.
+	ReasonInvalidImageName = "ImageNameInvalid"
+
+	// ReasonPullSecretMissing indicates the registry denied the pull and a pull secret the pod references does not exist.
+	ReasonPullSecretMissing = "PullSecretMissing"
+
+	// ReasonRegistryAuthFailed indicates the registry denied the pull with the credentials presented.
+	ReasonRegistryAuthFailed = "RegistryAuthFailed"
+
+	// ReasonImageNotFound indicates the registry does not have the image and no node caches it.
+	ReasonImageNotFound = "ImageNotFound"
+
+	// ReasonRegistryOutage indicates many pods fail to pull from one registry because it is unreachable, rate limiting or failing TLS.
+	ReasonRegistryOutage = "RegistryOutage"
+
+	// ReasonRegistryRecovered indicates a registry in an outage had no pull failures for the detection window.
+	ReasonRegistryRecovered = "RegistryRecovered"
+
+	actionDetected     = "Detected"
+	actionSalvaged     = "Salvaged"
+	actionSalvaging    = "Salvaging"
+	actionCleaning     = "Cleaning"
+	actionRepairing    = "Repairing"
+	actionPushing      = "Pushing"
+	actionPreseeding   = "Preseeding"
+	actionRescheduling = "Rescheduling"
+	actionRecovering   = "Recovering"
 )
 
+// Reasons lists every event reason, for validating message templates.
+var Reasons = []string{
+	ReasonSalvageable, ReasonNotActionable, ReasonSalvaged, ReasonSalvageFailed,
+	ReasonCorruptImage, ReasonCorruptContent, ReasonRepaired, ReasonResolvedUncached,
+	ReasonPushed, ReasonPushFailed, ReasonPreseeded, ReasonRescheduled,
+	ReasonManualRestart, ReasonRestartUnsupported, ReasonRegistryOutage, ReasonRegistryRecovered,
+	ReasonInvalidImageName, ReasonPullSecretMissing, ReasonRegistryAuthFailed, ReasonImageNotFound,
+}
+
 // Emitter emits Kubernetes events for tote detections.
 type Emitter struct {
-	Recorder events.EventRecorder
+	Recorder  events.EventRecorder
+	Templates *templates.Set // nil = built-in messages
+
+	// Owners, when set, finds the pod's owning workload so every event is
+	// also recorded there and survives the pod's deletion.
+	Owners *owners.Resolver
 }
 
 // NewEmitter creates an Emitter with the given recorder.
@@ -51,9 +112,8 @@ func NewEmitter(recorder events.EventRecorder) *Emitter {
 
 // EmitSalvageable emits a Warning event indicating the image digest exists on
 // specific nodes.
-func (e *Emitter) EmitSalvageable(pod *corev1.Pod, image string, nodes []string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonSalvageable, actionDetected,
+func (e *Emitter) EmitSalvageable(ctx context.Context, pod *corev1.Pod, image string, nodes []string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonSalvageable, actionDetected, templates.Data{Image: image, Nodes: nodes},
 		"Registry pull failed for %s; image digest exists on nodes: [%s]. This is technical debt — rebuild and push the image properly.",
 		image, strings.Join(nodes, ", "),
 	)
@@ -61,9 +121,8 @@ func (e *Emitter) EmitSalvageable(pod *corev1.Pod, image string, nodes []string)
 
 // EmitNotActionable emits a Warning event indicating the image uses a tag,
 // not a digest, so tote cannot determine cache locality.
-func (e *Emitter) EmitNotActionable(pod *corev1.Pod, image string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonNotActionable, actionDetected,
+func (e *Emitter) EmitNotActionable(ctx context.Context, pod *corev1.Pod, image string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonNotActionable, actionDetected, templates.Data{Image: image},
 		"Not actionable: image %s uses tag, not digest. Pin images by digest for tote to help.",
 		image,
 	)
@@ -71,18 +130,16 @@ func (e *Emitter) EmitNotActionable(pod *corev1.Pod, image string) {
 
 // EmitSalvaged emits a Warning event indicating the image was transferred
 // between nodes via containerd.
-func (e *Emitter) EmitSalvaged(pod *corev1.Pod, image, sourceNode, targetNode string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonSalvaged, actionSalvaged,
+func (e *Emitter) EmitSalvaged(ctx context.Context, pod *corev1.Pod, image, sourceNode, targetNode string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonSalvaged, actionSalvaged, templates.Data{Image: image, SourceNode: sourceNode, TargetNode: targetNode},
 		"Image %s salvaged from node %s to node %s via containerd. This is emergency — rebuild properly.",
 		image, sourceNode, targetNode,
 	)
 }
 
 // EmitSalvageFailed emits a Warning event indicating the salvage attempt failed.
-func (e *Emitter) EmitSalvageFailed(pod *corev1.Pod, image, reason string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonSalvageFailed, actionSalvaging,
+func (e *Emitter) EmitSalvageFailed(ctx context.Context, pod *corev1.Pod, image, reason string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonSalvageFailed, actionSalvaging, templates.Data{Image: image, Reason: reason},
 		"Image salvage failed for %s: %s",
 		image, reason,
 	)
@@ -90,39 +147,188 @@ func (e *Emitter) EmitSalvageFailed(pod *corev1.Pod, image, reason string) {
 
 // EmitCorruptImage emits a Warning event indicating a corrupt image record was
 // detected and removed from the node's containerd.
-func (e *Emitter) EmitCorruptImage(pod *corev1.Pod, image, nodeName string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonCorruptImage, actionCleaning,
+func (e *Emitter) EmitCorruptImage(ctx context.Context, pod *corev1.Pod, image, nodeName string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonCorruptImage, actionCleaning, templates.Data{Image: image, TargetNode: nodeName},
 		"Corrupt image record for %s on node %s: content blobs missing. Removing stale record.",
 		image, nodeName,
 	)
 }
 
+// EmitCorruptContent emits a Warning event indicating the agent scanner found
+// the running pod's image incomplete on its node, so the next container
+// restart will fail until the image is repaired or pulled again.
+func (e *Emitter) EmitCorruptContent(ctx context.Context, pod *corev1.Pod, image, nodeName string, blobs int) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonCorruptContent, actionDetected, templates.Data{Image: image, TargetNode: nodeName, Blobs: blobs},
+		"Image %s on node %s has %d missing or corrupt blob(s); the next container restart on this node will fail.",
+		image, nodeName, blobs,
+	)
+}
+
+// EmitRepaired emits a Normal event indicating the missing blobs of a corrupt
+// image were fetched from another node or the backup registry.
+func (e *Emitter) EmitRepaired(ctx context.Context, pod *corev1.Pod, image string, blobs int, source string) {
+	e.emit(ctx, pod, corev1.EventTypeNormal, ReasonRepaired, actionRepairing, templates.Data{Image: image, Blobs: blobs, SourceNode: source},
+		"Corrupt image %s repaired: fetched %d missing blob(s) from %s.",
+		image, blobs, source,
+	)
+}
+
 // EmitPushed emits a Normal event indicating the image was pushed to a backup registry.
-func (e *Emitter) EmitPushed(pod *corev1.Pod, digest, targetRef, sourceNode string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeNormal, ReasonPushed, actionPushing,
+func (e *Emitter) EmitPushed(ctx context.Context, pod *corev1.Pod, digest, targetRef, sourceNode string) {
+	e.emit(ctx, pod, corev1.EventTypeNormal, ReasonPushed, actionPushing, templates.Data{Digest: digest, BackupRef: targetRef, SourceNode: sourceNode},
 		"Image %s pushed to backup registry %s from node %s.",
 		digest, targetRef, sourceNode,
 	)
 }
 
 // EmitPushFailed emits a Warning event indicating the registry push failed.
-func (e *Emitter) EmitPushFailed(pod *corev1.Pod, digest, targetRef, reason string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonPushFailed, actionPushing,
+func (e *Emitter) EmitPushFailed(ctx context.Context, pod *corev1.Pod, digest, targetRef, reason string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonPushFailed, actionPushing, templates.Data{Digest: digest, BackupRef: targetRef, Reason: reason},
 		"Registry push failed for %s to %s: %s",
 		digest, targetRef, reason,
 	)
 }
 
+// EmitPreseeded emits a Normal event indicating the image was copied to a
+// node the unscheduled pod can schedule to, ahead of it landing there.
+func (e *Emitter) EmitPreseeded(ctx context.Context, pod *corev1.Pod, image, sourceNode, targetNode string) {
+	e.emit(ctx, pod, corev1.EventTypeNormal, ReasonPreseeded, actionPreseeding, templates.Data{Image: image, SourceNode: sourceNode, TargetNode: targetNode},
+		"Image %s pre-seeded from node %s to node %s, where this pod can be scheduled.",
+		image, sourceNode, targetNode,
+	)
+}
+
+// EmitRescheduled emits a Normal event indicating the pod was deleted so its
+// replacement prefers nodes that already cache the image.
+func (e *Emitter) EmitRescheduled(ctx context.Context, pod *corev1.Pod, image string, nodes []string) {
+	e.emit(ctx, pod, corev1.EventTypeNormal, ReasonRescheduled, actionRescheduling, templates.Data{Image: image, Nodes: nodes},
+		"Registry pull failed for %s on node %s; deleting pod so its replacement prefers nodes caching the image: [%s].",
+		image, pod.Spec.NodeName, strings.Join(nodes, ", "),
+	)
+}
+
+// EmitManualRestart emits a Warning event telling the operator to restart a
+// pod whose image was fixed on its node, because no controller would
+// recreate it.
+func (e *Emitter) EmitManualRestart(ctx context.Context, pod *corev1.Pod, image string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonManualRestart, actionRecovering, templates.Data{Image: image},
+		"Image %s is now usable on node %s, but no controller will recreate this pod. Restart it manually if kubelet's next pull retry does not start it.",
+		image, pod.Spec.NodeName,
+	)
+}
+
+// EmitRestartUnsupported emits a Warning event indicating the restart
+// recovery cannot roll the pod's workload, given as Kind/name, so the pod is
+// evicted instead.
+func (e *Emitter) EmitRestartUnsupported(ctx context.Context, pod *corev1.Pod, image, owner string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonRestartUnsupported, actionRecovering, templates.Data{Image: image, Workload: owner},
+		"Image %s is now usable on node %s, but tote.dev/recovery: restart cannot roll %s; evicting the pod instead.",
+		image, pod.Spec.NodeName, owner,
+	)
+}
+
+// EmitInvalidImageName emits a Warning event indicating kubelet cannot parse
+// the image reference, so no registry or node can provide it.
+func (e *Emitter) EmitInvalidImageName(ctx context.Context, pod *corev1.Pod, image, detail string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonInvalidImageName, actionDetected, templates.Data{Image: image, Reason: detail},
+		"Image name %q is invalid: %s. Fix the image reference; tote cannot salvage it.",
+		image, detail,
+	)
+}
+
+// EmitPullSecretMissing emits a Warning event indicating the registry denied
+// the pull and secret, a pull secret the pod references, does not exist.
+func (e *Emitter) EmitPullSecretMissing(ctx context.Context, pod *corev1.Pod, image, registry, secret string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonPullSecretMissing, actionDetected, templates.Data{Image: image, Registry: registry, Reason: secret},
+		"Pull secret %s missing in namespace %s; registry %s denied the pull of %s.",
+		secret, pod.Namespace, registry, image,
+	)
+}
+
+// EmitRegistryAuthFailed emits a Warning event indicating the registry
+// denied the pull. secrets are the pull secrets the pod references; without
+// any, the credentials may also come from the node, a kubelet credential
+// provider or the service account, so none is blamed.
+func (e *Emitter) EmitRegistryAuthFailed(ctx context.Context, pod *corev1.Pod, image, registry string, secrets []string) {
+	if len(secrets) == 0 {
+		e.emit(ctx, pod, corev1.EventTypeWarning, ReasonRegistryAuthFailed, actionDetected, templates.Data{Image: image, Registry: registry},
+			"Registry %s denied the pull of %s. Check the credentials available to the pod and its node grant access to the image.",
+			registry, image,
+		)
+		return
+	}
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonRegistryAuthFailed, actionDetected, templates.Data{Image: image, Registry: registry, Reason: strings.Join(secrets, ", ")},
+		"Registry %s rejected the credentials for %s from pull secrets [%s]. Check they are valid for this registry and grant access to the image.",
+		registry, image, strings.Join(secrets, ", "),
+	)
+}
+
+// EmitImageNotFound emits a Warning event indicating the registry does not
+// have the image and no node caches it, typically a typo in the name or tag.
+func (e *Emitter) EmitImageNotFound(ctx context.Context, pod *corev1.Pod, image, registry string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonImageNotFound, actionDetected, templates.Data{Image: image, Registry: registry},
+		"Image %s does not exist in registry %s and no node caches it. Check the image name and tag.",
+		image, registry,
+	)
+}
+
 // EmitResolvedButUncached emits a Warning event indicating the image tag was
 // resolved to a digest via the source registry, but no node has that digest
 // cached in containerd. The image exists in the registry but was never pulled.
-func (e *Emitter) EmitResolvedButUncached(pod *corev1.Pod, image, digest string) {
-	e.Recorder.Eventf(
-		pod, nil, corev1.EventTypeWarning, ReasonResolvedUncached, actionDetected,
+func (e *Emitter) EmitResolvedButUncached(ctx context.Context, pod *corev1.Pod, image, digest string) {
+	e.emit(ctx, pod, corev1.EventTypeWarning, ReasonResolvedUncached, actionDetected, templates.Data{Image: image, Digest: digest},
 		"Tag %s resolved to %s via registry, but no node has it cached. Image exists in registry but was never pulled to this cluster.",
 		image, digest,
 	)
 }
+
+// EmitRegistryOutage emits a Warning event on obj, the cluster-level object
+// registry-wide events are recorded on, indicating pods fail to pull from
+// registry for the same cause.
+func (e *Emitter) EmitRegistryOutage(obj runtime.Object, registry, class string, pods int) {
+	e.emitOn(obj, corev1.EventTypeWarning, ReasonRegistryOutage, actionDetected, templates.Data{Registry: registry, Reason: class, PodCount: pods},
+		"Registry %s outage: %d pods failed to pull from it (%s). Pulls from this registry will keep failing until it recovers; tote salvages from node caches meanwhile.",
+		registry, pods, class,
+	)
+}
+
+// EmitRegistryRecovered emits a Normal event on obj indicating a registry in
+// an outage had no pull failures for the detection window.
+func (e *Emitter) EmitRegistryRecovered(obj runtime.Object, registry string) {
+	e.emitOn(obj, corev1.EventTypeNormal, ReasonRegistryRecovered, actionDetected, templates.Data{Registry: registry},
+		"Registry %s recovered: no pods failed to pull from it for the detection window.",
+		registry,
+	)
+}
+
+// emitOn records an event on a non-pod object, such as a Namespace for
+// cluster-wide conditions.
+func (e *Emitter) emitOn(obj runtime.Object, eventType, reason, action string, d templates.Data, format string, args ...any) {
+	msg, ok := e.Templates.Event(reason, d)
+	if !ok {
+		msg = fmt.Sprintf(format, args...)
+	}
+	e.Recorder.Eventf(obj, nil, eventType, reason, action, "%s", msg)
+}
+
+// emit records an event, rendering the operator's template for reason when
+// one is configured and falling back to the built-in message otherwise. ctx
+// bounds the lookup of the pod's workload.
+func (e *Emitter) emit(ctx context.Context, pod *corev1.Pod, eventType, reason, action string, d templates.Data, format string, args ...any) {
+	d.Namespace, d.Pod = pod.Namespace, pod.Name
+	if d.TargetNode == "" {
+		d.TargetNode = pod.Spec.NodeName
+	}
+	msg, ok := e.Templates.Event(reason, d)
+	if !ok {
+		msg = fmt.Sprintf(format, args...)
+	}
+	e.Recorder.Eventf(pod, nil, eventType, reason, action, "%s", msg)
+
+	if e.Owners == nil {
+		return
+	}
+	if owner := workload.Owner(ctx, e.Owners, pod); owner != nil {
+		e.Recorder.Eventf(owner, pod, eventType, reason, action, "Pod %s: %s", pod.Name, msg)
+	}
+}
@@ -1,12 +1,22 @@
 package events
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	k8sevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/templates"
 )
 
 func testPod() *corev1.Pod {
@@ -22,7 +32,7 @@ func TestEmitSalvageable(t *testing.T) {
 	rec := k8sevents.NewFakeRecorder(10)
 	emitter := NewEmitter(rec)
 
-	emitter.EmitSalvageable(testPod(), "nginx@sha256:abc123", []string{"node-1", "node-2"})
+	emitter.EmitSalvageable(context.Background(), testPod(), "nginx@sha256:abc123", []string{"node-1", "node-2"})
 
 	event := <-rec.Events
 	if !strings.Contains(event, ReasonSalvageable) {
@@ -40,7 +50,7 @@ func TestEmitNotActionable(t *testing.T) {
 	rec := k8sevents.NewFakeRecorder(10)
 	emitter := NewEmitter(rec)
 
-	emitter.EmitNotActionable(testPod(), "nginx:latest")
+	emitter.EmitNotActionable(context.Background(), testPod(), "nginx:latest")
 
 	event := <-rec.Events
 	if !strings.Contains(event, ReasonNotActionable) {
@@ -55,7 +65,7 @@ func TestEmitSalvageable_SingleNode(t *testing.T) {
 	rec := k8sevents.NewFakeRecorder(10)
 	emitter := NewEmitter(rec)
 
-	emitter.EmitSalvageable(testPod(), "app@sha256:def456", []string{"node-a"})
+	emitter.EmitSalvageable(context.Background(), testPod(), "app@sha256:def456", []string{"node-a"})
 
 	event := <-rec.Events
 	if !strings.Contains(event, "node-a") {
@@ -67,7 +77,7 @@ func TestEmitSalvaged(t *testing.T) {
 	rec := k8sevents.NewFakeRecorder(10)
 	emitter := NewEmitter(rec)
 
-	emitter.EmitSalvaged(testPod(), "nginx@sha256:abc123", "node-src", "node-tgt")
+	emitter.EmitSalvaged(context.Background(), testPod(), "nginx@sha256:abc123", "node-src", "node-tgt")
 
 	event := <-rec.Events
 	if !strings.Contains(event, ReasonSalvaged) {
@@ -82,7 +92,7 @@ func TestEmitSalvageFailed(t *testing.T) {
 	rec := k8sevents.NewFakeRecorder(10)
 	emitter := NewEmitter(rec)
 
-	emitter.EmitSalvageFailed(testPod(), "nginx@sha256:abc123", "connection refused")
+	emitter.EmitSalvageFailed(context.Background(), testPod(), "nginx@sha256:abc123", "connection refused")
 
 	event := <-rec.Events
 	if !strings.Contains(event, ReasonSalvageFailed) {
@@ -92,3 +102,162 @@ func TestEmitSalvageFailed(t *testing.T) {
 		t.Errorf("expected event to contain failure reason, got %q", event)
 	}
 }
+
+func TestEmitCorruptContent(t *testing.T) {
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+
+	emitter.EmitCorruptContent(context.Background(), testPod(), "app@sha256:abc", "node-1", 2)
+
+	event := <-rec.Events
+	if !strings.Contains(event, ReasonCorruptContent) {
+		t.Errorf("expected event to contain reason %q, got %q", ReasonCorruptContent, event)
+	}
+	if !strings.Contains(event, "node-1") || !strings.Contains(event, "2 missing") {
+		t.Errorf("expected event to contain node and blob count, got %q", event)
+	}
+}
+
+func TestEmitRescheduled(t *testing.T) {
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+
+	pod := testPod()
+	pod.Spec.NodeName = "node-new"
+	emitter.EmitRescheduled(context.Background(), pod, "app@sha256:abc", []string{"node-1", "node-2"})
+
+	event := <-rec.Events
+	if !strings.Contains(event, "Normal "+ReasonRescheduled) {
+		t.Errorf("expected Normal event with reason %q, got %q", ReasonRescheduled, event)
+	}
+	if !strings.Contains(event, "node node-new") || !strings.Contains(event, "[node-1, node-2]") {
+		t.Errorf("expected event to contain the failing and preferred nodes, got %q", event)
+	}
+}
+
+func TestEmitManualRestart(t *testing.T) {
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+
+	pod := testPod()
+	pod.Spec.NodeName = "node-1"
+	emitter.EmitManualRestart(context.Background(), pod, "app@sha256:abc")
+
+	event := <-rec.Events
+	if !strings.Contains(event, "Warning "+ReasonManualRestart) {
+		t.Errorf("expected Warning event with reason %q, got %q", ReasonManualRestart, event)
+	}
+	if !strings.Contains(event, "node-1") || !strings.Contains(event, "Restart it manually") {
+		t.Errorf("expected event to name the node and ask for a manual restart, got %q", event)
+	}
+}
+
+func TestEmit_Template(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.yaml")
+	content := `
+runbooks:
+  "*": https://runbooks.example.com/tote
+events:
+  ImageSalvageable: "{{ .Pod }}: {{ .Image }} on {{ join .Nodes \"+\" }} see {{ .RunbookURL }}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tmpls, err := templates.Load(path, Reasons, nil)
+	if err != nil {
+		t.Fatalf("loading templates: %v", err)
+	}
+
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+	emitter.Templates = tmpls
+
+	emitter.EmitSalvageable(context.Background(), testPod(), "nginx@sha256:abc123", []string{"node-1", "node-2"})
+	event := <-rec.Events
+	if !strings.Contains(event, "test-pod: nginx@sha256:abc123 on node-1+node-2 see https://runbooks.example.com/tote") {
+		t.Errorf("expected templated message, got %q", event)
+	}
+
+	emitter.EmitSalvageFailed(context.Background(), testPod(), "nginx@sha256:abc123", "connection refused")
+	event = <-rec.Events
+	if !strings.Contains(event, "Image salvage failed") {
+		t.Errorf("expected built-in message without a template, got %q", event)
+	}
+}
+
+func TestEmit_AlsoOnWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+	).Build()
+
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+	emitter.Owners = owners.NewResolver(cl, 0)
+
+	pod := testPod()
+	pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", Controller: ptr.To(true)}}
+	emitter.EmitSalvaged(context.Background(), pod, "db@sha256:abc", "node-a", "node-b")
+
+	if podEvent := <-rec.Events; strings.Contains(podEvent, "Pod test-pod:") || !strings.Contains(podEvent, ReasonSalvaged) {
+		t.Errorf("expected pod event first, got %q", podEvent)
+	}
+	select {
+	case event := <-rec.Events:
+		if !strings.Contains(event, ReasonSalvaged) || !strings.Contains(event, "Pod test-pod: Image db@sha256:abc salvaged") {
+			t.Errorf("expected workload event naming the pod, got %q", event)
+		}
+	default:
+		t.Fatal("expected an event on the owning StatefulSet")
+	}
+
+	emitter.EmitSalvaged(context.Background(), testPod(), "db@sha256:abc", "node-a", "node-b")
+	<-rec.Events
+	if len(rec.Events) != 0 {
+		t.Error("expected no workload event for a standalone pod")
+	}
+}
+
+func TestEmitRegistryOutage(t *testing.T) {
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tote-system"}}
+	emitter.EmitRegistryOutage(ns, "registry.example.com", "unavailable", 5)
+
+	event := <-rec.Events
+	if !strings.Contains(event, "Warning "+ReasonRegistryOutage) {
+		t.Errorf("expected Warning event with reason %q, got %q", ReasonRegistryOutage, event)
+	}
+	if !strings.Contains(event, "registry.example.com") || !strings.Contains(event, "5 pods") {
+		t.Errorf("expected event to contain the registry and pod count, got %q", event)
+	}
+}
+
+func TestEmitPullSecretMissing(t *testing.T) {
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+
+	emitter.EmitPullSecretMissing(context.Background(), testPod(), "registry.example.com/app:v1", "registry.example.com", "regcred")
+
+	event := <-rec.Events
+	if want := "Pull secret regcred missing in namespace default"; !strings.Contains(event, "Warning "+ReasonPullSecretMissing) || !strings.Contains(event, want) {
+		t.Errorf("expected Warning %s event containing %q, got %q", ReasonPullSecretMissing, want, event)
+	}
+}
+
+func TestEmitRegistryAuthFailed(t *testing.T) {
+	rec := k8sevents.NewFakeRecorder(10)
+	emitter := NewEmitter(rec)
+
+	emitter.EmitRegistryAuthFailed(context.Background(), testPod(), "registry.example.com/app:v1", "registry.example.com", nil)
+	emitter.EmitRegistryAuthFailed(context.Background(), testPod(), "registry.example.com/app:v1", "registry.example.com", []string{"regcred"})
+
+	for _, want := range []string{"denied the pull of registry.example.com/app:v1.", "from pull secrets [regcred]"} {
+		event := <-rec.Events
+		if !strings.Contains(event, "Warning "+ReasonRegistryAuthFailed) || !strings.Contains(event, want) {
+			t.Errorf("expected Warning %s event containing %q, got %q", ReasonRegistryAuthFailed, want, event)
+		}
+	}
+}
@@ -12,6 +12,7 @@ type Counters struct {
 	SalvageableImages    prometheus.Counter
 	NotActionable        prometheus.Counter
 	CorruptImages        prometheus.Counter
+	CorruptRepairs       *prometheus.CounterVec
 	SalvageAttempts      prometheus.Counter
 	SalvageSuccesses     prometheus.Counter
 	SalvageFailures      prometheus.Counter
@@ -22,6 +23,16 @@ type Counters struct {
 	PushDuration         prometheus.Histogram
 	RegistryResolveTotal *prometheus.CounterVec
 	RegistryResolveDur   prometheus.Histogram
+	NotifyDeliveries     *prometheus.CounterVec
+	NotifyDeadLetters    *prometheus.CounterVec
+	QueueDepth           prometheus.Gauge
+	QueueWait            prometheus.Histogram
+	QueueDeduplicated    prometheus.Counter
+	Preseeds             *prometheus.CounterVec
+	Reschedules          *prometheus.CounterVec
+	Recoveries           *prometheus.CounterVec
+	RegistryOutage       *prometheus.GaugeVec
+	ConfigErrors         *prometheus.CounterVec
 }
 
 // NewCounters creates and registers Prometheus counters with the given registry.
@@ -43,6 +54,10 @@ func NewCounters(reg prometheus.Registerer) *Counters {
 			Name: "tote_corrupt_images_total",
 			Help: "Total number of corrupt image records detected and cleaned.",
 		}),
+		CorruptRepairs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_corrupt_image_repairs_total",
+			Help: "Total corrupt image repair attempts by result.",
+		}, []string{"result"}),
 		SalvageAttempts: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: "tote_salvage_attempts_total",
 			Help: "Total number of image salvage attempts.",
@@ -86,6 +101,47 @@ func NewCounters(reg prometheus.Registerer) *Counters {
 			Help:    "Duration of registry tag resolution operations in seconds.",
 			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 5},
 		}),
+		NotifyDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_notification_deliveries_total",
+			Help: "Total notification delivery attempts by sink and result.",
+		}, []string{"sink", "result"}),
+		NotifyDeadLetters: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_notifications_dead_lettered_total",
+			Help: "Total notifications dropped without delivery by sink and reason.",
+		}, []string{"sink", "reason"}),
+		QueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tote_salvage_queue_depth",
+			Help: "Number of salvages waiting in the queue.",
+		}),
+		QueueWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tote_salvage_queue_wait_seconds",
+			Help:    "Time salvages waited in the queue before starting, in seconds.",
+			Buckets: []float64{0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+		}),
+		QueueDeduplicated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tote_salvage_queue_deduplicated_total",
+			Help: "Total salvages not queued because the same image was already queued or running for the node.",
+		}),
+		Preseeds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_preseeds_total",
+			Help: "Total image transfers onto nodes where unscheduled replicas can land, by result.",
+		}, []string{"result"}),
+		Reschedules: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_reschedules_total",
+			Help: "Total failing pods deleted to steer their replacement onto nodes caching the image, by result.",
+		}, []string{"result"}),
+		Recoveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_recoveries_total",
+			Help: "Total pods restarted after their image was fixed, by recovery strategy and result.",
+		}, []string{"strategy", "result"}),
+		RegistryOutage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tote_registry_outage",
+			Help: "1 while a registry is in an outage: many pods fail to pull from it because it is unreachable, rate limiting or failing TLS.",
+		}, []string{"registry"}),
+		ConfigErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_config_errors_total",
+			Help: "Total pull failures caused by the pod's configuration rather than a missing cache, by failure class.",
+		}, []string{"class"}),
 	}
 
 	reg.MustRegister(
@@ -93,6 +149,7 @@ func NewCounters(reg prometheus.Registerer) *Counters {
 		c.SalvageableImages,
 		c.NotActionable,
 		c.CorruptImages,
+		c.CorruptRepairs,
 		c.SalvageAttempts,
 		c.SalvageSuccesses,
 		c.SalvageFailures,
@@ -103,6 +160,16 @@ func NewCounters(reg prometheus.Registerer) *Counters {
 		c.PushDuration,
 		c.RegistryResolveTotal,
 		c.RegistryResolveDur,
+		c.NotifyDeliveries,
+		c.NotifyDeadLetters,
+		c.QueueDepth,
+		c.QueueWait,
+		c.QueueDeduplicated,
+		c.Preseeds,
+		c.Reschedules,
+		c.Recoveries,
+		c.RegistryOutage,
+		c.ConfigErrors,
 	)
 
 	return c
@@ -128,6 +195,45 @@ func (c *Counters) RecordCorruptImage() {
 	c.CorruptImages.Inc()
 }
 
+// RecordRepair increments the corrupt image repair counter for the given
+// result ("peer", "registry" or "failed").
+func (c *Counters) RecordRepair(result string) {
+	c.CorruptRepairs.WithLabelValues(result).Inc()
+}
+
+// RecordPreseed increments the pre-seed counter for the given result
+// ("success" or "failed").
+func (c *Counters) RecordPreseed(result string) {
+	c.Preseeds.WithLabelValues(result).Inc()
+}
+
+// RecordReschedule increments the reschedule counter for the given result
+// ("success" or "failed").
+func (c *Counters) RecordReschedule(result string) {
+	c.Reschedules.WithLabelValues(result).Inc()
+}
+
+// RecordRecovery increments the recovery counter for the given strategy and
+// result ("success", "blocked", "manual" or "failed").
+func (c *Counters) RecordRecovery(strategy, result string) {
+	c.Recoveries.WithLabelValues(strategy, result).Inc()
+}
+
+// SetRegistryOutage sets whether the registry host is in an outage.
+func (c *Counters) SetRegistryOutage(registry string, active bool) {
+	v := 0.0
+	if active {
+		v = 1
+	}
+	c.RegistryOutage.WithLabelValues(registry).Set(v)
+}
+
+// RecordConfigError increments the config error counter for the given
+// failure class ("invalid-name", "auth" or "not-found").
+func (c *Counters) RecordConfigError(class string) {
+	c.ConfigErrors.WithLabelValues(class).Inc()
+}
+
 // RecordSalvageAttempt increments the salvage attempts counter.
 func (c *Counters) RecordSalvageAttempt() {
 	c.SalvageAttempts.Inc()
@@ -177,3 +283,115 @@ func (c *Counters) RecordRegistryResolve(result string) {
 func (c *Counters) RecordRegistryResolveDuration(d time.Duration) {
 	c.RegistryResolveDur.Observe(d.Seconds())
 }
+
+// RecordNotifyDelivery increments the notification delivery counter for a
+// sink (result: delivered, failed).
+func (c *Counters) RecordNotifyDelivery(sink, result string) {
+	c.NotifyDeliveries.WithLabelValues(sink, result).Inc()
+}
+
+// RecordNotifyDeadLetter increments the dead-lettered notification counter
+// (reason: queue_full, rejected, retries_exhausted).
+func (c *Counters) RecordNotifyDeadLetter(sink, reason string) {
+	c.NotifyDeadLetters.WithLabelValues(sink, reason).Inc()
+}
+
+// SetQueueDepth sets the number of queued salvages.
+func (c *Counters) SetQueueDepth(n int) {
+	c.QueueDepth.Set(float64(n))
+}
+
+// RecordQueueWait observes how long a salvage waited in the queue.
+func (c *Counters) RecordQueueWait(d time.Duration) {
+	c.QueueWait.Observe(d.Seconds())
+}
+
+// RecordQueueDeduplicated increments the deduplicated salvage counter.
+func (c *Counters) RecordQueueDeduplicated() {
+	c.QueueDeduplicated.Inc()
+}
+
+// AgentGauges holds the Prometheus metrics exported by the node agent.
+type AgentGauges struct {
+	CorruptImages    prometheus.Gauge
+	ScanDuration     *prometheus.HistogramVec
+	ActiveExports    prometheus.Gauge
+	RejectedExports  prometheus.Counter
+	TransferBytes    *prometheus.CounterVec
+	ThrottledSeconds *prometheus.CounterVec
+	ChecksumFailures prometheus.Counter
+}
+
+// NewAgentGauges creates and registers the agent metrics with the given registry.
+func NewAgentGauges(reg prometheus.Registerer) *AgentGauges {
+	g := &AgentGauges{
+		CorruptImages: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tote_agent_corrupt_images",
+			Help: "Number of images in the node's containerd store with missing or corrupt content, as of the last scan.",
+		}),
+		ScanDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tote_agent_scan_duration_seconds",
+			Help:    "Duration of node-wide image content scans in seconds.",
+			Buckets: []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600},
+		}, []string{"mode"}),
+		ActiveExports: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tote_agent_active_exports",
+			Help: "Number of exports the agent is serving.",
+		}),
+		RejectedExports: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tote_agent_exports_rejected_total",
+			Help: "Total export requests turned away because the agent was serving its maximum number of exports.",
+		}),
+		TransferBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_agent_transfer_bytes_total",
+			Help: "Total image bytes sent or received by the agent, by direction.",
+		}, []string{"direction"}),
+		ThrottledSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tote_agent_throttled_seconds_total",
+			Help: "Total time transfers were delayed by the agent's bandwidth limit in seconds, by direction.",
+		}, []string{"direction"}),
+		ChecksumFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tote_agent_chunk_checksum_failures_total",
+			Help: "Total transfer streams aborted because a chunk or the whole stream failed SHA-256 verification.",
+		}),
+	}
+
+	reg.MustRegister(g.CorruptImages, g.ScanDuration, g.ActiveExports, g.RejectedExports, g.TransferBytes, g.ThrottledSeconds, g.ChecksumFailures)
+
+	return g
+}
+
+// SetCorruptImages sets the number of corrupt images found by the last scan.
+func (g *AgentGauges) SetCorruptImages(n int) {
+	g.CorruptImages.Set(float64(n))
+}
+
+// RecordScanDuration observes scan duration for the given mode ("presence" or "rehash").
+func (g *AgentGauges) RecordScanDuration(mode string, d time.Duration) {
+	g.ScanDuration.WithLabelValues(mode).Observe(d.Seconds())
+}
+
+// SetActiveExports sets the number of exports being served.
+func (g *AgentGauges) SetActiveExports(n int) {
+	g.ActiveExports.Set(float64(n))
+}
+
+// RecordRejectedExport increments the rejected export counter.
+func (g *AgentGauges) RecordRejectedExport() {
+	g.RejectedExports.Inc()
+}
+
+// RecordTransferBytes adds n bytes for the direction ("export" or "import").
+func (g *AgentGauges) RecordTransferBytes(direction string, n int) {
+	g.TransferBytes.WithLabelValues(direction).Add(float64(n))
+}
+
+// RecordThrottled adds time a transfer in the direction was delayed.
+func (g *AgentGauges) RecordThrottled(direction string, d time.Duration) {
+	g.ThrottledSeconds.WithLabelValues(direction).Add(d.Seconds())
+}
+
+// RecordChecksumFailure increments the checksum failure counter.
+func (g *AgentGauges) RecordChecksumFailure() {
+	g.ChecksumFailures.Inc()
+}
@@ -103,3 +103,48 @@ func TestRecordPushDuration(t *testing.T) {
 		t.Errorf("expected 1 collector, got %d", count)
 	}
 }
+
+func TestRecordNotify(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := NewCounters(reg)
+	c.RecordNotifyDelivery("chat", "delivered")
+	c.RecordNotifyDeadLetter("chat", "retries_exhausted")
+	c.RecordNotifyDeadLetter("chat", "retries_exhausted")
+	if val := testutil.ToFloat64(c.NotifyDeliveries.WithLabelValues("chat", "delivered")); val != 1 {
+		t.Errorf("expected 1 delivery, got %f", val)
+	}
+	if val := testutil.ToFloat64(c.NotifyDeadLetters.WithLabelValues("chat", "retries_exhausted")); val != 2 {
+		t.Errorf("expected 2 dead letters, got %f", val)
+	}
+}
+
+func TestRecordQueue(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := NewCounters(reg)
+	c.SetQueueDepth(4)
+	c.RecordQueueDeduplicated()
+	c.RecordQueueWait(3 * time.Second)
+	if val := testutil.ToFloat64(c.QueueDepth); val != 4 {
+		t.Errorf("expected depth 4, got %f", val)
+	}
+	if val := testutil.ToFloat64(c.QueueDeduplicated); val != 1 {
+		t.Errorf("expected 1 deduplicated, got %f", val)
+	}
+	if count := testutil.CollectAndCount(c.QueueWait); count != 1 {
+		t.Errorf("expected 1 collector, got %d", count)
+	}
+}
+
+func TestAgentGauges(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	g := NewAgentGauges(reg)
+	g.SetCorruptImages(3)
+	g.SetCorruptImages(1)
+	if val := testutil.ToFloat64(g.CorruptImages); val != 1 {
+		t.Errorf("expected 1, got %f", val)
+	}
+	g.RecordScanDuration("presence", 2*time.Second)
+	if count := testutil.CollectAndCount(g.ScanDuration); count != 1 {
+		t.Errorf("expected 1 series, got %d", count)
+	}
+}
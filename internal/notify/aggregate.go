@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by Notify when the global per-minute cap has
+// been reached; the event is dead-lettered.
+var ErrRateLimited = errors.New("notification rate limit reached")
+
+// aggKey identifies events about the same image failing across the pods of
+// one workload.
+type aggKey struct {
+	eventType, namespace, workloadKind, workloadName, image string
+}
+
+type aggregate struct {
+	evt  Event
+	pods map[string]bool
+}
+
+// aggregator holds workload events for a window and releases one event per
+// key with the number of distinct pods seen.
+type aggregator struct {
+	window time.Duration
+	flush  func(Event)
+
+	mu      sync.Mutex
+	pending map[aggKey]*aggregate
+}
+
+// EnableAggregation coalesces events for the same (namespace, workload,
+// image) and event type arriving within window into a single notification
+// carrying PodCount. The first event is held for window before it is sent.
+// Events without a workload are sent immediately.
+func (n *Notifier) EnableAggregation(window time.Duration) {
+	n.agg = &aggregator{
+		window:  window,
+		flush:   func(evt Event) { _ = n.dispatch(context.Background(), evt) },
+		pending: make(map[aggKey]*aggregate),
+	}
+}
+
+// EnableRateLimit caps the notifications dispatched per minute across all
+// sinks; the excess is dead-lettered.
+func (n *Notifier) EnableRateLimit(perMinute int) {
+	n.limit = &rateLimiter{max: perMinute, period: time.Minute}
+}
+
+// add buffers evt and reports whether it was taken.
+func (a *aggregator) add(evt Event) bool {
+	if evt.WorkloadName == "" {
+		return false
+	}
+	image := evt.Digest
+	if image == "" {
+		image = evt.ImageRef
+	}
+	key := aggKey{evt.Type, evt.Namespace, evt.WorkloadKind, evt.WorkloadName, image}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if agg, ok := a.pending[key]; ok {
+		agg.pods[evt.PodName] = true
+		return true
+	}
+	a.pending[key] = &aggregate{evt: evt, pods: map[string]bool{evt.PodName: true}}
+	time.AfterFunc(a.window, func() { a.release(key) })
+	return true
+}
+
+func (a *aggregator) release(key aggKey) {
+	a.mu.Lock()
+	agg := a.pending[key]
+	delete(a.pending, key)
+	a.mu.Unlock()
+	if agg == nil {
+		return
+	}
+	agg.evt.PodCount = len(agg.pods)
+	a.flush(agg.evt)
+}
+
+// rateLimiter allows max events per fixed period.
+type rateLimiter struct {
+	max    int
+	period time.Duration
+
+	mu    sync.Mutex
+	start time.Time
+	count int
+}
+
+func (l *rateLimiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.start) >= l.period {
+		l.start, l.count = now, 0
+	}
+	if l.count >= l.max {
+		return false
+	}
+	l.count++
+	return true
+}
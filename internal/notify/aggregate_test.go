@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+// syncSink is a recordingSink safe for the aggregator's timer goroutine.
+type syncSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *syncSink) Send(_ context.Context, evt Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, evt)
+	return nil
+}
+
+func (s *syncSink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+func workloadEvent(pod string) Event {
+	return Event{
+		Type:         EventDetected,
+		Namespace:    "default",
+		PodName:      pod,
+		WorkloadKind: "Deployment",
+		WorkloadName: "api",
+		ImageRef:     "registry.example.com/api:v1",
+	}
+}
+
+func TestAggregation_CoalescesWorkloadPods(t *testing.T) {
+	sink := &syncSink{}
+	n := &Notifier{Routes: []Route{{Name: "chat", Sink: sink}}}
+	n.EnableAggregation(20 * time.Millisecond)
+
+	for i := 0; i < 50; i++ {
+		_ = n.Notify(context.Background(), workloadEvent(fmt.Sprintf("api-%d", i%10)))
+	}
+	// A different workload is aggregated separately.
+	other := workloadEvent("worker-0")
+	other.WorkloadName = "worker"
+	_ = n.Notify(context.Background(), other)
+	// Events without a workload are not held back.
+	_ = n.Notify(context.Background(), Event{Type: EventDetected, Namespace: "default", PodName: "standalone"})
+
+	if got := sink.Events(); len(got) != 1 || got[0].PodName != "standalone" {
+		t.Fatalf("expected only the standalone event before the window closes, got %v", got)
+	}
+
+	waitFor(t, func() bool { return len(sink.Events()) == 3 })
+	for _, evt := range sink.Events()[1:] {
+		switch evt.WorkloadName {
+		case "api":
+			if evt.PodCount != 10 {
+				t.Errorf("expected 10 distinct pods for api, got %d", evt.PodCount)
+			}
+		case "worker":
+			if evt.PodCount != 1 {
+				t.Errorf("expected 1 pod for worker, got %d", evt.PodCount)
+			}
+		}
+	}
+}
+
+func TestRateLimit_CapsPerMinute(t *testing.T) {
+	sink := &syncSink{}
+	m := metrics.NewCounters(prometheus.NewRegistry())
+	n := &Notifier{Routes: []Route{{Name: "chat", Sink: sink}}, Metrics: m}
+	n.EnableRateLimit(3)
+
+	var limited int
+	for i := 0; i < 5; i++ {
+		if err := n.Notify(context.Background(), Event{Type: EventSalvaged}); errors.Is(err, ErrRateLimited) {
+			limited++
+		}
+	}
+	if len(sink.Events()) != 3 || limited != 2 {
+		t.Errorf("expected 3 sent and 2 limited, got %d sent, %d limited", len(sink.Events()), limited)
+	}
+	if val := testutil.ToFloat64(m.NotifyDeadLetters.WithLabelValues("*", "rate_limited")); val != 2 {
+		t.Errorf("expected 2 rate-limited dead letters, got %f", val)
+	}
+}
+
+func TestRateLimiter_ResetsEachPeriod(t *testing.T) {
+	l := &rateLimiter{max: 1, period: time.Minute}
+	now := time.Now()
+	if !l.allow(now) || l.allow(now.Add(time.Second)) {
+		t.Fatal("expected one event per period")
+	}
+	if !l.allow(now.Add(time.Minute)) {
+		t.Error("expected the cap to reset after a period")
+	}
+}
+
+func TestSummary_Aggregated(t *testing.T) {
+	evt := workloadEvent("api-0")
+	evt.PodCount = 12
+	want := "Image pull failure detected for 12 pods of Deployment default/api (registry.example.com/api:v1)"
+	if got := Summary(evt); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CloudEvents content modes.
+const (
+	CloudEventsStructured = "structured"
+	CloudEventsBinary     = "binary"
+)
+
+const cloudEventsSpecVersion = "1.0"
+
+// cloudEventTypes maps event types to their stable CloudEvents type.
+var cloudEventTypes = map[string]string{
+	EventDetected:      "dev.tote.image.detected",
+	EventSalvaged:      "dev.tote.image.salvaged",
+	EventSalvageFailed: "dev.tote.image.salvage_failed",
+	EventPushed:        "dev.tote.image.pushed",
+	EventPushFailed:    "dev.tote.image.push_failed",
+
+	EventRegistryOutage:    "dev.tote.registry.outage",
+	EventRegistryRecovered: "dev.tote.registry.recovered",
+}
+
+// CloudEventType returns the CloudEvents type for an event type.
+func CloudEventType(eventType string) string {
+	if t, ok := cloudEventTypes[eventType]; ok {
+		return t
+	}
+	return "dev.tote.image." + eventType
+}
+
+// CloudEventsSink posts the Event as the data of a CloudEvents 1.0 event,
+// in structured (application/cloudevents+json) or binary (ce-* headers)
+// HTTP content mode.
+type CloudEventsSink struct {
+	endpoint
+	Source string // CloudEvents source, identifies this controller
+	Binary bool
+}
+
+// NewCloudEventsSink creates a CloudEvents sink. mode is
+// CloudEventsStructured or CloudEventsBinary.
+func NewCloudEventsSink(url, source, mode string) *CloudEventsSink {
+	return &CloudEventsSink{
+		endpoint: newEndpoint(url),
+		Source:   source,
+		Binary:   mode == CloudEventsBinary,
+	}
+}
+
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject,omitempty"`
+	Time            string `json:"time,omitempty"`
+	DataContentType string `json:"datacontenttype"`
+	Data            Event  `json:"data"`
+}
+
+func (s *CloudEventsSink) Send(ctx context.Context, evt Event) error {
+	id := evt.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	ce := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              id,
+		Source:          s.Source,
+		Type:            CloudEventType(evt.Type),
+		Subject:         cloudEventSubject(evt),
+		Time:            evt.Timestamp,
+		DataContentType: "application/json",
+		Data:            evt,
+	}
+
+	if !s.Binary {
+		return s.post(ctx, ce, http.Header{
+			"Content-Type": {"application/cloudevents+json; charset=utf-8"},
+		})
+	}
+	header := http.Header{}
+	header.Set("Content-Type", ce.DataContentType)
+	header.Set("ce-specversion", ce.SpecVersion)
+	header.Set("ce-id", ce.ID)
+	header.Set("ce-source", ce.Source)
+	header.Set("ce-type", ce.Type)
+	if ce.Subject != "" {
+		header.Set("ce-subject", ce.Subject)
+	}
+	if ce.Time != "" {
+		header.Set("ce-time", ce.Time)
+	}
+	return s.post(ctx, evt, header)
+}
+
+// cloudEventSubject identifies the pod or registry the event is about.
+func cloudEventSubject(evt Event) string {
+	if evt.Registry != "" && evt.PodName == "" {
+		return evt.Registry
+	}
+	if evt.PodName == "" {
+		return ""
+	}
+	return strings.TrimPrefix(evt.Namespace+"/"+evt.PodName, "/")
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCloudEventsSink_Structured(t *testing.T) {
+	var (
+		contentType string
+		ce          cloudEvent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&ce); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	evt := failedEvent
+	evt.Timestamp = "2026-01-02T03:04:05Z"
+	sink := NewCloudEventsSink(srv.URL, "/clusters/prod/tote", CloudEventsStructured)
+	if err := sink.Send(context.Background(), evt); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if contentType != "application/cloudevents+json; charset=utf-8" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	if ce.SpecVersion != "1.0" || ce.ID == "" {
+		t.Errorf("unexpected envelope %+v", ce)
+	}
+	if ce.Type != "dev.tote.image.salvage_failed" || ce.Source != "/clusters/prod/tote" || ce.Subject != "default/app-1" {
+		t.Errorf("unexpected attributes type=%q source=%q subject=%q", ce.Type, ce.Source, ce.Subject)
+	}
+	if ce.Time != evt.Timestamp || ce.Data.Digest != "sha256:abc" {
+		t.Errorf("expected event as data, got %+v", ce)
+	}
+}
+
+func TestCloudEventsSink_Binary(t *testing.T) {
+	var (
+		header http.Header
+		data   Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sink := NewCloudEventsSink(srv.URL, "/tote/controller", CloudEventsBinary)
+	if err := sink.Send(context.Background(), Event{Type: EventSalvaged, Namespace: "default", PodName: "app-1"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected content type %q", header.Get("Content-Type"))
+	}
+	if header.Get("Ce-Specversion") != "1.0" || header.Get("Ce-Id") == "" {
+		t.Errorf("missing required ce headers: %v", header)
+	}
+	if header.Get("Ce-Type") != "dev.tote.image.salvaged" || header.Get("Ce-Subject") != "default/app-1" {
+		t.Errorf("unexpected ce headers: %v", header)
+	}
+	if header.Get("Ce-Time") != "" {
+		t.Error("expected ce-time to be omitted without a timestamp")
+	}
+	if data.Type != EventSalvaged {
+		t.Errorf("expected event as body, got %+v", data)
+	}
+}
+
+func TestCloudEventType(t *testing.T) {
+	if got := CloudEventType(EventDetected); got != "dev.tote.image.detected" {
+		t.Errorf("unexpected type %q", got)
+	}
+	if got := CloudEventType("custom"); got != "dev.tote.image.custom" {
+		t.Errorf("unexpected fallback type %q", got)
+	}
+}
+
+func TestCloudEventsSink_RetryKeepsID(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		ids []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, r.Header.Get("Ce-Id"))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Routes: []Route{{Name: "ce", Sink: NewCloudEventsSink(srv.URL, "/tote/controller", CloudEventsBinary)}}}
+	startQueue(t, n, QueueOptions{Size: 10, Workers: 1, MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	if err := n.Notify(context.Background(), Event{Type: EventSalvaged}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(ids) == 2
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("expected the retry to reuse the event id, got %q", ids)
+	}
+}
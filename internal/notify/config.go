@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Sink types accepted in the config file.
+const (
+	SinkWebhook   = "webhook"
+	SinkSlack     = "slack"
+	SinkTeams     = "teams"
+	SinkPagerDuty = "pagerduty"
+)
+
+// Payload formats for webhook sinks.
+const (
+	FormatJSON        = "json"
+	FormatCloudEvents = "cloudevents"
+)
+
+// DefaultCloudEventsSource is the CloudEvents source when none is configured.
+const DefaultCloudEventsSource = "/tote/controller"
+
+// Config is the notification sink configuration file (--notify-config).
+type Config struct {
+	// Source identifies this controller in CloudEvents, e.g.
+	// "/clusters/prod-eu/tote". Sinks may override it.
+	Source string       `json:"source,omitempty"`
+	Sinks  []SinkConfig `json:"sinks"`
+}
+
+// SinkConfig configures one sink and the events routed to it.
+type SinkConfig struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	URL         string            `json:"url,omitempty"`
+	RoutingKey  string            `json:"routingKey,omitempty"`  // pagerduty only
+	Events      []string          `json:"events,omitempty"`      // empty = all
+	MinSeverity string            `json:"minSeverity,omitempty"` // default info
+	Severity    map[string]string `json:"severity,omitempty"`    // event type -> severity
+	Format      string            `json:"format,omitempty"`      // webhook only: json or cloudevents
+	Mode        string            `json:"mode,omitempty"`        // cloudevents only: structured or binary
+	Source      string            `json:"source,omitempty"`      // cloudevents only
+}
+
+// LoadConfig reads a YAML or JSON sink configuration file. Sinks are
+// validated by Routes.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading notify config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing notify config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Routes builds a Route for every configured sink.
+func (c *Config) Routes() ([]Route, error) {
+	routes := make([]Route, 0, len(c.Sinks))
+	seen := make(map[string]bool)
+	for i, sc := range c.Sinks {
+		if sc.Name == "" {
+			return nil, fmt.Errorf("notify sink %d: name is required", i)
+		}
+		if seen[sc.Name] {
+			return nil, fmt.Errorf("notify sink %s: duplicate name", sc.Name)
+		}
+		seen[sc.Name] = true
+
+		if sc.Source == "" {
+			sc.Source = c.Source
+		}
+		route, err := sc.route()
+		if err != nil {
+			return nil, fmt.Errorf("notify sink %s: %w", sc.Name, err)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func (sc SinkConfig) route() (Route, error) {
+	route := Route{Name: sc.Name, Events: eventSet(sc.Events)}
+
+	if sc.Type != SinkPagerDuty && sc.URL == "" {
+		return route, fmt.Errorf("url is required for %s sinks", sc.Type)
+	}
+	switch sc.Type {
+	case SinkWebhook:
+		sink, err := sc.webhookSink()
+		if err != nil {
+			return route, err
+		}
+		route.Sink = sink
+	case SinkSlack:
+		route.Sink = NewSlackSink(sc.URL)
+	case SinkTeams:
+		route.Sink = NewTeamsSink(sc.URL)
+	case SinkPagerDuty:
+		if sc.RoutingKey == "" {
+			return route, fmt.Errorf("routingKey is required for pagerduty sinks")
+		}
+		route.Sink = NewPagerDutySink(sc.URL, sc.RoutingKey)
+	default:
+		return route, fmt.Errorf("unknown type %q (want webhook, slack, teams or pagerduty)", sc.Type)
+	}
+
+	if sc.Type != SinkWebhook && (sc.Format != "" || sc.Mode != "") {
+		return route, fmt.Errorf("format and mode only apply to webhook sinks")
+	}
+
+	for _, e := range sc.Events {
+		if _, ok := DefaultSeverities[e]; !ok {
+			return route, fmt.Errorf("unknown event type %q", e)
+		}
+	}
+	if sc.MinSeverity != "" {
+		sev, err := ParseSeverity(sc.MinSeverity)
+		if err != nil {
+			return route, err
+		}
+		route.MinSeverity = sev
+	}
+	if len(sc.Severity) > 0 {
+		route.Severities = make(map[string]Severity, len(sc.Severity))
+		for e, s := range sc.Severity {
+			if _, ok := DefaultSeverities[e]; !ok {
+				return route, fmt.Errorf("unknown event type %q in severity", e)
+			}
+			sev, err := ParseSeverity(s)
+			if err != nil {
+				return route, err
+			}
+			route.Severities[e] = sev
+		}
+	}
+	return route, nil
+}
+
+func (sc SinkConfig) webhookSink() (Sink, error) {
+	switch sc.Format {
+	case "", FormatJSON:
+		if sc.Mode != "" {
+			return nil, fmt.Errorf("mode only applies to the cloudevents format")
+		}
+		return NewWebhookSink(sc.URL), nil
+	case FormatCloudEvents:
+		mode := sc.Mode
+		if mode == "" {
+			mode = CloudEventsStructured
+		}
+		if mode != CloudEventsStructured && mode != CloudEventsBinary {
+			return nil, fmt.Errorf("unknown cloudevents mode %q (want structured or binary)", sc.Mode)
+		}
+		source := sc.Source
+		if source == "" {
+			source = DefaultCloudEventsSource
+		}
+		return NewCloudEventsSink(sc.URL, source, mode), nil
+	}
+	return nil, fmt.Errorf("unknown format %q (want json or cloudevents)", sc.Format)
+}
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sinks.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, `
+sinks:
+  - name: oncall
+    type: pagerduty
+    routingKey: R0UT1NG
+    minSeverity: critical
+  - name: chat
+    type: slack
+    url: https://hooks.slack.com/services/T/B/X
+    events: [salvaged, salvage_failed]
+    severity:
+      salvaged: warning
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	routes, err := cfg.Routes()
+	if err != nil {
+		t.Fatalf("Routes: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+
+	pd, ok := routes[0].Sink.(*PagerDutySink)
+	if !ok || pd.URL != DefaultPagerDutyURL || routes[0].MinSeverity != SeverityCritical {
+		t.Errorf("unexpected pagerduty route %+v", routes[0])
+	}
+	if _, ok := routes[1].Sink.(*SlackSink); !ok {
+		t.Errorf("expected slack sink, got %T", routes[1].Sink)
+	}
+	if !routes[1].Events[EventSalvaged] || routes[1].Events[EventDetected] {
+		t.Errorf("unexpected event filter %v", routes[1].Events)
+	}
+	if routes[1].severity(EventSalvaged) != SeverityWarning {
+		t.Error("expected severity override to apply")
+	}
+}
+
+func TestConfigRoutes_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		sink SinkConfig
+		want string
+	}{
+		{"missing name", SinkConfig{Type: SinkSlack, URL: "http://x"}, "name is required"},
+		{"unknown type", SinkConfig{Name: "a", Type: "email", URL: "http://x"}, "unknown type"},
+		{"missing url", SinkConfig{Name: "a", Type: SinkTeams}, "url is required"},
+		{"missing routing key", SinkConfig{Name: "a", Type: SinkPagerDuty}, "routingKey is required"},
+		{"unknown event", SinkConfig{Name: "a", Type: SinkSlack, URL: "http://x", Events: []string{"salvage"}}, "unknown event type"},
+		{"bad severity", SinkConfig{Name: "a", Type: SinkSlack, URL: "http://x", MinSeverity: "page"}, "unknown severity"},
+		{"bad format", SinkConfig{Name: "a", Type: SinkWebhook, URL: "http://x", Format: "xml"}, "unknown format"},
+		{"bad mode", SinkConfig{Name: "a", Type: SinkWebhook, URL: "http://x", Format: FormatCloudEvents, Mode: "batch"}, "unknown cloudevents mode"},
+		{"mode without cloudevents", SinkConfig{Name: "a", Type: SinkWebhook, URL: "http://x", Mode: CloudEventsBinary}, "mode only applies"},
+		{"format on slack", SinkConfig{Name: "a", Type: SinkSlack, URL: "http://x", Format: FormatCloudEvents}, "only apply to webhook"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sinks: []SinkConfig{tt.sink}}
+			_, err := cfg.Routes()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	dup := &Config{Sinks: []SinkConfig{
+		{Name: "a", Type: SinkSlack, URL: "http://x"},
+		{Name: "a", Type: SinkSlack, URL: "http://y"},
+	}}
+	if _, err := dup.Routes(); err == nil {
+		t.Error("expected duplicate name error")
+	}
+}
+
+func TestLoadConfig_UnknownField(t *testing.T) {
+	path := writeConfig(t, "sinks:\n  - name: a\n    type: slack\n    webhook: http://x\n")
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expected unknown field to be rejected")
+	}
+}
+
+func TestConfigRoutes_CloudEvents(t *testing.T) {
+	cfg := &Config{
+		Source: "/clusters/prod/tote",
+		Sinks: []SinkConfig{
+			{Name: "bus", Type: SinkWebhook, URL: "http://broker", Format: FormatCloudEvents, Mode: CloudEventsBinary},
+			{Name: "argo", Type: SinkWebhook, URL: "http://argo", Format: FormatCloudEvents, Source: "/custom"},
+		},
+	}
+	routes, err := cfg.Routes()
+	if err != nil {
+		t.Fatalf("Routes: %v", err)
+	}
+
+	bus, ok := routes[0].Sink.(*CloudEventsSink)
+	if !ok || !bus.Binary || bus.Source != "/clusters/prod/tote" {
+		t.Errorf("expected binary sink with config source, got %+v", routes[0].Sink)
+	}
+	argo, ok := routes[1].Sink.(*CloudEventsSink)
+	if !ok || argo.Binary || argo.Source != "/custom" {
+		t.Errorf("expected structured sink with own source, got %+v", routes[1].Sink)
+	}
+
+	noSource := &Config{Sinks: []SinkConfig{{Name: "a", Type: SinkWebhook, URL: "http://x", Format: FormatCloudEvents}}}
+	routes, _ = noSource.Routes()
+	if s := routes[0].Sink.(*CloudEventsSink); s.Source != DefaultCloudEventsSource {
+		t.Errorf("expected default source, got %q", s.Source)
+	}
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, formatted
+// as "sha256=<hex>", when a signing key is configured.
+const SignatureHeader = "X-Tote-Signature"
+
+// endpoint is the HTTP destination shared by all sinks.
+type endpoint struct {
+	URL        string
+	HTTPClient *http.Client
+	SigningKey []byte // nil = unsigned
+}
+
+func newEndpoint(url string) endpoint {
+	return endpoint{URL: url, HTTPClient: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (e *endpoint) setSigningKey(key []byte) {
+	e.SigningKey = key
+}
+
+func (e *endpoint) postJSON(ctx context.Context, payload any) error {
+	return e.post(ctx, payload, http.Header{"Content-Type": {"application/json"}})
+}
+
+// post sends payload as a JSON body with the given headers, which must
+// include Content-Type.
+func (e *endpoint) post(ctx context.Context, payload any, header http.Header) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header = header.Clone()
+	if len(e.SigningKey) > 0 {
+		req.Header.Set(SignatureHeader, Sign(e.SigningKey, body))
+	}
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending webhook: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return &StatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the X-Tote-Signature value for body.
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// StatusError is returned when a receiver answers with an HTTP error.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook returned status %d", e.Code)
+}
+
+// retryable reports whether a failed delivery may succeed later: network
+// errors, timeouts, 408, 429 and 5xx are retried; other 4xx are not.
+func retryable(err error) bool {
+	var se *StatusError
+	if !errors.As(err, &se) {
+		return true
+	}
+	return se.Code == http.StatusRequestTimeout || se.Code == http.StatusTooManyRequests || se.Code >= 500
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEndpoint_SignsBody(t *testing.T) {
+	var (
+		signature string
+		body      []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Routes: []Route{{Name: "hook", Sink: NewWebhookSink(srv.URL)}}}
+	n.SetSigningKey([]byte("s3cret"))
+	if err := n.Notify(context.Background(), Event{Type: EventSalvaged}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign([]byte("s3cret"), body))) {
+		t.Errorf("signature %q does not match body", signature)
+	}
+	if len(signature) != len("sha256=")+64 {
+		t.Errorf("unexpected signature format %q", signature)
+	}
+}
+
+func TestEndpoint_UnsignedWithoutKey(t *testing.T) {
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if err := NewSlackSink(srv.URL).Send(context.Background(), Event{Type: EventSalvaged}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if signature != "" {
+		t.Errorf("expected no signature, got %q", signature)
+	}
+}
+
+func TestEndpoint_StatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookSink(srv.URL).Send(context.Background(), Event{Type: EventSalvaged})
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected StatusError 429, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Incident is the context shared by every event about one failing
+// container, from detection through salvage and backup push.
+type Incident struct {
+	CorrelationID string
+	WorkloadKind  string
+	WorkloadName  string
+	Container     string
+	Reason        string
+	Message       string
+}
+
+type incidentKey struct{}
+
+// WithIncident returns a context carrying inc; Notify copies its fields
+// into every event sent with that context.
+func WithIncident(ctx context.Context, inc Incident) context.Context {
+	return context.WithValue(ctx, incidentKey{}, inc)
+}
+
+// IncidentFrom returns the incident carried by ctx, if any.
+func IncidentFrom(ctx context.Context) (Incident, bool) {
+	inc, ok := ctx.Value(incidentKey{}).(Incident)
+	return inc, ok
+}
+
+// CorrelationID derives a stable incident ID from the pod UID and container
+// name, so repeated detections of the same failure and the resulting
+// salvage share one ID.
+func CorrelationID(podUID, container string) string {
+	sum := sha256.Sum256([]byte(podUID + "/" + container))
+	return hex.EncodeToString(sum[:8])
+}
+
+// apply fills unset event fields from the incident.
+func (inc Incident) apply(evt *Event) {
+	fill := func(dst *string, v string) {
+		if *dst == "" {
+			*dst = v
+		}
+	}
+	fill(&evt.CorrelationID, inc.CorrelationID)
+	fill(&evt.WorkloadKind, inc.WorkloadKind)
+	fill(&evt.WorkloadName, inc.WorkloadName)
+	fill(&evt.Container, inc.Container)
+	fill(&evt.Reason, inc.Reason)
+	fill(&evt.Message, inc.Message)
+}
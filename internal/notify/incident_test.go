@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNotify_AppliesIncidentAndCluster(t *testing.T) {
+	sink := &recordingSink{}
+	n := &Notifier{Routes: []Route{{Name: "hook", Sink: sink}}, Cluster: "prod-eu"}
+
+	ctx := WithIncident(context.Background(), Incident{
+		CorrelationID: "abc123",
+		WorkloadKind:  "Deployment",
+		WorkloadName:  "api",
+		Container:     "app",
+		Reason:        "ImagePullBackOff",
+	})
+	_ = n.Notify(ctx, Event{Type: EventSalvaged, Container: "explicit"})
+
+	got := sink.events[0]
+	if got.Cluster != "prod-eu" || got.CorrelationID != "abc123" || got.WorkloadKind != "Deployment" || got.WorkloadName != "api" {
+		t.Errorf("expected incident context, got %+v", got)
+	}
+	if got.Container != "explicit" {
+		t.Errorf("expected explicit event fields to win, got %q", got.Container)
+	}
+	if got.Reason != "ImagePullBackOff" {
+		t.Errorf("expected reason from incident, got %q", got.Reason)
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	id := CorrelationID("uid-1", "app")
+	if id != CorrelationID("uid-1", "app") {
+		t.Error("expected correlation ID to be stable")
+	}
+	if id == CorrelationID("uid-1", "sidecar") || id == CorrelationID("uid-2", "app") {
+		t.Error("expected different incidents to get different IDs")
+	}
+	if len(id) != 16 {
+		t.Errorf("expected 16 hex chars, got %q", id)
+	}
+}
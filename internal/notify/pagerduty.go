@@ -0,0 +1,77 @@
+package notify
+
+import "context"
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 enqueue endpoint.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySink triggers PagerDuty incidents through the Events API v2.
+type PagerDutySink struct {
+	endpoint
+	RoutingKey string
+}
+
+// NewPagerDutySink creates a sink for the given integration routing key.
+// An empty url uses DefaultPagerDutyURL.
+func NewPagerDutySink(url, routingKey string) *PagerDutySink {
+	if url == "" {
+		url = DefaultPagerDutyURL
+	}
+	return &PagerDutySink{endpoint: newEndpoint(url), RoutingKey: routingKey}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (s *PagerDutySink) Send(ctx context.Context, evt Event) error {
+	source := evt.TargetNode
+	if source == "" {
+		source = "tote"
+	}
+	dedupKey := "tote/" + evt.Namespace + "/" + evt.PodName + "/" + evt.Digest
+	if evt.CorrelationID != "" {
+		dedupKey = "tote/" + evt.CorrelationID
+	}
+	// A registry recovery resolves the incident its outage triggered.
+	action := "trigger"
+	if evt.Type == EventRegistryRecovered {
+		action = "resolve"
+	}
+	component := evt.Namespace + "/" + evt.PodName
+	if evt.PodName == "" && evt.Registry != "" {
+		component = evt.Registry
+	}
+	details := make(map[string]string)
+	for _, f := range facts(evt) {
+		details[f.Name] = f.Value
+	}
+	return s.postJSON(ctx, pagerDutyEvent{
+		RoutingKey:  s.RoutingKey,
+		EventAction: action,
+		// Repeated events for the same incident collapse into one.
+		DedupKey: dedupKey,
+		Payload: pagerDutyPayload{
+			Summary:       Summary(evt),
+			Source:        source,
+			Severity:      evt.Severity,
+			Timestamp:     evt.Timestamp,
+			Component:     component,
+			Class:         evt.Type,
+			CustomDetails: details,
+		},
+	})
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrQueueFull is returned by Notify when the delivery queue has no room;
+// the event is dead-lettered.
+var ErrQueueFull = errors.New("notification queue full")
+
+// QueueOptions configures asynchronous delivery.
+type QueueOptions struct {
+	Size        int           // events waiting for delivery
+	Workers     int           // concurrent deliveries
+	MaxAttempts int           // tries per event before it is dead-lettered
+	Backoff     time.Duration // delay before the first retry, doubled per retry
+	MaxBackoff  time.Duration
+}
+
+type delivery struct {
+	route *Route
+	evt   Event
+}
+
+type queue struct {
+	opts QueueOptions
+	ch   chan delivery
+}
+
+// EnableQueue switches the Notifier to asynchronous delivery: Notify only
+// enqueues, and the workers run by Start deliver with exponential-backoff
+// retries. Must be called before Notify is first used.
+func (n *Notifier) EnableQueue(opts QueueOptions) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = opts.Backoff
+	}
+	n.queue = &queue{opts: opts, ch: make(chan delivery, opts.Size)}
+}
+
+// NeedLeaderElection returns false: workers only drain what this replica
+// enqueued, and only the leader reconciles.
+func (n *Notifier) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the delivery workers until ctx is cancelled. Events still
+// queued at shutdown are lost. It returns immediately without a queue.
+func (n *Notifier) Start(ctx context.Context) error {
+	if n.queue == nil {
+		return nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n.queue.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-n.queue.ch:
+					n.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (n *Notifier) enqueue(d delivery) error {
+	select {
+	case n.queue.ch <- d:
+		return nil
+	default:
+		n.recordDeadLetter(d.route.Name, "queue_full")
+		return ErrQueueFull
+	}
+}
+
+// deliver sends one event to its sink, retrying transient failures until
+// MaxAttempts is reached.
+func (n *Notifier) deliver(ctx context.Context, d delivery) {
+	logger := log.FromContext(ctx).WithName("notify")
+	backoff := n.queue.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := d.route.Sink.Send(ctx, d.evt)
+		if err == nil {
+			n.recordDelivery(d.route.Name, "delivered")
+			return
+		}
+		n.recordDelivery(d.route.Name, "failed")
+
+		if !retryable(err) {
+			logger.Error(err, "notification rejected", "sink", d.route.Name, "type", d.evt.Type)
+			n.recordDeadLetter(d.route.Name, "rejected")
+			return
+		}
+		if attempt >= n.queue.opts.MaxAttempts {
+			logger.Error(err, "notification dead-lettered", "sink", d.route.Name, "type", d.evt.Type, "attempts", attempt)
+			n.recordDeadLetter(d.route.Name, "retries_exhausted")
+			return
+		}
+		logger.V(1).Info("notification failed, retrying", "sink", d.route.Name, "attempt", attempt, "backoff", backoff, "error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, n.queue.opts.MaxBackoff)
+	}
+}
+
+func (n *Notifier) recordDelivery(sink, result string) {
+	if n.Metrics != nil {
+		n.Metrics.RecordNotifyDelivery(sink, result)
+	}
+}
+
+func (n *Notifier) recordDeadLetter(sink, reason string) {
+	if n.Metrics != nil {
+		n.Metrics.RecordNotifyDeadLetter(sink, reason)
+	}
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+// flakySink fails with errs in order, then succeeds.
+type flakySink struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+	done  chan struct{}
+}
+
+func (f *flakySink) Send(_ context.Context, _ Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return err
+	}
+	if f.done != nil {
+		close(f.done)
+	}
+	return nil
+}
+
+func (f *flakySink) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func startQueue(t *testing.T, n *Notifier, opts QueueOptions) {
+	t.Helper()
+	n.EnableQueue(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = n.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for delivery")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueue_RetriesTransientFailures(t *testing.T) {
+	sink := &flakySink{
+		errs: []error{&StatusError{Code: 503}, errors.New("connection refused")},
+		done: make(chan struct{}),
+	}
+	m := metrics.NewCounters(prometheus.NewRegistry())
+	n := &Notifier{Routes: []Route{{Name: "hook", Sink: sink}}, Metrics: m}
+	startQueue(t, n, QueueOptions{Size: 10, Workers: 1, MaxAttempts: 5, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+
+	if err := n.Notify(context.Background(), Event{Type: EventSalvaged}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	select {
+	case <-sink.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was never delivered")
+	}
+	if sink.Calls() != 3 {
+		t.Errorf("expected 3 attempts, got %d", sink.Calls())
+	}
+	if val := testutil.ToFloat64(m.NotifyDeliveries.WithLabelValues("hook", "failed")); val != 2 {
+		t.Errorf("expected 2 failed attempts, got %f", val)
+	}
+}
+
+func TestQueue_DeadLetters(t *testing.T) {
+	m := metrics.NewCounters(prometheus.NewRegistry())
+	exhausted := &flakySink{errs: []error{&StatusError{Code: 500}, &StatusError{Code: 500}, &StatusError{Code: 500}}}
+	rejected := &flakySink{errs: []error{&StatusError{Code: 400}}}
+	n := &Notifier{Routes: []Route{
+		{Name: "exhausted", Sink: exhausted},
+		{Name: "rejected", Sink: rejected},
+	}, Metrics: m}
+	startQueue(t, n, QueueOptions{Size: 10, Workers: 2, MaxAttempts: 2, Backoff: time.Millisecond})
+
+	_ = n.Notify(context.Background(), Event{Type: EventSalvageFailed})
+
+	waitFor(t, func() bool {
+		return testutil.ToFloat64(m.NotifyDeadLetters.WithLabelValues("exhausted", "retries_exhausted")) == 1 &&
+			testutil.ToFloat64(m.NotifyDeadLetters.WithLabelValues("rejected", "rejected")) == 1
+	})
+	if exhausted.Calls() != 2 {
+		t.Errorf("expected MaxAttempts=2 tries, got %d", exhausted.Calls())
+	}
+	if rejected.Calls() != 1 {
+		t.Errorf("expected client errors not to be retried, got %d tries", rejected.Calls())
+	}
+}
+
+func TestQueue_FullDeadLetters(t *testing.T) {
+	m := metrics.NewCounters(prometheus.NewRegistry())
+	n := &Notifier{Routes: []Route{{Name: "hook", Sink: &recordingSink{}}}, Metrics: m}
+	// No workers started: the second event does not fit.
+	n.EnableQueue(QueueOptions{Size: 1})
+
+	if err := n.Notify(context.Background(), Event{Type: EventSalvaged}); err != nil {
+		t.Fatalf("first Notify: %v", err)
+	}
+	if err := n.Notify(context.Background(), Event{Type: EventSalvaged}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if val := testutil.ToFloat64(m.NotifyDeadLetters.WithLabelValues("hook", "queue_full")); val != 1 {
+		t.Errorf("expected 1 dead letter, got %f", val)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("dial tcp: connection refused"), true},
+		{&StatusError{Code: 500}, true},
+		{&StatusError{Code: 429}, true},
+		{&StatusError{Code: 408}, true},
+		{&StatusError{Code: 404}, false},
+		{&StatusError{Code: 401}, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity ranks how urgently an event needs a human.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// ParseSeverity parses "info", "warning" or "critical".
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (want info, warning or critical)", s)
+}
+
+// Event types sent by the controller.
+const (
+	EventDetected      = "detected"
+	EventSalvaged      = "salvaged"
+	EventSalvageFailed = "salvage_failed"
+	EventPushed        = "pushed"
+	EventPushFailed    = "push_failed"
+
+	// Registry-wide events, not about a single pod.
+	EventRegistryOutage    = "registry_outage"
+	EventRegistryRecovered = "registry_recovered"
+)
+
+// DefaultSeverities is the severity of each event type unless a route
+// overrides it: failures page, successes are informational.
+var DefaultSeverities = map[string]Severity{
+	EventDetected:      SeverityWarning,
+	EventSalvaged:      SeverityInfo,
+	EventSalvageFailed: SeverityCritical,
+	EventPushed:        SeverityInfo,
+	EventPushFailed:    SeverityWarning,
+
+	EventRegistryOutage:    SeverityCritical,
+	EventRegistryRecovered: SeverityInfo,
+}
+
+// EventTypes lists every notification type, for validating message
+// templates.
+var EventTypes = []string{
+	EventDetected, EventSalvaged, EventSalvageFailed, EventPushed, EventPushFailed,
+	EventRegistryOutage, EventRegistryRecovered,
+}
+
+// Sink delivers a notification to one destination.
+type Sink interface {
+	Send(ctx context.Context, evt Event) error
+}
+
+// Route pairs a sink with the events it receives.
+type Route struct {
+	Name        string
+	Sink        Sink
+	Events      map[string]bool     // empty = all
+	MinSeverity Severity            // events below this are dropped
+	Severities  map[string]Severity // overrides DefaultSeverities
+}
+
+func (r *Route) severity(eventType string) Severity {
+	if sev, ok := r.Severities[eventType]; ok {
+		return sev
+	}
+	return DefaultSeverities[eventType]
+}
+
+func (r *Route) accepts(eventType string, sev Severity) bool {
+	if len(r.Events) > 0 && !r.Events[eventType] {
+		return false
+	}
+	return sev >= r.MinSeverity
+}
+
+// Summary renders a one-line human-readable description of the event.
+func Summary(evt Event) string {
+	if evt.Summary != "" {
+		return evt.Summary
+	}
+	switch evt.Type {
+	case EventRegistryOutage:
+		return fmt.Sprintf("Registry %s outage: %d pods failing to pull (%s)", evt.Registry, evt.PodCount, evt.Reason)
+	case EventRegistryRecovered:
+		return fmt.Sprintf("Registry %s recovered after a %s outage", evt.Registry, time.Duration(evt.DurationSeconds*float64(time.Second)).Round(time.Second))
+	}
+	pod := evt.Namespace + "/" + evt.PodName
+	if evt.PodCount > 1 {
+		pod = fmt.Sprintf("%d pods of %s %s/%s", evt.PodCount, evt.WorkloadKind, evt.Namespace, evt.WorkloadName)
+	}
+	switch evt.Type {
+	case EventDetected:
+		return fmt.Sprintf("Image pull failure detected for %s (%s)", pod, evt.ImageRef)
+	case EventSalvaged:
+		return fmt.Sprintf("Salvaged %s for %s from %s to %s", evt.Digest, pod, evt.SourceNode, evt.TargetNode)
+	case EventSalvageFailed:
+		return fmt.Sprintf("Salvage failed for %s: %s", pod, evt.Error)
+	case EventPushed:
+		return fmt.Sprintf("Pushed %s for %s to %s", evt.Digest, pod, evt.BackupRef)
+	case EventPushFailed:
+		return fmt.Sprintf("Backup registry push failed for %s: %s", pod, evt.Error)
+	}
+	return fmt.Sprintf("tote %s for %s", evt.Type, pod)
+}
+
+type fact struct {
+	Name, Value string
+}
+
+// facts lists the populated event fields in display order.
+func facts(evt Event) []fact {
+	workload := ""
+	if evt.WorkloadName != "" {
+		workload = evt.WorkloadKind + "/" + evt.WorkloadName
+	}
+	size := ""
+	if evt.SizeBytes > 0 {
+		size = fmt.Sprintf("%d", evt.SizeBytes)
+	}
+	pods := ""
+	if evt.PodCount > 1 {
+		pods = fmt.Sprintf("%d", evt.PodCount)
+	}
+	duration := ""
+	if evt.DurationSeconds > 0 {
+		duration = fmt.Sprintf("%.1fs", evt.DurationSeconds)
+	}
+	all := []fact{
+		{"Cluster", evt.Cluster},
+		{"Namespace", evt.Namespace},
+		{"Registry", evt.Registry},
+		{"Workload", workload},
+		{"Pod", evt.PodName},
+		{"Pods", pods},
+		{"Container", evt.Container},
+		{"Image", evt.ImageRef},
+		{"Digest", evt.Digest},
+		{"Size (bytes)", size},
+		{"Source node", evt.SourceNode},
+		{"Target node", evt.TargetNode},
+		{"Backup ref", evt.BackupRef},
+		{"Duration", duration},
+		{"Reason", evt.Reason},
+		{"Message", evt.Message},
+		{"Error", evt.Error},
+		{"Correlation ID", evt.CorrelationID},
+		{"Runbook", evt.RunbookURL},
+	}
+	out := all[:0]
+	for _, f := range all {
+		if f.Value != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
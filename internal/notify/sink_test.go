@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// recordingSink captures the events delivered to it.
+type recordingSink struct {
+	events []Event
+	err    error
+}
+
+func (r *recordingSink) Send(_ context.Context, evt Event) error {
+	r.events = append(r.events, evt)
+	return r.err
+}
+
+func TestNotifier_RoutesBySeverityAndEvent(t *testing.T) {
+	pager := &recordingSink{}
+	chat := &recordingSink{}
+	n := &Notifier{Routes: []Route{
+		{Name: "pager", Sink: pager, MinSeverity: SeverityCritical},
+		{Name: "chat", Sink: chat, Events: eventSet([]string{EventSalvaged, EventSalvageFailed})},
+	}}
+
+	for _, typ := range []string{EventDetected, EventSalvaged, EventSalvageFailed} {
+		_ = n.Notify(context.Background(), Event{Type: typ})
+	}
+
+	if len(pager.events) != 1 || pager.events[0].Type != EventSalvageFailed {
+		t.Errorf("expected pager to receive only salvage_failed, got %v", pager.events)
+	}
+	if pager.events[0].Severity != "critical" {
+		t.Errorf("expected critical severity, got %q", pager.events[0].Severity)
+	}
+	if len(chat.events) != 2 {
+		t.Errorf("expected chat to receive salvaged and salvage_failed, got %v", chat.events)
+	}
+}
+
+func TestNotifier_SeverityOverride(t *testing.T) {
+	sink := &recordingSink{}
+	n := &Notifier{Routes: []Route{{
+		Name:        "pager",
+		Sink:        sink,
+		MinSeverity: SeverityCritical,
+		Severities:  map[string]Severity{EventDetected: SeverityCritical},
+	}}}
+
+	_ = n.Notify(context.Background(), Event{Type: EventDetected})
+	if len(sink.events) != 1 || sink.events[0].Severity != "critical" {
+		t.Errorf("expected detected to be promoted to critical, got %v", sink.events)
+	}
+}
+
+func TestNotifier_JoinsSinkErrors(t *testing.T) {
+	ok := &recordingSink{}
+	n := &Notifier{Routes: []Route{
+		{Name: "broken", Sink: &recordingSink{err: errors.New("boom")}},
+		{Name: "ok", Sink: ok},
+	}}
+
+	err := n.Notify(context.Background(), Event{Type: EventSalvaged})
+	if err == nil {
+		t.Fatal("expected error from broken sink")
+	}
+	if len(ok.events) != 1 {
+		t.Error("expected a failing sink not to block the others")
+	}
+}
+
+func captureJSON(t *testing.T, into any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(into); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+var failedEvent = Event{
+	Type:      EventSalvageFailed,
+	Severity:  "critical",
+	PodName:   "app-1",
+	Namespace: "default",
+	Digest:    "sha256:abc",
+	Error:     "no source",
+}
+
+func TestSlackSink(t *testing.T) {
+	var msg slackMessage
+	srv := captureJSON(t, &msg)
+
+	if err := NewSlackSink(srv.URL).Send(context.Background(), failedEvent); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if msg.Text != "Salvage failed for default/app-1: no source" {
+		t.Errorf("unexpected text %q", msg.Text)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Color != "danger" {
+		t.Fatalf("expected danger attachment, got %+v", msg.Attachments)
+	}
+	if len(msg.Attachments[0].Fields) != 4 {
+		t.Errorf("expected only populated fields, got %+v", msg.Attachments[0].Fields)
+	}
+}
+
+func TestTeamsSink(t *testing.T) {
+	var msg struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string `json:"type"`
+				Body []struct {
+					Text  string `json:"text"`
+					Color string `json:"color"`
+				} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	srv := captureJSON(t, &msg)
+
+	if err := NewTeamsSink(srv.URL).Send(context.Background(), failedEvent); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if msg.Type != "message" || len(msg.Attachments) != 1 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	card := msg.Attachments[0]
+	if card.ContentType != "application/vnd.microsoft.card.adaptive" || card.Content.Type != "AdaptiveCard" {
+		t.Errorf("expected adaptive card, got %+v", card)
+	}
+	if card.Content.Body[0].Color != "Attention" {
+		t.Errorf("expected Attention color for critical, got %q", card.Content.Body[0].Color)
+	}
+}
+
+func TestPagerDutySink(t *testing.T) {
+	var evt pagerDutyEvent
+	srv := captureJSON(t, &evt)
+
+	if err := NewPagerDutySink(srv.URL, "R0UT1NG").Send(context.Background(), failedEvent); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if evt.RoutingKey != "R0UT1NG" || evt.EventAction != "trigger" {
+		t.Errorf("unexpected envelope %+v", evt)
+	}
+	if evt.DedupKey != "tote/default/app-1/sha256:abc" {
+		t.Errorf("unexpected dedup key %q", evt.DedupKey)
+	}
+	if evt.Payload.Severity != "critical" || evt.Payload.Class != EventSalvageFailed || evt.Payload.Source != "tote" {
+		t.Errorf("unexpected payload %+v", evt.Payload)
+	}
+	if evt.Payload.CustomDetails["Error"] != "no source" {
+		t.Errorf("expected error in custom details, got %v", evt.Payload.CustomDetails)
+	}
+}
+
+func TestPagerDutySink_RegistryRecoveredResolves(t *testing.T) {
+	var evt pagerDutyEvent
+	srv := captureJSON(t, &evt)
+	sink := NewPagerDutySink(srv.URL, "R0UT1NG")
+
+	outage := Event{Type: EventRegistryOutage, Registry: "registry.example.com", CorrelationID: "reg", PodCount: 5, Reason: "unavailable"}
+	if err := sink.Send(context.Background(), outage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if evt.EventAction != "trigger" || evt.Payload.Component != "registry.example.com" {
+		t.Errorf("unexpected outage event %+v", evt)
+	}
+	if err := sink.Send(context.Background(), Event{Type: EventRegistryRecovered, Registry: "registry.example.com", CorrelationID: "reg"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if evt.EventAction != "resolve" || evt.DedupKey != "tote/reg" {
+		t.Errorf("expected the recovery to resolve tote/reg, got %+v", evt)
+	}
+}
+
+func TestNewPagerDutySink_DefaultURL(t *testing.T) {
+	if s := NewPagerDutySink("", "key"); s.URL != DefaultPagerDutyURL {
+		t.Errorf("expected default URL, got %q", s.URL)
+	}
+}
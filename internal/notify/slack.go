@@ -0,0 +1,46 @@
+package notify
+
+import "context"
+
+// SlackSink posts to a Slack incoming webhook.
+type SlackSink struct {
+	endpoint
+}
+
+// NewSlackSink creates a sink for a Slack incoming-webhook URL.
+func NewSlackSink(url string) *SlackSink {
+	return &SlackSink{endpoint: newEndpoint(url)}
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Fields []slackField `json:"fields"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+var slackColors = map[string]string{
+	"info":     "good",
+	"warning":  "warning",
+	"critical": "danger",
+}
+
+func (s *SlackSink) Send(ctx context.Context, evt Event) error {
+	att := slackAttachment{Color: slackColors[evt.Severity]}
+	for _, f := range facts(evt) {
+		att.Fields = append(att.Fields, slackField{Title: f.Name, Value: f.Value, Short: f.Name != "Error" && f.Name != "Message"})
+	}
+	return s.postJSON(ctx, slackMessage{
+		Text:        Summary(evt),
+		Attachments: []slackAttachment{att},
+	})
+}
@@ -0,0 +1,42 @@
+package notify
+
+import "context"
+
+// TeamsSink posts an Adaptive Card to a Microsoft Teams workflow webhook.
+type TeamsSink struct {
+	endpoint
+}
+
+// NewTeamsSink creates a sink for a Teams incoming-webhook URL.
+func NewTeamsSink(url string) *TeamsSink {
+	return &TeamsSink{endpoint: newEndpoint(url)}
+}
+
+var teamsColors = map[string]string{
+	"info":     "Good",
+	"warning":  "Warning",
+	"critical": "Attention",
+}
+
+func (s *TeamsSink) Send(ctx context.Context, evt Event) error {
+	var cardFacts []map[string]string
+	for _, f := range facts(evt) {
+		cardFacts = append(cardFacts, map[string]string{"title": f.Name, "value": f.Value})
+	}
+	card := map[string]any{
+		"type":    "AdaptiveCard",
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"version": "1.4",
+		"body": []map[string]any{
+			{"type": "TextBlock", "text": Summary(evt), "weight": "Bolder", "wrap": true, "color": teamsColors[evt.Severity]},
+			{"type": "FactSet", "facts": cardFacts},
+		},
+	}
+	return s.postJSON(ctx, map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	})
+}
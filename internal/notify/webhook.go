@@ -1,82 +1,195 @@
 package notify
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/templates"
 )
 
 // Event represents a notification payload sent to webhooks.
 type Event struct {
-	Type       string `json:"type"`
-	PodName    string `json:"pod_name"`
-	Namespace  string `json:"namespace"`
-	ImageRef   string `json:"image_ref,omitempty"`
-	Digest     string `json:"digest,omitempty"`
-	SourceNode string `json:"source_node,omitempty"`
-	TargetNode string `json:"target_node,omitempty"`
-	Error      string `json:"error,omitempty"`
-	Timestamp  string `json:"timestamp"`
+	// ID identifies the event across every sink and delivery attempt, so
+	// receivers can deduplicate retries. Sent as the CloudEvents id.
+	ID              string  `json:"-"`
+	Type            string  `json:"type"`
+	Severity        string  `json:"severity,omitempty"`
+	CorrelationID   string  `json:"correlation_id,omitempty"`
+	Cluster         string  `json:"cluster,omitempty"`
+	PodName         string  `json:"pod_name"`
+	Namespace       string  `json:"namespace"`
+	WorkloadKind    string  `json:"workload_kind,omitempty"`
+	WorkloadName    string  `json:"workload_name,omitempty"`
+	Container       string  `json:"container,omitempty"`
+	ImageRef        string  `json:"image_ref,omitempty"`
+	Registry        string  `json:"registry,omitempty"` // registry host of registry-wide events
+	Digest          string  `json:"digest,omitempty"`
+	SizeBytes       int64   `json:"size_bytes,omitempty"`
+	SourceNode      string  `json:"source_node,omitempty"`
+	TargetNode      string  `json:"target_node,omitempty"`
+	BackupRef       string  `json:"backup_ref,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	PodCount        int     `json:"pod_count,omitempty"` // pods coalesced into this event
+	Reason          string  `json:"reason,omitempty"`
+	Message         string  `json:"message,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	Summary         string  `json:"summary,omitempty"`     // rendered from an operator template
+	RunbookURL      string  `json:"runbook_url,omitempty"` // from the namespace's runbook entry
+	Timestamp       string  `json:"timestamp"`
 }
 
-// Notifier sends webhook notifications. Fire-and-forget with timeout.
+// Notifier fans events out to every configured route. By default each
+// sink is called synchronously; EnableQueue moves delivery to background
+// workers with retries.
 type Notifier struct {
-	URL        string
-	Events     map[string]bool
-	HTTPClient *http.Client
+	Routes    []Route
+	Cluster   string            // stamped on every event
+	Metrics   *metrics.Counters // nil = no metrics
+	Templates *templates.Set    // nil = built-in summaries
+
+	queue *queue       // nil = deliver synchronously
+	agg   *aggregator  // nil = no aggregation
+	limit *rateLimiter // nil = unlimited
 }
 
-// NewNotifier creates a Notifier for the given URL and event filter.
-// eventTypes is a list of event types to send (e.g. "detected", "salvaged").
-// An empty list means all events are sent.
+// NewNotifier creates a Notifier with a single generic webhook route for the
+// given URL and event filter. eventTypes is a list of event types to send
+// (e.g. "detected", "salvaged"). An empty list means all events are sent.
 func NewNotifier(url string, eventTypes []string) *Notifier {
-	events := make(map[string]bool)
-	for _, e := range eventTypes {
-		events[e] = true
+	if url == "" {
+		return &Notifier{}
 	}
-	return &Notifier{
-		URL:    url,
-		Events: events,
-		HTTPClient: &http.Client{
-			Timeout: 5 * time.Second,
-		},
+	return &Notifier{Routes: []Route{{
+		Name:   "webhook",
+		Sink:   NewWebhookSink(url),
+		Events: eventSet(eventTypes),
+	}}}
+}
+
+// SetSigningKey makes every sink sign its request body with key in the
+// X-Tote-Signature header.
+func (n *Notifier) SetSigningKey(key []byte) {
+	for _, r := range n.Routes {
+		if s, ok := r.Sink.(interface{ setSigningKey([]byte) }); ok {
+			s.setSigningKey(key)
+		}
 	}
 }
 
-// Notify sends an event to the webhook. Errors are logged but never returned
-// to the caller (fire-and-forget).
+// Notify hands an event to every route that accepts it. With a queue it
+// never blocks: events that do not fit are dead-lettered. Callers ignore
+// the error (fire-and-forget); the joined error of all failed or dropped
+// sinks is returned for tests.
 func (n *Notifier) Notify(ctx context.Context, evt Event) error {
-	if n == nil || n.URL == "" {
+	if n == nil {
 		return nil
 	}
-	if len(n.Events) > 0 && !n.Events[evt.Type] {
+	evt.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	if evt.Cluster == "" {
+		evt.Cluster = n.Cluster
+	}
+	if inc, ok := IncidentFrom(ctx); ok {
+		inc.apply(&evt)
+	}
+	if n.agg != nil && n.agg.add(evt) {
 		return nil
 	}
+	return n.dispatch(ctx, evt)
+}
 
-	evt.Timestamp = time.Now().UTC().Format(time.RFC3339)
-
-	body, err := json.Marshal(evt)
-	if err != nil {
-		return fmt.Errorf("marshaling event: %w", err)
+// dispatch applies the global rate cap and routes evt to every sink that
+// accepts it.
+func (n *Notifier) dispatch(ctx context.Context, evt Event) error {
+	if n.limit != nil && !n.limit.allow(time.Now()) {
+		n.recordDeadLetter("*", "rate_limited")
+		return ErrRateLimited
 	}
+	n.render(&evt)
+	if evt.ID == "" {
+		evt.ID = uuid.NewString()
+	}
+
+	var errs []error
+	for i := range n.Routes {
+		r := &n.Routes[i]
+		sev := r.severity(evt.Type)
+		if !r.accepts(evt.Type, sev) {
+			continue
+		}
+		d := delivery{route: r, evt: evt}
+		d.evt.Severity = sev.String()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		if n.queue != nil {
+			if err := n.enqueue(d); err != nil {
+				errs = append(errs, fmt.Errorf("sink %s: %w", r.Name, err))
+			}
+			continue
+		}
+		if err := r.Sink.Send(ctx, d.evt); err != nil {
+			n.recordDelivery(r.Name, "failed")
+			errs = append(errs, fmt.Errorf("sink %s: %w", r.Name, err))
+			continue
+		}
+		n.recordDelivery(r.Name, "delivered")
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return errors.Join(errs...)
+}
 
-	resp, err := n.HTTPClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending webhook: %w", err)
+// render fills the runbook URL and, when the operator supplied a template
+// for evt.Type, the summary. It runs after aggregation so templates see the
+// final pod count.
+func (n *Notifier) render(evt *Event) {
+	if n.Templates == nil {
+		return
+	}
+	if evt.RunbookURL == "" {
+		evt.RunbookURL = n.Templates.RunbookURL(evt.Namespace)
+	}
+	workload := ""
+	if evt.WorkloadName != "" {
+		workload = evt.WorkloadKind + "/" + evt.WorkloadName
+	}
+	reason := evt.Error
+	if reason == "" {
+		reason = evt.Reason
 	}
-	_ = resp.Body.Close()
+	var nodes []string
+	if evt.SourceNode != "" {
+		nodes = []string{evt.SourceNode}
+	}
+	if text, ok := n.Templates.Notification(evt.Type, templates.Data{
+		Cluster: evt.Cluster, Namespace: evt.Namespace, Pod: evt.PodName, Workload: workload,
+		Container: evt.Container, PodCount: evt.PodCount, Image: evt.ImageRef, Digest: evt.Digest,
+		Nodes: nodes, SourceNode: evt.SourceNode, TargetNode: evt.TargetNode, BackupRef: evt.BackupRef,
+		Registry: evt.Registry, Reason: reason, RunbookURL: evt.RunbookURL,
+	}); ok {
+		evt.Summary = text
+	}
+}
+
+// WebhookSink posts the Event as plain JSON.
+type WebhookSink struct {
+	endpoint
+}
+
+// NewWebhookSink creates a generic JSON sink for url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{endpoint: newEndpoint(url)}
+}
+
+func (s *WebhookSink) Send(ctx context.Context, evt Event) error {
+	return s.postJSON(ctx, evt)
+}
 
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+func eventSet(eventTypes []string) map[string]bool {
+	events := make(map[string]bool)
+	for _, e := range eventTypes {
+		events[e] = true
 	}
-	return nil
+	return events
 }
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/ppiankov/tote/internal/templates"
 )
 
 func TestNotifier_SendsEvent(t *testing.T) {
@@ -93,3 +97,45 @@ func TestNotifier_ReturnsErrorOnBadStatus(t *testing.T) {
 		t.Error("expected error on 500 status")
 	}
 }
+
+func TestNotifier_Templates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.yaml")
+	content := `
+runbooks:
+  payments: https://runbooks.example.com/payments
+notifications:
+  salvage_failed: "{{ .Workload }} in {{ .Namespace }}: {{ .Reason }}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tmpls, err := templates.Load(path, nil, EventTypes)
+	if err != nil {
+		t.Fatalf("loading templates: %v", err)
+	}
+
+	var received Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	n := NewNotifier(srv.URL, nil)
+	n.Templates = tmpls
+	err = n.Notify(context.Background(), Event{
+		Type: EventSalvageFailed, Namespace: "payments", PodName: "api-1",
+		WorkloadKind: "Deployment", WorkloadName: "api", Error: "no source node",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Summary != "Deployment/api in payments: no source node" {
+		t.Errorf("unexpected summary %q", received.Summary)
+	}
+	if received.RunbookURL != "https://runbooks.example.com/payments" {
+		t.Errorf("unexpected runbook %q", received.RunbookURL)
+	}
+	if Summary(received) != received.Summary {
+		t.Error("expected Summary to prefer the rendered template")
+	}
+}
@@ -0,0 +1,207 @@
+// Package outage detects registry-wide outages: when many pods fail to pull
+// from one registry host because it is unreachable, rate limiting or failing
+// TLS, the registry is declared down and tote switches to incident mode,
+// raising one cluster-level event, notification and metric instead of
+// leaving operators to piece the outage together from per-pod failures.
+package outage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/detector"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/notify"
+	"github.com/ppiankov/tote/internal/resolver"
+)
+
+// registry is the failure history of one registry host.
+type registry struct {
+	pods   map[string]time.Time // namespace/name -> last outage-class failure
+	active bool
+	since  time.Time // when the outage was declared
+}
+
+// Tracker aggregates pull failures by registry host. A registry is in an
+// outage once Threshold distinct pods failed to pull from it with an outage
+// class within Window, and recovers after Window passes without such a
+// failure.
+type Tracker struct {
+	Threshold int
+	Window    time.Duration
+	Emitter   *events.Emitter
+	Notifier  *notify.Notifier // nil = no notifications
+	Metrics   *metrics.Counters
+
+	// Namespace is the Namespace object outage events are recorded on.
+	// Empty = no Kubernetes events.
+	Namespace string
+
+	mu         sync.Mutex
+	registries map[string]*registry
+}
+
+// NewTracker creates a Tracker declaring an outage at threshold failing pods
+// within window.
+func NewTracker(threshold int, window time.Duration, emitter *events.Emitter, m *metrics.Counters) *Tracker {
+	return &Tracker{
+		Threshold:  threshold,
+		Window:     window,
+		Emitter:    emitter,
+		Metrics:    m,
+		registries: make(map[string]*registry),
+	}
+}
+
+// Observe records a pull failure of pod. Failures whose class does not
+// point at the registry are ignored.
+func (t *Tracker) Observe(ctx context.Context, pod *corev1.Pod, f detector.Failure) {
+	if !f.Class.Outage() {
+		return
+	}
+	host := resolver.Registry(f.Image)
+	if pods, ok := t.observe(host, pod.Namespace+"/"+pod.Name, time.Now()); ok {
+		t.startOutage(ctx, host, f.Class, pods)
+	}
+}
+
+// Active reports whether the registry host is in an outage. Safe on a nil
+// Tracker.
+func (t *Tracker) Active(host string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.registries[host]
+	return ok && r.active
+}
+
+// NeedLeaderElection returns true so only the leader reports outages.
+func (t *Tracker) NeedLeaderElection() bool {
+	return true
+}
+
+// Start ends outages that saw no failures for Window until ctx is
+// cancelled.
+func (t *Tracker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(t.Window / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			now := time.Now()
+			for host, since := range t.expire(now) {
+				t.endOutage(ctx, host, now.Sub(since))
+			}
+		}
+	}
+}
+
+// observe records the failure and returns the number of failing pods when
+// it trips an outage.
+func (t *Tracker) observe(host, pod string, now time.Time) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.registries == nil {
+		t.registries = make(map[string]*registry)
+	}
+	r, ok := t.registries[host]
+	if !ok {
+		r = &registry{pods: make(map[string]time.Time)}
+		t.registries[host] = r
+	}
+	r.pods[pod] = now
+
+	if r.active || t.Threshold <= 0 {
+		return 0, false
+	}
+	pods := 0
+	for _, at := range r.pods {
+		if now.Sub(at) <= t.Window {
+			pods++
+		}
+	}
+	if pods < t.Threshold {
+		return 0, false
+	}
+	r.active, r.since = true, now
+	return pods, true
+}
+
+// expire forgets failures older than Window and returns the hosts whose
+// outage ended, with the time each outage was declared.
+func (t *Tracker) expire(now time.Time) map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	recovered := make(map[string]time.Time)
+	for host, r := range t.registries {
+		for pod, at := range r.pods {
+			if now.Sub(at) > t.Window {
+				delete(r.pods, pod)
+			}
+		}
+		if len(r.pods) > 0 {
+			continue
+		}
+		if r.active {
+			recovered[host] = r.since
+		}
+		delete(t.registries, host)
+	}
+	return recovered
+}
+
+// startOutage reports the start of an outage.
+func (t *Tracker) startOutage(ctx context.Context, host string, class detector.Class, pods int) {
+	log.FromContext(ctx).Info("registry outage detected, switching to incident mode", "registry", host, "class", class, "pods", pods)
+	t.Metrics.SetRegistryOutage(host, true)
+	if t.Namespace != "" {
+		t.Emitter.EmitRegistryOutage(t.regarding(), host, string(class), pods)
+	}
+	if t.Notifier != nil {
+		_ = t.Notifier.Notify(ctx, notify.Event{
+			Type:          notify.EventRegistryOutage,
+			CorrelationID: correlationID(host),
+			Registry:      host,
+			PodCount:      pods,
+			Reason:        string(class),
+		})
+	}
+}
+
+// endOutage reports the end of an outage that lasted d.
+func (t *Tracker) endOutage(ctx context.Context, host string, d time.Duration) {
+	log.FromContext(ctx).Info("registry recovered", "registry", host, "outage", d.Round(time.Second))
+	t.Metrics.SetRegistryOutage(host, false)
+	if t.Namespace != "" {
+		t.Emitter.EmitRegistryRecovered(t.regarding(), host)
+	}
+	if t.Notifier != nil {
+		_ = t.Notifier.Notify(ctx, notify.Event{
+			Type:            notify.EventRegistryRecovered,
+			CorrelationID:   correlationID(host),
+			Registry:        host,
+			DurationSeconds: d.Seconds(),
+		})
+	}
+}
+
+func (t *Tracker) regarding() *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: t.Namespace}}
+}
+
+// correlationID is shared by an outage and its recovery, so incident tools
+// resolve the incident the outage opened.
+func correlationID(host string) string {
+	return notify.CorrelationID("registry", host)
+}
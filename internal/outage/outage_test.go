@@ -0,0 +1,117 @@
+package outage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sevents "k8s.io/client-go/tools/events"
+
+	"github.com/ppiankov/tote/internal/detector"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/metrics"
+)
+
+func newTracker(threshold int) (*Tracker, *k8sevents.FakeRecorder) {
+	rec := k8sevents.NewFakeRecorder(10)
+	t := NewTracker(threshold, 10*time.Minute, events.NewEmitter(rec), metrics.NewCounters(prometheus.NewRegistry()))
+	t.Namespace = "tote-system"
+	return t, rec
+}
+
+func pod(i int) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("app-%d", i), Namespace: "default"}}
+}
+
+func unreachable(image string) detector.Failure {
+	return detector.Failure{Image: image, Reason: "ErrImagePull", Class: detector.ClassUnavailable}
+}
+
+func TestObserve_TripsAtThreshold(t *testing.T) {
+	tracker, rec := newTracker(3)
+	ctx := context.Background()
+	f := unreachable("registry.example.com/app:v1")
+
+	tracker.Observe(ctx, pod(1), f)
+	tracker.Observe(ctx, pod(1), f) // the same pod again
+	tracker.Observe(ctx, pod(2), f)
+	if tracker.Active("registry.example.com") {
+		t.Fatal("expected no outage below the threshold")
+	}
+
+	tracker.Observe(ctx, pod(3), f)
+	if !tracker.Active("registry.example.com") {
+		t.Fatal("expected an outage at the threshold")
+	}
+	if v := testutil.ToFloat64(tracker.Metrics.RegistryOutage.WithLabelValues("registry.example.com")); v != 1 {
+		t.Errorf("expected tote_registry_outage 1, got %v", v)
+	}
+	select {
+	case event := <-rec.Events:
+		if !strings.Contains(event, events.ReasonRegistryOutage) || !strings.Contains(event, "3 pods") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Fatal("expected a RegistryOutage event")
+	}
+
+	// Further failures do not report the outage again.
+	tracker.Observe(ctx, pod(4), f)
+	if len(rec.Events) != 0 {
+		t.Errorf("expected one outage event, got another: %q", <-rec.Events)
+	}
+}
+
+func TestObserve_IgnoresPodSpecificClasses(t *testing.T) {
+	tracker, _ := newTracker(1)
+	tracker.Observe(context.Background(), pod(1), detector.Failure{Image: "registry.example.com/app:v1", Class: detector.ClassAuth})
+	tracker.Observe(context.Background(), pod(2), detector.Failure{Image: "registry.example.com/app:v2", Class: detector.ClassNotFound})
+	if tracker.Active("registry.example.com") {
+		t.Error("expected auth and not-found failures not to count towards an outage")
+	}
+}
+
+func TestObserve_ByRegistry(t *testing.T) {
+	tracker, _ := newTracker(2)
+	ctx := context.Background()
+	tracker.Observe(ctx, pod(1), unreachable("registry.example.com/app:v1"))
+	tracker.Observe(ctx, pod(2), unreachable("nginx:1.25"))
+	if tracker.Active("registry.example.com") || tracker.Active("docker.io") {
+		t.Error("expected failures on different registries not to add up")
+	}
+}
+
+func TestExpire(t *testing.T) {
+	tracker, _ := newTracker(2)
+	start := time.Now()
+	tracker.observe("registry.example.com", "default/app-1", start)
+	if _, tripped := tracker.observe("registry.example.com", "default/app-2", start.Add(time.Minute)); !tripped {
+		t.Fatal("expected an outage")
+	}
+
+	if ended := tracker.expire(start.Add(5 * time.Minute)); len(ended) != 0 {
+		t.Errorf("expected the outage to last within the window, got %v", ended)
+	}
+	ended := tracker.expire(start.Add(12 * time.Minute))
+	if _, ok := ended["registry.example.com"]; !ok {
+		t.Fatalf("expected the outage to end a window after the last failure, got %v", ended)
+	}
+	if tracker.Active("registry.example.com") {
+		t.Error("expected the registry to be healthy again")
+	}
+}
+
+func TestExpire_OldFailuresDoNotTrip(t *testing.T) {
+	tracker, _ := newTracker(2)
+	start := time.Now()
+	tracker.observe("registry.example.com", "default/app-1", start)
+	if _, tripped := tracker.observe("registry.example.com", "default/app-2", start.Add(11*time.Minute)); tripped {
+		t.Error("expected failures further apart than the window not to trip an outage")
+	}
+}
@@ -0,0 +1,218 @@
+// Package owners walks an object's ownerReferences to any depth and of any
+// kind, so CronJobs, Argo Rollouts, OpenKruise CloneSets and other CRD
+// owners are handled like the built-in workloads. Owners are read as
+// metadata only and cached for a short time.
+package owners
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// MaxDepth bounds the owner walk, in case of reference cycles.
+const MaxDepth = 8
+
+// DefaultTTL is how long an owner lookup is cached.
+const DefaultTTL = 30 * time.Second
+
+// Owner is an object in an owner chain, as referenced by its child.
+type Owner struct {
+	metav1.OwnerReference
+
+	// Annotations of the owner. Empty when it was not found.
+	Annotations map[string]string
+
+	// Found is true when the owner exists with the referenced UID.
+	Found bool
+
+	// Deleting is true when the owner has a deletion timestamp.
+	Deleting bool
+	// Object is the owner's metadata with its kind set, for recording events
+	// and patching annotations. Nil when it was not found.
+	Object *metav1.PartialObjectMetadata
+}
+
+type cacheKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+type cacheEntry struct {
+	meta    *metav1.PartialObjectMetadata // nil = not found or not readable
+	expires time.Time
+}
+
+// Resolver resolves owner chains. Lookups go through Reader as
+// PartialObjectMetadata, so only get permission is needed on each owner
+// kind; use an uncached reader so no informer is started per kind.
+type Resolver struct {
+	Reader client.Reader
+	TTL    time.Duration // 0 = no caching
+
+	mu    sync.Mutex
+	cache map[cacheKey]cacheEntry
+	swept time.Time
+}
+
+// NewResolver creates a Resolver caching lookups for ttl.
+func NewResolver(r client.Reader, ttl time.Duration) *Resolver {
+	return &Resolver{Reader: r, TTL: ttl, cache: make(map[cacheKey]cacheEntry)}
+}
+
+// Uncached returns a Resolver reading through the same Reader without
+// caching, for owner annotations tote itself has just written.
+func (r *Resolver) Uncached() *Resolver {
+	return NewResolver(r.Reader, 0)
+}
+
+// Chain returns the owners reachable from obj through ownerReferences,
+// nearest first. Owners that cannot be read are included with Found false
+// and not walked further. Owners live in obj's namespace.
+func (r *Resolver) Chain(ctx context.Context, obj client.Object) []Owner {
+	var chain []Owner
+	seen := map[types.UID]bool{obj.GetUID(): true}
+	refs := obj.GetOwnerReferences()
+	for depth := 0; depth < MaxDepth && len(refs) > 0; depth++ {
+		var next []metav1.OwnerReference
+		for _, ref := range refs {
+			if seen[ref.UID] && ref.UID != "" {
+				continue
+			}
+			seen[ref.UID] = true
+			owner, parents := r.resolve(ctx, obj.GetNamespace(), ref)
+			chain = append(chain, owner)
+			next = append(next, parents...)
+		}
+		refs = next
+	}
+	return chain
+}
+
+// Controller returns obj's managing controller as recorded in its
+// controller reference, or false if it has none.
+func (r *Resolver) Controller(ctx context.Context, obj client.Object) (Owner, bool) {
+	ref := metav1.GetControllerOfNoCopy(obj)
+	if ref == nil {
+		return Owner{}, false
+	}
+	owner, _ := r.resolve(ctx, obj.GetNamespace(), *ref)
+	return owner, true
+}
+
+// Controllers returns obj's controller, that controller's controller and so
+// on, nearest first. The walk stops after an owner that was not found and
+// at a Node, which controls mirror pods but is no workload.
+func (r *Resolver) Controllers(ctx context.Context, obj client.Object) []Owner {
+	var chain []Owner
+	seen := map[types.UID]bool{obj.GetUID(): true}
+	ref := metav1.GetControllerOfNoCopy(obj)
+	for depth := 0; depth < MaxDepth && ref != nil && !isNode(*ref); depth++ {
+		if seen[ref.UID] && ref.UID != "" {
+			break
+		}
+		seen[ref.UID] = true
+		owner, _ := r.resolve(ctx, obj.GetNamespace(), *ref)
+		chain = append(chain, owner)
+		if !owner.Found {
+			break
+		}
+		ref = metav1.GetControllerOfNoCopy(owner.Object)
+	}
+	return chain
+}
+
+// Workload returns the outermost existing controller of obj: the Deployment
+// behind a ReplicaSet, the CronJob behind a Job, the Rollout behind its
+// ReplicaSets, or obj's own controller when nothing manages it. It returns
+// false when obj has no controller or it cannot be read.
+func (r *Resolver) Workload(ctx context.Context, obj client.Object) (Owner, bool) {
+	chain := r.Controllers(ctx, obj)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].Found {
+			return chain[i], true
+		}
+	}
+	return Owner{}, false
+}
+
+// Recreates reports whether deleting the pod-like obj is safe because its
+// controller will replace it: the controller exists with the referenced
+// UID and is not being deleted. Mirror pods, controlled by their Node, are
+// not recreated by deleting them.
+func (r *Resolver) Recreates(ctx context.Context, obj client.Object) bool {
+	owner, ok := r.Controller(ctx, obj)
+	if !ok || isNode(owner.OwnerReference) {
+		return false
+	}
+	return owner.Found && !owner.Deleting
+}
+
+// resolve looks up the object ref points to and returns it with its own
+// owner references. An object with another UID is a replacement of the
+// referenced one and counts as not found.
+func (r *Resolver) resolve(ctx context.Context, namespace string, ref metav1.OwnerReference) (Owner, []metav1.OwnerReference) {
+	owner := Owner{OwnerReference: ref}
+	meta := r.get(ctx, namespace, ref)
+	if meta == nil || (ref.UID != "" && meta.UID != ref.UID) {
+		return owner, nil
+	}
+	owner.Found = true
+	owner.Annotations = meta.Annotations
+	owner.Deleting = meta.DeletionTimestamp != nil
+	// The cached metadata is shared; callers get their own copy to patch.
+	owner.Object = meta.DeepCopy()
+	owner.Object.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	return owner, meta.OwnerReferences
+}
+
+func isNode(ref metav1.OwnerReference) bool {
+	return ref.Kind == "Node" && ref.APIVersion == "v1"
+}
+
+func (r *Resolver) get(ctx context.Context, namespace string, ref metav1.OwnerReference) *metav1.PartialObjectMetadata {
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	key := cacheKey{gvk: gvk, namespace: namespace, name: ref.Name}
+
+	now := time.Now()
+	r.mu.Lock()
+	if e, ok := r.cache[key]; ok && now.Before(e.expires) {
+		r.mu.Unlock()
+		return e.meta
+	}
+	r.mu.Unlock()
+
+	meta := &metav1.PartialObjectMetadata{}
+	meta.SetGroupVersionKind(gvk)
+	if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, meta); err != nil {
+		// Not found, not readable or an unknown kind: the owner is unknown.
+		log.FromContext(ctx).V(1).Info("owner lookup failed", "kind", ref.Kind, "name", ref.Name, "error", err.Error())
+		meta = nil
+	}
+
+	if r.TTL > 0 {
+		r.mu.Lock()
+		if r.cache == nil {
+			r.cache = make(map[cacheKey]cacheEntry)
+		}
+		// Drop expired entries once per TTL so deleted owners do not pile up.
+		if now.Sub(r.swept) > r.TTL {
+			for k, e := range r.cache {
+				if !now.Before(e.expires) {
+					delete(r.cache, k)
+				}
+			}
+			r.swept = now
+		}
+		r.cache[key] = cacheEntry{meta: meta, expires: now.Add(r.TTL)}
+		r.mu.Unlock()
+	}
+	return meta
+}
@@ -0,0 +1,200 @@
+package owners
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func ref(apiVersion, kind, name string, uid types.UID) metav1.OwnerReference {
+	return metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid, Controller: ptr.To(true)}
+}
+
+func rollout() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("argoproj.io/v1alpha1")
+	u.SetKind("Rollout")
+	u.SetName("web")
+	u.SetNamespace("default")
+	u.SetUID("rollout-uid")
+	u.SetAnnotations(map[string]string{"tote.dev/auto-salvage": "true"})
+	return u
+}
+
+func testClient() client.Client {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = appsv1.AddToScheme(s)
+	_ = batchv1.AddToScheme(s)
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(
+		rollout(),
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-abc", Namespace: "default", UID: "rs-uid",
+			OwnerReferences: []metav1.OwnerReference{ref("argoproj.io/v1alpha1", "Rollout", "web", "rollout-uid")},
+		}},
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", UID: "cron-uid"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "nightly-1", Namespace: "default", UID: "job-uid",
+			OwnerReferences: []metav1.OwnerReference{ref("batch/v1", "CronJob", "nightly", "cron-uid")},
+		}},
+	).Build()
+}
+
+func pod(refs ...metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default", UID: "pod-uid", OwnerReferences: refs}}
+}
+
+func TestChain(t *testing.T) {
+	r := NewResolver(testClient(), DefaultTTL)
+
+	chain := r.Chain(context.Background(), pod(ref("apps/v1", "ReplicaSet", "web-abc", "rs-uid")))
+	if len(chain) != 2 || chain[0].Kind != "ReplicaSet" || chain[1].Kind != "Rollout" {
+		t.Fatalf("expected ReplicaSet then Rollout, got %+v", chain)
+	}
+	if !chain[1].Found || chain[1].Annotations["tote.dev/auto-salvage"] != "true" {
+		t.Errorf("expected the Rollout's annotations, got %+v", chain[1])
+	}
+
+	chain = r.Chain(context.Background(), pod(ref("batch/v1", "Job", "nightly-1", "job-uid")))
+	if len(chain) != 2 || chain[1].Kind != "CronJob" || !chain[1].Found {
+		t.Errorf("expected Job then CronJob, got %+v", chain)
+	}
+}
+
+func TestChain_MissingAndReplacedOwners(t *testing.T) {
+	r := NewResolver(testClient(), 0)
+
+	chain := r.Chain(context.Background(), pod(
+		ref("apps/v1", "StatefulSet", "gone", "ss-uid"),
+		ref("batch/v1", "CronJob", "nightly", "old-cron-uid"),
+	))
+	if len(chain) != 2 || chain[0].Found || chain[1].Found {
+		t.Errorf("expected both owners not found, got %+v", chain)
+	}
+}
+
+func TestChain_Cycle(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	a := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", UID: "a",
+		OwnerReferences: []metav1.OwnerReference{ref("apps/v1", "ReplicaSet", "b", "b")}}}
+	b := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", UID: "b",
+		OwnerReferences: []metav1.OwnerReference{ref("apps/v1", "ReplicaSet", "a", "a")}}}
+	r := NewResolver(fake.NewClientBuilder().WithScheme(s).WithObjects(a, b).Build(), 0)
+
+	if chain := r.Chain(context.Background(), pod(ref("apps/v1", "ReplicaSet", "a", "a"))); len(chain) != 2 {
+		t.Errorf("expected the cycle to be walked once, got %+v", chain)
+	}
+}
+
+func TestRecreates(t *testing.T) {
+	cl := testClient()
+	deleting := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name: "closing", Namespace: "default", UID: "closing-uid", Finalizers: []string{"test"},
+	}}
+	if err := cl.Create(context.Background(), deleting); err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Delete(context.Background(), deleting); err != nil {
+		t.Fatal(err)
+	}
+	r := NewResolver(cl, DefaultTTL)
+
+	notController := ref("batch/v1", "Job", "nightly-1", "job-uid")
+	notController.Controller = nil
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{"existing controller", pod(ref("batch/v1", "Job", "nightly-1", "job-uid")), true},
+		{"crd controller", pod(ref("argoproj.io/v1alpha1", "Rollout", "web", "rollout-uid")), true},
+		{"standalone", pod(), false},
+		{"owner without controller flag", pod(notController), false},
+		{"missing controller", pod(ref("apps/v1", "StatefulSet", "gone", "ss-uid")), false},
+		{"controller being deleted", pod(ref("batch/v1", "Job", "closing", "closing-uid")), false},
+		{"mirror pod", pod(ref("v1", "Node", "node-1", "node-uid")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Recreates(context.Background(), tt.pod); got != tt.want {
+				t.Errorf("Recreates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkload(t *testing.T) {
+	cl := testClient()
+	bare := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "bare", Namespace: "default", UID: "bare-uid"}}
+	orphan := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "orphan", Namespace: "default", UID: "orphan-uid",
+		OwnerReferences: []metav1.OwnerReference{ref("apps/v1", "Deployment", "gone", "gone-uid")},
+	}}
+	for _, obj := range []client.Object{bare, orphan} {
+		if err := cl.Create(context.Background(), obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewResolver(cl, DefaultTTL)
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		wantKind string
+		wantName string
+	}{
+		{"rollout behind replicaset", pod(ref("apps/v1", "ReplicaSet", "web-abc", "rs-uid")), "Rollout", "web"},
+		{"cronjob behind job", pod(ref("batch/v1", "Job", "nightly-1", "job-uid")), "CronJob", "nightly"},
+		{"bare replicaset", pod(ref("apps/v1", "ReplicaSet", "bare", "bare-uid")), "ReplicaSet", "bare"},
+		{"deleted deployment", pod(ref("apps/v1", "ReplicaSet", "orphan", "orphan-uid")), "ReplicaSet", "orphan"},
+		{"standalone", pod(), "", ""},
+		{"missing controller", pod(ref("apps/v1", "StatefulSet", "gone", "ss-uid")), "", ""},
+		{"mirror pod", pod(ref("v1", "Node", "node-1", "node-uid")), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, ok := r.Workload(context.Background(), tt.pod)
+			if ok != (tt.wantKind != "") || owner.Kind != tt.wantKind || owner.Name != tt.wantName {
+				t.Fatalf("Workload() = %s/%s %v, want %s/%s", owner.Kind, owner.Name, ok, tt.wantKind, tt.wantName)
+			}
+			if ok && (owner.Object == nil || owner.Object.Kind != tt.wantKind || owner.Object.GetName() != tt.wantName) {
+				t.Errorf("expected the owner's metadata, got %+v", owner.Object)
+			}
+		})
+	}
+}
+
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (c *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.gets++
+	return c.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestResolver_Caches(t *testing.T) {
+	reader := &countingReader{Reader: testClient()}
+	r := NewResolver(reader, time.Minute)
+	p := pod(ref("batch/v1", "Job", "nightly-1", "job-uid"), ref("apps/v1", "StatefulSet", "gone", "ss-uid"))
+
+	r.Chain(context.Background(), p)
+	first := reader.gets
+	r.Chain(context.Background(), p)
+	if reader.gets != first {
+		t.Errorf("expected cached lookups, got %d more gets", reader.gets-first)
+	}
+}
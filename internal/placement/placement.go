@@ -0,0 +1,224 @@
+// Package placement approximates the scheduler's node filters, to tell
+// which nodes a pod that is not scheduled yet could land on.
+package placement
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Fits reports whether the scheduler could place pod on node as far as the
+// node's own state goes: the node is Ready and not cordoned, matches the
+// pod's nodeSelector and required node affinity, and carries no NoSchedule
+// or NoExecute taint the pod does not tolerate. Resources, pod affinity and
+// topology spread are not considered.
+func Fits(pod *corev1.Pod, node *corev1.Node) bool {
+	if node.Spec.Unschedulable || !ready(node) || node.DeletionTimestamp != nil {
+		return false
+	}
+	for k, v := range pod.Spec.NodeSelector {
+		if node.Labels[k] != v {
+			return false
+		}
+	}
+	if a := pod.Spec.Affinity; a != nil && a.NodeAffinity != nil {
+		if req := a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; req != nil && !matchesTerms(node, req.NodeSelectorTerms) {
+			return false
+		}
+	}
+	return toleratesTaints(pod.Spec.Tolerations, node.Spec.Taints)
+}
+
+// Nodes returns the names of the nodes pod fits on, in the order given.
+func Nodes(pod *corev1.Pod, nodes []corev1.Node) []string {
+	var out []string
+	for i := range nodes {
+		if Fits(pod, &nodes[i]) {
+			out = append(out, nodes[i].Name)
+		}
+	}
+	return out
+}
+
+// poolLabels name a node's pool or group on common platforms, most specific
+// first. The instance type stands in where no pool label is set.
+var poolLabels = []string{
+	"karpenter.sh/nodepool",
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"kubernetes.azure.com/agentpool",
+	corev1.LabelInstanceTypeStable,
+}
+
+// Rank orders candidate node names by how likely the scheduler is to pick
+// them for another replica of a workload, following its default scoring as
+// far as node labels and pod counts go:
+//
+//  1. nodes in a pool that already runs a replica,
+//  2. nodes in zones running fewer replicas, then nodes running fewer
+//     replicas (topology spreading),
+//  3. nodes running fewer pods (least allocated).
+//
+// Ties keep name order. replicas lists the node of each scheduled replica
+// and pods counts the pods on each node. It is an estimate: resource
+// requests, pod affinity and scheduler profiles are not considered.
+func Rank(candidates []string, nodes []corev1.Node, replicas []string, pods map[string]int) []string {
+	byName := make(map[string]*corev1.Node, len(nodes))
+	for i := range nodes {
+		byName[nodes[i].Name] = &nodes[i]
+	}
+	replicaPools := make(map[string]bool)
+	replicaZones := make(map[string]int)
+	replicaNodes := make(map[string]int)
+	for _, name := range replicas {
+		replicaNodes[name]++
+		if node := byName[name]; node != nil {
+			if p := pool(node); p != "" {
+				replicaPools[p] = true
+			}
+			replicaZones[node.Labels[corev1.LabelTopologyZone]]++
+		}
+	}
+	inReplicaPool := func(name string) bool {
+		node := byName[name]
+		return node != nil && replicaPools[pool(node)]
+	}
+	zoneReplicas := func(name string) int {
+		if node := byName[name]; node != nil {
+			return replicaZones[node.Labels[corev1.LabelTopologyZone]]
+		}
+		return 0
+	}
+
+	out := slices.Clone(candidates)
+	slices.Sort(out)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if pa, pb := inReplicaPool(a), inReplicaPool(b); pa != pb {
+			return pa
+		}
+		if za, zb := zoneReplicas(a), zoneReplicas(b); za != zb {
+			return za < zb
+		}
+		if replicaNodes[a] != replicaNodes[b] {
+			return replicaNodes[a] < replicaNodes[b]
+		}
+		return pods[a] < pods[b]
+	})
+	return out
+}
+
+// pool returns the node's pool as "label=value", or "" if it has none.
+func pool(node *corev1.Node) string {
+	for _, label := range poolLabels {
+		if v, ok := node.Labels[label]; ok {
+			return label + "=" + v
+		}
+	}
+	return ""
+}
+
+func ready(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// matchesTerms reports whether node matches any of the terms. A term matches
+// when all of its expressions do; an empty term matches nothing.
+func matchesTerms(node *corev1.Node, terms []corev1.NodeSelectorTerm) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		ok := true
+		for _, req := range term.MatchExpressions {
+			if !matchesRequirement(node.Labels, req) {
+				ok = false
+				break
+			}
+		}
+		for _, req := range term.MatchFields {
+			// metadata.name is the only supported field.
+			if req.Key != "metadata.name" || !matchesRequirement(map[string]string{req.Key: node.Name}, req) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRequirement(labels map[string]string, req corev1.NodeSelectorRequirement) bool {
+	value, exists := labels[req.Key]
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		return exists && slices.Contains(req.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !exists || !slices.Contains(req.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !exists || len(req.Values) != 1 {
+			return false
+		}
+		have, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		want, err := strconv.ParseInt(req.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if req.Operator == corev1.NodeSelectorOpGt {
+			return have > want
+		}
+		return have < want
+	}
+	return false
+}
+
+// toleratesTaints reports whether every NoSchedule and NoExecute taint is
+// tolerated. PreferNoSchedule taints only lower a node's score.
+func toleratesTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !slices.ContainsFunc(tolerations, func(t corev1.Toleration) bool { return tolerates(t, taint) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// tolerates matches a toleration against a taint the way the scheduler
+// does: an empty effect matches every effect, and an empty key with Exists
+// matches every taint.
+func tolerates(t corev1.Toleration, taint *corev1.Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key != "" && t.Key != taint.Key {
+		return false
+	}
+	switch t.Operator {
+	case corev1.TolerationOpExists:
+		return true
+	case corev1.TolerationOpEqual, "":
+		return t.Key != "" && t.Value == taint.Value
+	}
+	return false
+}
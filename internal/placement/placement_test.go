@@ -0,0 +1,116 @@
+package placement
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name string, labels map[string]string, taints ...corev1.Taint) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}},
+	}
+}
+
+func TestFits(t *testing.T) {
+	gpuTaint := corev1.Taint{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	cordoned := node("cordoned", nil)
+	cordoned.Spec.Unschedulable = true
+	notReady := node("not-ready", nil)
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+
+	tests := []struct {
+		name string
+		pod  corev1.PodSpec
+		node corev1.Node
+		want bool
+	}{
+		{"plain", corev1.PodSpec{}, node("n", nil), true},
+		{"cordoned", corev1.PodSpec{}, cordoned, false},
+		{"not ready", corev1.PodSpec{}, notReady, false},
+		{"node selector match", corev1.PodSpec{NodeSelector: map[string]string{"pool": "web"}}, node("n", map[string]string{"pool": "web"}), true},
+		{"node selector mismatch", corev1.PodSpec{NodeSelector: map[string]string{"pool": "web"}}, node("n", map[string]string{"pool": "db"}), false},
+		{"untolerated taint", corev1.PodSpec{}, node("n", nil, gpuTaint), false},
+		{"tolerated taint", corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}}}, node("n", nil, gpuTaint), true},
+		{"exists toleration", corev1.PodSpec{Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}}}, node("n", nil, gpuTaint), true},
+		{"prefer no schedule", corev1.PodSpec{}, node("n", nil, corev1.Taint{Key: "x", Effect: corev1.TaintEffectPreferNoSchedule}), true},
+		{"affinity in", affinity(corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a", "b"}}), node("n", map[string]string{"zone": "b"}), true},
+		{"affinity not in", affinity(corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"b"}}), node("n", map[string]string{"zone": "b"}), false},
+		{"affinity does not exist", affinity(corev1.NodeSelectorRequirement{Key: "spot", Operator: corev1.NodeSelectorOpDoesNotExist}), node("n", nil), true},
+		{"affinity gt", affinity(corev1.NodeSelectorRequirement{Key: "cores", Operator: corev1.NodeSelectorOpGt, Values: []string{"8"}}), node("n", map[string]string{"cores": "16"}), true},
+		{"affinity lt", affinity(corev1.NodeSelectorRequirement{Key: "cores", Operator: corev1.NodeSelectorOpLt, Values: []string{"8"}}), node("n", map[string]string{"cores": "16"}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: tt.pod}
+			if got := Fits(pod, &tt.node); got != tt.want {
+				t.Errorf("Fits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func affinity(reqs ...corev1.NodeSelectorRequirement) corev1.PodSpec {
+	return corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: reqs}},
+		},
+	}}}
+}
+
+func TestFits_AffinityTermsAreORed(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"web"}}}},
+				{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"special"}}}},
+			},
+		},
+	}}}}
+
+	nodes := []corev1.Node{
+		node("web-1", map[string]string{"pool": "web"}),
+		node("db-1", map[string]string{"pool": "db"}),
+		node("special", nil),
+	}
+	if got, want := Nodes(pod, nodes), []string{"web-1", "special"}; !slices.Equal(got, want) {
+		t.Errorf("Nodes() = %v, want %v", got, want)
+	}
+}
+
+func TestRank(t *testing.T) {
+	labels := func(pool, zone string) map[string]string {
+		return map[string]string{"karpenter.sh/nodepool": pool, corev1.LabelTopologyZone: zone}
+	}
+	nodes := []corev1.Node{
+		node("a-busy", labels("web", "z1")),
+		node("a-idle", labels("web", "z1")),
+		node("b", labels("web", "z2")),
+		node("c", labels("web", "z3")),
+		node("other-pool", labels("batch", "z3")),
+		node("running", labels("web", "z1")),
+	}
+	// One replica runs in z1, one in z2; z3 has none.
+	replicas := []string{"running", "b"}
+	pods := map[string]int{"a-busy": 30, "a-idle": 2, "b": 5, "c": 10, "other-pool": 0, "running": 1}
+
+	got := Rank([]string{"other-pool", "a-busy", "a-idle", "b", "c"}, nodes, replicas, pods)
+	want := []string{"c", "a-idle", "a-busy", "b", "other-pool"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
+
+func TestRank_NoReplicas(t *testing.T) {
+	nodes := []corev1.Node{node("n1", nil), node("n2", nil), node("n3", nil)}
+	got := Rank([]string{"n3", "n2", "n1"}, nodes, nil, map[string]int{"n1": 4, "n3": 4})
+	if want := []string{"n2", "n1", "n3"}; !slices.Equal(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
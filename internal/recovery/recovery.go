@@ -0,0 +1,169 @@
+// Package recovery restarts a pod once the image it failed to pull is usable
+// on its node, with the strategy picked by tote.dev/recovery: delete the pod,
+// wait for kubelet, evict it within PodDisruptionBudgets or restart its
+// workload.
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/detector"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/workload"
+)
+
+// AnnotationRestartedAt is the pod template annotation kubectl rollout
+// restart sets; changing it rolls the workload's pods.
+const AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+
+// Recoverer restarts pods after their image was salvaged or repaired.
+type Recoverer struct {
+	Client  client.Client
+	Owners  *owners.Resolver
+	Emitter *events.Emitter
+	Metrics *metrics.Counters
+
+	// Default is the strategy for pods without tote.dev/recovery.
+	// Empty means config.RecoveryDelete.
+	Default string
+}
+
+// New creates a Recoverer using strategy def by default.
+func New(c client.Client, res *owners.Resolver, emitter *events.Emitter, m *metrics.Counters, def string) *Recoverer {
+	return &Recoverer{Client: c, Owners: res, Emitter: emitter, Metrics: m, Default: def}
+}
+
+// Strategy returns the tote.dev/recovery of the pod, else of its workload,
+// else of its namespace, else Default.
+func (r *Recoverer) Strategy(ctx context.Context, pod *corev1.Pod) string {
+	if s := workload.Setting(ctx, r.Client, r.Owners, pod, config.AnnotationRecovery, config.ValidateRecovery); s != "" {
+		return s
+	}
+	if r.Default == "" {
+		return config.RecoveryDelete
+	}
+	return r.Default
+}
+
+// Recover restarts the pod with its strategy so it starts from the image
+// now usable on its node. Pods that no controller would recreate are left
+// alone and get a ManualRestartRequired event instead. Pods whose image only
+// ephemeral debug containers use are left to kubelet, since restarting would
+// end the debug session. Errors are logged and counted but not returned: the
+// image is already fixed, and kubelet's pull backoff still picks it up.
+func (r *Recoverer) Recover(ctx context.Context, pod *corev1.Pod, image string) {
+	logger := log.FromContext(ctx).WithValues("pod", pod.Name, "namespace", pod.Namespace)
+
+	if detector.EphemeralOnly(pod, image) {
+		logger.Info("image only used by ephemeral containers, leaving pod to kubelet's pull backoff")
+		r.Metrics.RecordRecovery(config.RecoveryWait, "success")
+		return
+	}
+	strategy := r.Strategy(ctx, pod)
+	if strategy == config.RecoveryWait {
+		logger.Info("leaving pod to kubelet's pull backoff")
+		r.Metrics.RecordRecovery(strategy, "success")
+		return
+	}
+	if !r.Owners.Recreates(ctx, pod) {
+		logger.Info("no controller would recreate pod, manual restart required")
+		r.Emitter.EmitManualRestart(ctx, pod, image)
+		r.Metrics.RecordRecovery(strategy, "manual")
+		return
+	}
+
+	var err error
+	switch strategy {
+	case config.RecoveryEvict:
+		err = Evict(ctx, r.Client, pod)
+	case config.RecoveryRestart:
+		err = r.restart(ctx, pod, image)
+	default:
+		err = client.IgnoreNotFound(r.Client.Delete(ctx, pod))
+	}
+	switch {
+	case apierrors.IsTooManyRequests(err):
+		// A PodDisruptionBudget allows no disruption right now.
+		logger.Info("eviction blocked by a PodDisruptionBudget, leaving pod to kubelet's pull backoff")
+		r.Metrics.RecordRecovery(strategy, "blocked")
+	case err != nil:
+		logger.Error(err, "failed to recover pod", "strategy", strategy)
+		r.Metrics.RecordRecovery(strategy, "failed")
+	default:
+		logger.Info("recovered pod", "strategy", strategy)
+		r.Metrics.RecordRecovery(strategy, "success")
+	}
+}
+
+// Evict asks the API server to evict the pod, which fails with
+// TooManyRequests while a PodDisruptionBudget allows no disruption.
+func Evict(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+	if err := c.SubResource("eviction").Create(ctx, pod, eviction); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// restart rolls the pod's Deployment, StatefulSet or DaemonSet by setting
+// the restartedAt template annotation. Other workloads, such as CronJobs or
+// Argo Rollouts, get a RestartUnsupported event and the pod is evicted
+// instead. A workload already restarted since the pod was created is left
+// alone, so salvaging several of its pods restarts it once.
+func (r *Recoverer) restart(ctx context.Context, pod *corev1.Pod, image string) error {
+	var owner client.Object
+	var template *corev1.PodTemplateSpec
+	ref := workload.Owner(ctx, r.Owners, pod)
+	if ref != nil && ref.GetObjectKind().GroupVersionKind().Group == appsv1.GroupName {
+		switch workload.Kind(ref) {
+		case "Deployment":
+			w := &appsv1.Deployment{}
+			owner, template = w, &w.Spec.Template
+		case "StatefulSet":
+			w := &appsv1.StatefulSet{}
+			owner, template = w, &w.Spec.Template
+		case "DaemonSet":
+			w := &appsv1.DaemonSet{}
+			owner, template = w, &w.Spec.Template
+		}
+	}
+	if owner == nil {
+		kind, name := workload.KindName(ctx, r.Owners, pod)
+		log.FromContext(ctx).Info("workload cannot be restarted, evicting pod instead", "pod", pod.Name, "workload", kind+"/"+name)
+		r.Emitter.EmitRestartUnsupported(ctx, pod, image, kind+"/"+name)
+		return Evict(ctx, r.Client, pod)
+	}
+	kind := workload.Kind(ref)
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(ref), owner); err != nil {
+		return fmt.Errorf("getting %s %s/%s: %w", kind, ref.GetNamespace(), ref.GetName(), err)
+	}
+
+	if at, err := time.Parse(time.RFC3339, template.Annotations[AnnotationRestartedAt]); err == nil && !at.Before(pod.CreationTimestamp.Time) {
+		return nil
+	}
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[AnnotationRestartedAt] = time.Now().UTC().Format(time.RFC3339)
+	if err := r.Client.Patch(ctx, owner, patch); err != nil {
+		return fmt.Errorf("restarting %s %s/%s: %w", kind, owner.GetNamespace(), owner.GetName(), err)
+	}
+	return nil
+}
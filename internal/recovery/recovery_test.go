@@ -0,0 +1,255 @@
+package recovery
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/owners"
+)
+
+func newScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = appsv1.AddToScheme(s)
+	_ = batchv1.AddToScheme(s)
+	return s
+}
+
+// deploymentPod returns pod app-1 owned by Deployment app through
+// ReplicaSet app-rs, created an hour ago.
+func deploymentPod() (*corev1.Pod, *appsv1.ReplicaSet, *appsv1.Deployment) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "app-1", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app-rs", UID: "rs-uid", Controller: ptr.To(true)}},
+	}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "app-rs", Namespace: "default", UID: "rs-uid",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", UID: "app-uid", Controller: ptr.To(true)}},
+	}}
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid"}}
+	return pod, rs, dep
+}
+
+type fixture struct {
+	recoverer *Recoverer
+	recorder  *k8sevents.FakeRecorder
+}
+
+func setup(funcs interceptor.Funcs, objs ...runtime.Object) fixture {
+	cl := interceptor.NewClient(fake.NewClientBuilder().WithScheme(newScheme()).WithRuntimeObjects(objs...).Build(), funcs)
+	rec := k8sevents.NewFakeRecorder(10)
+	m := metrics.NewCounters(prometheus.NewRegistry())
+	return fixture{
+		recoverer: New(cl, owners.NewResolver(cl, 0), events.NewEmitter(rec), m, config.RecoveryDelete),
+		recorder:  rec,
+	}
+}
+
+func podExists(t *testing.T, c client.Client, pod *corev1.Pod) bool {
+	t.Helper()
+	err := c.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestRecover(t *testing.T) {
+	pdbBlocks := interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, sub string, obj client.Object, subObj client.Object, opts ...client.SubResourceCreateOption) error {
+			return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		},
+	}
+
+	tests := []struct {
+		name       string
+		strategy   string
+		funcs      interceptor.Funcs
+		wantPod    bool
+		wantResult string
+	}{
+		{"delete", config.RecoveryDelete, interceptor.Funcs{}, false, "success"},
+		{"wait", config.RecoveryWait, interceptor.Funcs{}, true, "success"},
+		{"evict", config.RecoveryEvict, interceptor.Funcs{}, false, "success"},
+		{"evict blocked by budget", config.RecoveryEvict, pdbBlocks, true, "blocked"},
+		{"restart", config.RecoveryRestart, interceptor.Funcs{}, true, "success"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, rs, dep := deploymentPod()
+			dep.Annotations = map[string]string{config.AnnotationRecovery: tt.strategy}
+			f := setup(tt.funcs, pod, rs, dep)
+
+			f.recoverer.Recover(context.Background(), pod, "app:v1")
+
+			if got := podExists(t, f.recoverer.Client, pod); got != tt.wantPod {
+				t.Errorf("expected pod exists = %v, got %v", tt.wantPod, got)
+			}
+			if n := testutil.ToFloat64(f.recoverer.Metrics.Recoveries.WithLabelValues(tt.strategy, tt.wantResult)); n != 1 {
+				t.Errorf("expected one %s/%s recovery, got %v", tt.strategy, tt.wantResult, n)
+			}
+		})
+	}
+}
+
+func TestRecover_StandalonePod(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bare", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "node-1"}}
+	f := setup(interceptor.Funcs{}, pod)
+
+	f.recoverer.Recover(context.Background(), pod, "app:v1")
+
+	if !podExists(t, f.recoverer.Client, pod) {
+		t.Error("expected standalone pod to be kept")
+	}
+	select {
+	case event := <-f.recorder.Events:
+		if !strings.Contains(event, events.ReasonManualRestart) {
+			t.Errorf("expected %s event, got %q", events.ReasonManualRestart, event)
+		}
+	default:
+		t.Error("expected a manual restart event")
+	}
+}
+
+func TestRecover_EphemeralContainer(t *testing.T) {
+	pod, rs, dep := deploymentPod()
+	pod.Spec.Containers = []corev1.Container{{Name: "app", Image: "app:v1"}}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.36"},
+	}}
+	f := setup(interceptor.Funcs{}, pod, rs, dep)
+
+	f.recoverer.Recover(context.Background(), pod, "busybox:1.36")
+
+	if !podExists(t, f.recoverer.Client, pod) {
+		t.Error("expected the pod to keep running its debug session")
+	}
+	if n := testutil.ToFloat64(f.recoverer.Metrics.Recoveries.WithLabelValues(config.RecoveryWait, "success")); n != 1 {
+		t.Errorf("expected the pod to be left to kubelet, got %v wait recoveries", n)
+	}
+}
+
+func TestRecover_RestartOnce(t *testing.T) {
+	pod, rs, dep := deploymentPod()
+	f := setup(interceptor.Funcs{}, pod, rs, dep)
+	f.recoverer.Default = config.RecoveryRestart
+	ctx := context.Background()
+
+	f.recoverer.Recover(ctx, pod, "app:v1")
+	var got appsv1.Deployment
+	if err := f.recoverer.Client.Get(ctx, client.ObjectKeyFromObject(dep), &got); err != nil {
+		t.Fatal(err)
+	}
+	first := got.Spec.Template.Annotations[AnnotationRestartedAt]
+	if first == "" {
+		t.Fatal("expected the deployment's pod template to be restarted")
+	}
+
+	// A second pod of the same rollout does not restart it again.
+	got.Spec.Template.Annotations[AnnotationRestartedAt] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if err := f.recoverer.Client.Update(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := got.Spec.Template.Annotations[AnnotationRestartedAt]
+	f.recoverer.Recover(ctx, pod, "app:v1")
+	if err := f.recoverer.Client.Get(ctx, client.ObjectKeyFromObject(dep), &got); err != nil {
+		t.Fatal(err)
+	}
+	if at := got.Spec.Template.Annotations[AnnotationRestartedAt]; at != want {
+		t.Errorf("expected restartedAt to stay %s, got %s", want, at)
+	}
+}
+
+func TestRecover_CustomOwnerStrategy(t *testing.T) {
+	pdbBlocks := interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, sub string, obj client.Object, subObj client.Object, opts ...client.SubResourceCreateOption) error {
+			return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		},
+	}
+	pod, rs, _ := deploymentPod()
+	rs.OwnerReferences = []metav1.OwnerReference{{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "app", UID: "rollout-uid", Controller: ptr.To(true)}}
+	rollout := &unstructured.Unstructured{}
+	rollout.SetAPIVersion("argoproj.io/v1alpha1")
+	rollout.SetKind("Rollout")
+	rollout.SetNamespace("default")
+	rollout.SetName("app")
+	rollout.SetUID("rollout-uid")
+	rollout.SetAnnotations(map[string]string{config.AnnotationRecovery: config.RecoveryEvict})
+	f := setup(pdbBlocks, pod, rs, rollout)
+
+	f.recoverer.Recover(context.Background(), pod, "app:v1")
+
+	if !podExists(t, f.recoverer.Client, pod) {
+		t.Error("expected the Rollout's evict strategy to keep the pod while the budget blocks")
+	}
+	if n := testutil.ToFloat64(f.recoverer.Metrics.Recoveries.WithLabelValues(config.RecoveryEvict, "blocked")); n != 1 {
+		t.Errorf("expected one blocked eviction, got %v", n)
+	}
+}
+
+func TestRecover_RestartFallsBackToEviction(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "report-1-abc", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "report-1", UID: "job-uid", Controller: ptr.To(true)}},
+	}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name: "report-1", Namespace: "default", UID: "job-uid",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "report", UID: "cron-uid", Controller: ptr.To(true)}},
+	}}
+	cron := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default", UID: "cron-uid"}}
+	var evictions int
+	f := setup(interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, sub string, obj client.Object, subObj client.Object, opts ...client.SubResourceCreateOption) error {
+			evictions++
+			return c.SubResource(sub).Create(ctx, obj, subObj, opts...)
+		},
+	}, pod, job, cron)
+	f.recoverer.Default = config.RecoveryRestart
+
+	f.recoverer.Recover(context.Background(), pod, "app:v1")
+
+	if evictions != 1 || podExists(t, f.recoverer.Client, pod) {
+		t.Errorf("expected the CronJob's pod to be evicted, got %d evictions", evictions)
+	}
+	select {
+	case e := <-f.recorder.Events:
+		if !strings.Contains(e, events.ReasonRestartUnsupported) || !strings.Contains(e, "CronJob/report") {
+			t.Errorf("expected a RestartUnsupported event naming the CronJob, got %q", e)
+		}
+	default:
+		t.Error("expected a RestartUnsupported event")
+	}
+}
+
+func TestEvict_NotFound(t *testing.T) {
+	f := setup(interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, sub string, obj client.Object, subObj client.Object, opts ...client.SubResourceCreateOption) error {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, obj.GetName())
+		},
+	})
+	pod, _, _ := deploymentPod()
+	if err := Evict(context.Background(), f.recoverer.Client, pod); err != nil {
+		t.Errorf("expected a gone pod to count as evicted, got %v", err)
+	}
+}
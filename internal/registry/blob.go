@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// FetchBlob opens the blob with the given digest from the repository of
+// repoRef (any tag or digest on repoRef is ignored). The caller must close
+// the returned reader.
+func FetchBlob(ctx context.Context, repoRef, blob, username, password string, insecure bool) (io.ReadCloser, error) {
+	ref, err := name.ParseReference(repoRef, nameOpts(insecure)...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ref %q: %w", repoRef, err)
+	}
+	digestRef := ref.Context().Digest(blob)
+
+	opts := []remote.Option{remote.WithContext(ctx)}
+	if username != "" {
+		opts = append(opts, remote.WithAuth(&authn.Basic{
+			Username: username,
+			Password: password,
+		}))
+	}
+
+	layer, err := remote.Layer(digestRef, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching blob %s from %s: %w", blob, ref.Context(), err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("reading blob %s from %s: %w", blob, ref.Context(), err)
+	}
+	return rc, nil
+}
@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestFetchBlob_Success(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(host+"/test/app:v1", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img); err != nil {
+		t.Fatalf("seeding registry: %v", err)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerDigest, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := layers[0].Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData, _ := io.ReadAll(want)
+
+	rc, err := FetchBlob(context.Background(), host+"/test/app:other-tag", layerDigest.String(), "", "", true)
+	if err != nil {
+		t.Fatalf("FetchBlob: %v", err)
+	}
+	defer func() { _ = rc.Close() }()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading blob: %v", err)
+	}
+	if !bytes.Equal(got, wantData) {
+		t.Errorf("blob content mismatch: got %d bytes, want %d", len(got), len(wantData))
+	}
+}
+
+func TestFetchBlob_InvalidRef(t *testing.T) {
+	_, err := FetchBlob(context.Background(), ":::invalid", "sha256:abc", "", "", false)
+	if err == nil {
+		t.Fatal("expected error for invalid ref")
+	}
+}
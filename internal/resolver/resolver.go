@@ -38,3 +38,17 @@ func Resolve(image string) Resolution {
 
 	return r
 }
+
+// DefaultRegistry is the registry of image references without a host.
+const DefaultRegistry = "docker.io"
+
+// Registry returns the registry host of an image reference. The first path
+// component is a host when it contains "." or ":" or is "localhost", as in
+// the Docker reference grammar; otherwise the image is on DefaultRegistry.
+func Registry(image string) string {
+	host, _, ok := strings.Cut(image, "/")
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return DefaultRegistry
+	}
+	return host
+}
@@ -72,3 +72,19 @@ func TestResolve_PreservesOriginal(t *testing.T) {
 		t.Errorf("expected original %q, got %q", image, r.Original)
 	}
 }
+
+func TestRegistry(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                                  "docker.io",
+		"nginx@" + validDigest:                   "docker.io",
+		"library/nginx:1.25":                     "docker.io",
+		"registry.example.com/team/app:v1":       "registry.example.com",
+		"localhost/app:v1":                       "localhost",
+		"registry.local:5000/app@" + validDigest: "registry.local:5000",
+	}
+	for image, want := range tests {
+		if got := Registry(image); got != want {
+			t.Errorf("Registry(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -13,6 +13,7 @@ type Session struct {
 	Digest     string
 	SourceNode string
 	TargetNode string
+	Platform   string // target platform to export; empty = all
 	ExpiresAt  time.Time
 }
 
@@ -46,13 +47,14 @@ func (s *Store) Create(digest, sourceNode, targetNode string, ttl time.Duration)
 
 // Register stores a session with a pre-existing token (created by the controller).
 // Used by agents to accept session tokens from the orchestrator.
-func (s *Store) Register(token, digest string, ttl time.Duration) Session {
+func (s *Store) Register(token, digest, platform string, ttl time.Duration) Session {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	sess := Session{
 		Token:     token,
 		Digest:    digest,
+		Platform:  platform,
 		ExpiresAt: time.Now().Add(ttl),
 	}
 	s.sessions[token] = sess
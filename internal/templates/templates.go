@@ -0,0 +1,157 @@
+// Package templates renders operator-supplied text/template overrides for
+// Kubernetes event messages and notification summaries.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultRunbookKey selects the runbook URL for namespaces without their own.
+const DefaultRunbookKey = "*"
+
+// Data is available to every template.
+type Data struct {
+	Cluster    string
+	Namespace  string
+	Pod        string
+	Workload   string // Kind/name of the owning workload, if known
+	Container  string
+	PodCount   int // pods coalesced into a notification
+	Image      string
+	Registry   string // registry host of registry-wide events
+	Digest     string
+	Nodes      []string // nodes holding the image (ImageSalvageable)
+	SourceNode string
+	TargetNode string
+	BackupRef  string
+	Blobs      int    // missing or repaired blobs
+	Reason     string // failure detail or error
+	RunbookURL string
+}
+
+// Set is a validated collection of templates.
+type Set struct {
+	runbooks      map[string]string
+	events        map[string]*template.Template
+	notifications map[string]*template.Template
+}
+
+type file struct {
+	Runbooks      map[string]string `json:"runbooks,omitempty"`      // namespace -> URL
+	Events        map[string]string `json:"events,omitempty"`        // event reason -> template
+	Notifications map[string]string `json:"notifications,omitempty"` // notification type -> template
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Load reads a YAML template file and validates it: every key must be a
+// known event reason or notification type, and every template must parse
+// and render against sample data.
+func Load(path string, eventReasons, notificationTypes []string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading message templates: %w", err)
+	}
+	var f file
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing message templates %s: %w", path, err)
+	}
+
+	s := &Set{runbooks: f.Runbooks}
+	if s.events, err = compile("events", f.Events, eventReasons); err != nil {
+		return nil, err
+	}
+	if s.notifications, err = compile("notifications", f.Notifications, notificationTypes); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func compile(section string, defs map[string]string, known []string) (map[string]*template.Template, error) {
+	sample := Data{
+		Cluster: "cluster", Namespace: "namespace", Pod: "pod", Workload: "Deployment/app",
+		Container: "app", PodCount: 2, Image: "registry.example.com/app:v1", Registry: "registry.example.com", Digest: "sha256:0",
+		Nodes: []string{"node-a", "node-b"}, SourceNode: "node-a", TargetNode: "node-b",
+		BackupRef: "backup.example.com/app:v1", Blobs: 1, Reason: "reason", RunbookURL: "https://runbook",
+	}
+	out := make(map[string]*template.Template, len(defs))
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("%s template %q: unknown key (want one of %s)", section, name, strings.Join(known, ", "))
+		}
+		tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(defs[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s template %q: %w", section, name, err)
+		}
+		if err := tmpl.Execute(&bytes.Buffer{}, sample); err != nil {
+			return nil, fmt.Errorf("%s template %q: %w", section, name, err)
+		}
+		out[name] = tmpl
+	}
+	return out, nil
+}
+
+// RunbookURL returns the runbook for namespace, falling back to the "*"
+// entry. Safe on a nil Set.
+func (s *Set) RunbookURL(namespace string) string {
+	if s == nil {
+		return ""
+	}
+	if url, ok := s.runbooks[namespace]; ok {
+		return url
+	}
+	return s.runbooks[DefaultRunbookKey]
+}
+
+// Event renders the override for an event reason. ok is false when there
+// is no override or it fails to render, in which case the built-in message
+// should be used. Safe on a nil Set.
+func (s *Set) Event(reason string, d Data) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return render(s.events[reason], s.withRunbook(d))
+}
+
+// Notification renders the override for a notification type, like Event.
+func (s *Set) Notification(eventType string, d Data) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return render(s.notifications[eventType], s.withRunbook(d))
+}
+
+func (s *Set) withRunbook(d Data) Data {
+	if d.RunbookURL == "" {
+		d.RunbookURL = s.RunbookURL(d.Namespace)
+	}
+	return d
+}
+
+func render(tmpl *template.Template, d Data) (string, bool) {
+	if tmpl == nil {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplates(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "templates.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeTemplates(t, `
+runbooks:
+  "*": https://runbooks.example.com/default
+  payments: https://runbooks.example.com/payments
+events:
+  ImageSalvageable: "{{ .Image }} cached on {{ join .Nodes \", \" }} ({{ .RunbookURL }})"
+notifications:
+  salvaged: "{{ upper .Namespace }}/{{ .Pod }} saved"
+`)
+	s, err := Load(path, []string{"ImageSalvageable", "ImageSalvaged"}, []string{"salvaged"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	msg, ok := s.Event("ImageSalvageable", Data{Namespace: "payments", Image: "app:v1", Nodes: []string{"a", "b"}})
+	if !ok || msg != "app:v1 cached on a, b (https://runbooks.example.com/payments)" {
+		t.Errorf("unexpected event message %q (ok=%v)", msg, ok)
+	}
+	if _, ok := s.Event("ImageSalvaged", Data{}); ok {
+		t.Error("expected no override for ImageSalvaged")
+	}
+	msg, ok = s.Notification("salvaged", Data{Namespace: "web", Pod: "p"})
+	if !ok || msg != "WEB/p saved" {
+		t.Errorf("unexpected notification %q (ok=%v)", msg, ok)
+	}
+	if got := s.RunbookURL("other"); got != "https://runbooks.example.com/default" {
+		t.Errorf("expected default runbook, got %q", got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"unknown reason", "events:\n  ImageTypo: x\n", "unknown key"},
+		{"unknown notification", "notifications:\n  typo: x\n", "unknown key"},
+		{"parse error", "events:\n  ImageSalvaged: \"{{ .Image \"\n", "ImageSalvaged"},
+		{"unknown field", "events:\n  ImageSalvaged: \"{{ .Nope }}\"\n", "Nope"},
+		{"unknown section", "messages: {}\n", "messages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeTemplates(t, tt.content), []string{"ImageSalvaged"}, []string{"salvaged"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNilSet(t *testing.T) {
+	var s *Set
+	if _, ok := s.Event("ImageSalvaged", Data{}); ok {
+		t.Error("expected nil set to have no overrides")
+	}
+	if s.RunbookURL("ns") != "" {
+		t.Error("expected no runbook on nil set")
+	}
+}
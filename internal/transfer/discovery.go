@@ -110,6 +110,75 @@ func (r *Resolver) RemoveImageOnNode(ctx context.Context, nodeName, imageRef str
 	return err
 }
 
+// CheckImageOnNode asks the agent on the given node which blobs of an image
+// are missing from its content store. Returns the image digest and the
+// missing blob digests (empty when the content is complete).
+func (r *Resolver) CheckImageOnNode(ctx context.Context, nodeName, imageRef string) (string, []string, error) {
+	endpoint, err := r.EndpointForNode(ctx, nodeName)
+	if err != nil {
+		return "", nil, err
+	}
+	conn, err := grpc.NewClient(endpoint, r.dialOption())
+	if err != nil {
+		return "", nil, fmt.Errorf("connecting to agent: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := v1.NewToteAgentClient(conn).CheckImage(ctx, &v1.CheckImageRequest{ImageRef: imageRef})
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Digest, resp.MissingBlobs, nil
+}
+
+// CorruptImagesByNode collects the findings of every agent's background
+// content scanner, keyed by node name. Agents that cannot be reached are
+// skipped so one bad node does not hide the rest.
+func (r *Resolver) CorruptImagesByNode(ctx context.Context) (map[string][]*v1.CorruptImage, error) {
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods,
+		client.InNamespace(r.Namespace),
+		client.MatchingLabels{
+			"app.kubernetes.io/name":      "tote",
+			"app.kubernetes.io/component": "agent",
+		},
+	); err != nil {
+		return nil, fmt.Errorf("listing agent pods: %w", err)
+	}
+
+	logger := log.FromContext(ctx)
+	byNode := make(map[string][]*v1.CorruptImage)
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == "" || pod.Spec.NodeName == "" {
+			continue
+		}
+		endpoint := fmt.Sprintf("%s:%d", pod.Status.PodIP, r.Port)
+		images, err := r.listCorruptFromAgent(ctx, endpoint)
+		if err != nil {
+			logger.V(1).Info("agent ListCorruptImages failed", "endpoint", endpoint, "node", pod.Spec.NodeName, "error", err)
+			continue
+		}
+		if len(images) > 0 {
+			byNode[pod.Spec.NodeName] = images
+		}
+	}
+	return byNode, nil
+}
+
+func (r *Resolver) listCorruptFromAgent(ctx context.Context, endpoint string) ([]*v1.CorruptImage, error) {
+	conn, err := grpc.NewClient(endpoint, r.dialOption())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := v1.NewToteAgentClient(conn).ListCorruptImages(ctx, &v1.ListCorruptImagesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Images, nil
+}
+
 func (r *Resolver) resolveTagFromAgent(ctx context.Context, endpoint, imageRef string) (string, error) {
 	conn, err := grpc.NewClient(endpoint, r.dialOption())
 	if err != nil {
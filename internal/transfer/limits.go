@@ -0,0 +1,114 @@
+package transfer
+
+import (
+	"context"
+	"sync"
+)
+
+// Limits bounds concurrent transfers cluster-wide, per target node and per
+// source node. A limit of zero or less is unbounded.
+type Limits struct {
+	mu        sync.Mutex
+	max       int
+	perTarget int
+	perSource int
+	running   int
+	targets   map[string]int
+	sources   map[string]int
+	freed     chan struct{} // closed and replaced whenever a slot is released
+}
+
+// NewLimits returns Limits allowing max transfers at once, at most
+// perTarget onto one node and at most perSource served by one node.
+func NewLimits(max, perTarget, perSource int) *Limits {
+	return &Limits{
+		max:       max,
+		perTarget: perTarget,
+		perSource: perSource,
+		targets:   make(map[string]int),
+		sources:   make(map[string]int),
+		freed:     make(chan struct{}),
+	}
+}
+
+// acquire takes a global and a target slot, or returns false if either is
+// exhausted.
+func (l *Limits) acquire(target string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquireLocked(target)
+}
+
+// acquireWait takes a global and a target slot, waiting for a release while
+// both are exhausted, until ctx is done.
+func (l *Limits) acquireWait(ctx context.Context, target string) error {
+	for {
+		l.mu.Lock()
+		ok, freed := l.acquireLocked(target), l.freed
+		l.mu.Unlock()
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+// released returns a channel that is closed when any slot is next released.
+func (l *Limits) released() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.freed
+}
+
+func (l *Limits) acquireLocked(target string) bool {
+	if l.max > 0 && l.running >= l.max {
+		return false
+	}
+	if l.perTarget > 0 && l.targets[target] >= l.perTarget {
+		return false
+	}
+	l.running++
+	l.targets[target]++
+	return true
+}
+
+func (l *Limits) release(target string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	if l.targets[target]--; l.targets[target] <= 0 {
+		delete(l.targets, target)
+	}
+	l.notify()
+}
+
+// acquireSource takes a slot on a source node, or returns false if the node
+// is already serving its limit.
+func (l *Limits) acquireSource(node string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perSource > 0 && l.sources[node] >= l.perSource {
+		return false
+	}
+	l.sources[node]++
+	return true
+}
+
+func (l *Limits) releaseSource(node string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sources[node]--; l.sources[node] <= 0 {
+		delete(l.sources, node)
+	}
+	l.notify()
+}
+
+// notify wakes every waiter. The caller holds l.mu.
+func (l *Limits) notify() {
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
@@ -0,0 +1,56 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/containerd/platforms"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNoPlatformVariant is returned when none of the candidate source nodes
+// holds complete content for the target node's platform.
+var ErrNoPlatformVariant = errors.New("no compatible platform variant cached")
+
+// nodePlatform returns the normalized os/arch platform string kubelet
+// reports for the node, e.g. "linux/arm64".
+func nodePlatform(ctx context.Context, c client.Reader, nodeName string) (string, error) {
+	var node corev1.Node
+	if err := c.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return "", fmt.Errorf("getting node %s: %w", nodeName, err)
+	}
+	info := node.Status.NodeInfo
+	if info.OperatingSystem == "" || info.Architecture == "" {
+		return "", fmt.Errorf("node %s does not report its platform", nodeName)
+	}
+	return platforms.Format(platforms.Normalize(ocispec.Platform{
+		OS:           info.OperatingSystem,
+		Architecture: info.Architecture,
+	})), nil
+}
+
+// rankSources orders candidate source nodes so those running the target
+// platform come first: a node always pulls its own platform's layers, so
+// its cached content is the most likely to cover the target. The relative
+// order of the candidates is otherwise preserved.
+func rankSources(ctx context.Context, c client.Reader, nodes []string, platform string) []string {
+	ranked := append([]string(nil), nodes...)
+	if platform == "" {
+		return ranked
+	}
+	same := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		if p, err := nodePlatform(ctx, c, n); err == nil && p == platform {
+			same[n] = true
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return same[ranked[i]] && !same[ranked[j]]
+	})
+	return ranked
+}
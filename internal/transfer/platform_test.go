@@ -0,0 +1,339 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/ppiankov/tote/api/v1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
+	"github.com/ppiankov/tote/internal/agent"
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/events"
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/notify"
+	"github.com/ppiankov/tote/internal/session"
+)
+
+// The fake store names imported images after the payload length.
+const platformDigest = "sha256:fake-14"
+
+func platformNode(name, arch string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{OperatingSystem: "linux", Architecture: arch},
+		},
+	}
+}
+
+// platformCluster starts one agent per node on its own loopback address
+// (all sharing a port, as the resolver expects) and returns an orchestrator
+// wired to them. sources maps node name -> platforms its store covers.
+func platformCluster(t *testing.T, pod *corev1.Pod, nodes []*corev1.Node, sources map[string][]string) (*Orchestrator, client.Client) {
+	t.Helper()
+
+	port := 0
+	objs := []runtime.Object{pod}
+	for i, node := range nodes {
+		store := agent.NewFakeImageStore()
+		if covered, ok := sources[node.Name]; ok {
+			store.AddImage(platformDigest, []byte("image-tar-data"))
+			store.SetPlatforms(platformDigest, covered...)
+		}
+		host := fmt.Sprintf("127.0.0.%d", i+1)
+		lis, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			t.Skipf("loopback address %s unavailable: %v", host, err)
+		}
+		port = lis.Addr().(*net.TCPAddr).Port
+
+		srv := grpc.NewServer()
+		v1.RegisterToteAgentServer(srv, &agent.Server{Store: store, Sessions: session.NewStore()})
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+
+		objs = append(objs, node, agentPod("tote-system", "agent-"+node.Name, node.Name, host))
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(newScheme()).WithStatusSubresource(&v1alpha2.SalvageRecord{}).WithRuntimeObjects(objs...).Build()
+	resolver := NewResolver(cl, "tote-system", port)
+	o := NewOrchestrator(session.NewStore(), resolver, events.NewEmitter(k8sevents.NewFakeRecorder(10)),
+		metrics.NewCounters(prometheus.NewRegistry()), cl, 2, 5*time.Minute, 0)
+	return o, cl
+}
+
+func salvagedFrom(t *testing.T, cl client.Client, pod *corev1.Pod) string {
+	t.Helper()
+	var record v1alpha2.SalvageRecord
+	key := client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name + "-fake-14"}
+	if err := cl.Get(context.Background(), key, &record); err != nil {
+		t.Fatalf("expected SalvageRecord: %v", err)
+	}
+	return record.Spec.SourceNode
+}
+
+func TestOrchestratorSalvage_PrefersSamePlatformSource(t *testing.T) {
+	pod := targetPod()
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "arm64"),
+		platformNode("node-amd", "amd64"),
+		platformNode("node-arm", "arm64"),
+	}, map[string][]string{
+		"node-amd": {"linux/amd64", "linux/arm64"},
+		"node-arm": {"linux/arm64"},
+	})
+
+	err := o.Salvage(context.Background(), pod, platformDigest, "registry.example.com/app:v1", []string{"node-amd", "node-arm"})
+	if err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+	if src := salvagedFrom(t, cl, pod); src != "node-arm" {
+		t.Errorf("expected arm64 source to be preferred, got %s", src)
+	}
+}
+
+func TestOrchestratorSalvage_SkipsSourceWithoutVariant(t *testing.T) {
+	pod := targetPod()
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "arm64"),
+		platformNode("node-a", "amd64"),
+		platformNode("node-b", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+		"node-b": {"linux/amd64", "linux/arm64"},
+	})
+
+	err := o.Salvage(context.Background(), pod, platformDigest, "registry.example.com/app:v1", []string{"node-a", "node-b"})
+	if err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+	if src := salvagedFrom(t, cl, pod); src != "node-b" {
+		t.Errorf("expected node-b (has arm64 content), got %s", src)
+	}
+}
+
+func TestOrchestratorSalvage_NoPlatformVariant(t *testing.T) {
+	pod := targetPod()
+	o, _ := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "arm64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+
+	err := o.Salvage(context.Background(), pod, platformDigest, "registry.example.com/app:v1", []string{"node-a"})
+	if !errors.Is(err, ErrNoPlatformVariant) {
+		t.Fatalf("expected ErrNoPlatformVariant, got %v", err)
+	}
+}
+
+func TestOrchestratorSalvage_PreservesImageNames(t *testing.T) {
+	pod := targetPod()
+	o, _ := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+
+	if err := o.Salvage(context.Background(), pod, platformDigest, "registry.example.com/app:v1", []string{"node-a"}); err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+
+	endpoint, err := o.Resolver.EndpointForNode(context.Background(), "node-target")
+	if err != nil {
+		t.Fatalf("resolving target: %v", err)
+	}
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial target: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := v1.NewToteAgentClient(conn).ResolveTag(context.Background(), &v1.ResolveTagRequest{ImageRef: "registry.example.com/app:v1"})
+	if err != nil {
+		t.Fatalf("ResolveTag: %v", err)
+	}
+	if resp.Digest != platformDigest {
+		t.Errorf("expected pod image tag to resolve on target, got %q", resp.Digest)
+	}
+}
+
+func TestRankSources(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(newScheme()).WithRuntimeObjects(
+		platformNode("a", "amd64"), platformNode("b", "arm64"), platformNode("c", "amd64"), platformNode("d", "arm64"),
+	).Build()
+
+	got := rankSources(context.Background(), cl, []string{"a", "b", "c", "d", "unknown"}, "linux/arm64")
+	want := []string{"b", "d", "a", "c", "unknown"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	if got := rankSources(context.Background(), cl, []string{"a", "b"}, ""); got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected order unchanged without platform, got %v", got)
+	}
+}
+
+func TestOrchestratorSalvage_NotificationContext(t *testing.T) {
+	var received notify.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	pod := targetPod()
+	o, _ := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+	o.Notifier = notify.NewNotifier(srv.URL, []string{notify.EventSalvaged})
+
+	ctx := notify.WithIncident(context.Background(), notify.Incident{CorrelationID: "abc123", Container: "app"})
+	if err := o.Salvage(ctx, pod, platformDigest, "registry.example.com/app:v1", []string{"node-a"}); err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+
+	if received.CorrelationID != "abc123" || received.Container != "app" {
+		t.Errorf("expected incident context on salvaged event, got %+v", received)
+	}
+	if received.SizeBytes != int64(len("image-tar-data")) || received.ImageRef != "registry.example.com/app:v1" {
+		t.Errorf("expected size and image ref, got %+v", received)
+	}
+	if received.DurationSeconds <= 0 {
+		t.Error("expected salvage duration")
+	}
+}
+
+func TestOrchestratorSalvage_RecordsLastSalvageOnWorkload(t *testing.T) {
+	pod := targetPod()
+	pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", Controller: ptr.To(true)}}
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+	ss := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: pod.Namespace}}
+	if err := cl.Create(context.Background(), ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Salvage(context.Background(), pod, platformDigest, "registry.example.com/app:v1", []string{"node-a"}); err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(ss), ss); err != nil {
+		t.Fatal(err)
+	}
+	var last struct{ Result, Pod, SourceNode, TargetNode string }
+	if err := json.Unmarshal([]byte(ss.Annotations[config.AnnotationLastSalvage]), &last); err != nil {
+		t.Fatalf("expected last-salvage annotation, got %q: %v", ss.Annotations[config.AnnotationLastSalvage], err)
+	}
+	if last.Result != "Completed" || last.Pod != pod.Name || last.SourceNode != "node-a" || last.TargetNode != "node-target" {
+		t.Errorf("unexpected last salvage %+v", last)
+	}
+}
+
+func TestOrchestratorSalvage_RecordDetails(t *testing.T) {
+	pod := targetPod()
+	pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", Controller: ptr.To(true)}}
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+	ss := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: pod.Namespace, UID: "ss-uid"}}
+	if err := cl.Create(context.Background(), ss); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := notify.WithIncident(context.Background(), notify.Incident{CorrelationID: "abc123", Container: "app"})
+	if err := o.Salvage(ctx, pod, platformDigest, "registry.example.com/app:v1", []string{"node-a"}); err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+
+	var record v1alpha2.SalvageRecord
+	key := client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name + "-fake-14"}
+	if err := cl.Get(context.Background(), key, &record); err != nil {
+		t.Fatalf("expected SalvageRecord: %v", err)
+	}
+	if record.Spec.Container != "app" {
+		t.Errorf("expected container app, got %q", record.Spec.Container)
+	}
+	if record.Spec.Workload == nil || record.Spec.Workload.Kind != "StatefulSet" || record.Spec.Workload.Name != "db" {
+		t.Errorf("expected workload StatefulSet/db, got %+v", record.Spec.Workload)
+	}
+	if len(record.OwnerReferences) != 1 || record.OwnerReferences[0].Kind != "StatefulSet" || record.OwnerReferences[0].UID != "ss-uid" {
+		t.Errorf("expected owner reference to the StatefulSet, got %+v", record.OwnerReferences)
+	}
+
+	st := record.Status
+	if st.Phase != v1alpha2.PhaseCompleted || st.SizeBytes != int64(len("image-tar-data")) {
+		t.Errorf("expected Completed with size, got %+v", st)
+	}
+	if st.StartedAt == nil || st.CompletedAt == nil || st.Duration == nil || st.CompletedAt.Before(st.StartedAt) {
+		t.Errorf("expected start, completion and duration, got %+v", st)
+	}
+	if !meta.IsStatusConditionTrue(st.Conditions, v1alpha2.ConditionSalvaged) {
+		t.Errorf("expected Salvaged condition, got %+v", st.Conditions)
+	}
+	if meta.FindStatusCondition(st.Conditions, v1alpha2.ConditionBackedUp) != nil {
+		t.Error("expected no BackedUp condition without a backup registry")
+	}
+}
+
+func TestOrchestratorPreseed(t *testing.T) {
+	pod := targetPod()
+	pod.Spec.NodeName = ""
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+
+	for range 2 {
+		err := o.Preseed(context.Background(), pod, platformDigest, "registry.example.com/app:v1", "node-target", []string{"node-a"})
+		if err != nil {
+			t.Fatalf("preseed failed: %v", err)
+		}
+	}
+	if n := testutil.ToFloat64(o.Metrics.Preseeds.WithLabelValues("success")); n != 1 {
+		t.Errorf("expected 1 pre-seed, got %v", n)
+	}
+	var records v1alpha2.SalvageRecordList
+	if err := cl.List(context.Background(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records.Items) != 0 {
+		t.Errorf("expected no SalvageRecord for a pre-seed, got %d", len(records.Items))
+	}
+}
@@ -0,0 +1,255 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/notify"
+)
+
+// DefaultQueueInterval is how often the queue retries jobs when no salvage
+// has finished in the meantime.
+const DefaultQueueInterval = time.Second
+
+// Job is a queued salvage of one image onto one pod's node, or a pre-seed
+// onto a node an unscheduled pod can land on.
+type Job struct {
+	Pod         *corev1.Pod
+	Digest      string
+	ImageRef    string
+	SourceNodes []string
+	Priority    int    // from the namespace's tote.dev/priority, raised during a registry outage; higher runs first
+	Kind        string // owning workload kind
+	Target      string // pre-seed onto this node; empty = salvage onto the pod's node
+
+	incident    notify.Incident
+	hasIncident bool
+	pods        int
+	enqueued    time.Time
+}
+
+func (j *Job) key() string {
+	return j.Digest + "@" + j.node()
+}
+
+// node returns the node the job copies the image to.
+func (j *Job) node() string {
+	if j.Target != "" {
+		return j.Target
+	}
+	return j.Pod.Spec.NodeName
+}
+
+// Queue runs salvages in priority order as the orchestrator's limits allow,
+// instead of rejecting them while all slots are busy. Identical jobs (same
+// digest and target node) are merged. It implements manager.Runnable and
+// manager.LeaderElectionRunnable.
+type Queue struct {
+	Orchestrator *Orchestrator
+	Metrics      *metrics.Counters
+	Interval     time.Duration
+
+	mu      sync.Mutex
+	pending []*Job
+	running map[string]bool
+	wake    chan struct{}
+}
+
+// NewQueue creates a Queue that runs salvages through o.
+func NewQueue(o *Orchestrator, m *metrics.Counters) *Queue {
+	return &Queue{
+		Orchestrator: o,
+		Metrics:      m,
+		Interval:     DefaultQueueInterval,
+		running:      make(map[string]bool),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// NeedLeaderElection returns true so salvages only run on the leader.
+func (q *Queue) NeedLeaderElection() bool {
+	return true
+}
+
+// Enqueue adds a salvage job, carrying over the incident in ctx. It returns
+// false if the same image is already queued or running for the node; a
+// queued duplicate counts one more affected pod instead.
+func (q *Queue) Enqueue(ctx context.Context, job *Job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := job.key()
+	if q.running[key] {
+		q.Metrics.RecordQueueDeduplicated()
+		return false
+	}
+	for _, queued := range q.pending {
+		if queued.key() == key {
+			if queued.Pod.UID != job.Pod.UID {
+				queued.pods++
+			}
+			q.Metrics.RecordQueueDeduplicated()
+			return false
+		}
+	}
+
+	job.incident, job.hasIncident = notify.IncidentFrom(ctx)
+	job.pods = 1
+	job.enqueued = time.Now()
+	q.pending = append(q.pending, job)
+	q.Metrics.SetQueueDepth(len(q.pending))
+	q.signal()
+	return true
+}
+
+// Len returns the number of queued jobs.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start dispatches queued jobs until ctx is cancelled.
+func (q *Queue) Start(ctx context.Context) error {
+	ticker := time.NewTicker(q.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.wake:
+		case <-ticker.C:
+		}
+		q.dispatch(ctx)
+	}
+}
+
+// dispatch starts every queued job whose target node has a free slot,
+// highest priority first. The slot is taken here so that a later, lower
+// priority job cannot claim it first.
+func (q *Queue) dispatch(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.sort()
+	remaining := q.pending[:0]
+	for _, job := range q.pending {
+		if !q.Orchestrator.Limits.acquire(job.node()) {
+			remaining = append(remaining, job)
+			continue
+		}
+		q.running[job.key()] = true
+		go q.run(ctx, job)
+	}
+	q.pending = remaining
+	q.Metrics.SetQueueDepth(len(q.pending))
+}
+
+// sort orders pending jobs by priority, then by how many queued pods wait
+// for the same image, then by workload kind, oldest first.
+func (q *Queue) sort() {
+	affected := make(map[string]int)
+	for _, job := range q.pending {
+		affected[job.Digest] += job.pods
+	}
+	sort.SliceStable(q.pending, func(i, j int) bool {
+		a, b := q.pending[i], q.pending[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if affected[a.Digest] != affected[b.Digest] {
+			return affected[a.Digest] > affected[b.Digest]
+		}
+		if kindRank(a.Kind) != kindRank(b.Kind) {
+			return kindRank(a.Kind) > kindRank(b.Kind)
+		}
+		return a.enqueued.Before(b.enqueued)
+	})
+}
+
+// kindRank orders workload kinds by how widely an outage spreads: a
+// DaemonSet blocks every node, a bare pod only itself. Custom workloads
+// rank with ReplicaSets and Jobs.
+func kindRank(kind string) int {
+	switch kind {
+	case "DaemonSet":
+		return 4
+	case "StatefulSet":
+		return 3
+	case "Deployment":
+		return 2
+	case "Pod":
+		return 0
+	default:
+		return 1
+	}
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) {
+	logger := log.FromContext(ctx).WithName("salvage-queue")
+	started := time.Now()
+	err := q.salvage(ctx, job)
+	q.Orchestrator.Limits.release(job.node())
+
+	q.mu.Lock()
+	delete(q.running, job.key())
+	if errors.Is(err, ErrRateLimited) {
+		// Every source is busy; keep its place in line.
+		q.pending = append(q.pending, job)
+		q.Metrics.SetQueueDepth(len(q.pending))
+	} else {
+		q.Metrics.RecordQueueWait(started.Sub(job.enqueued))
+	}
+	q.mu.Unlock()
+
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		// Retry on the next tick or completion rather than spinning.
+		return
+	case err != nil:
+		logger.Error(err, "salvage failed", "digest", job.Digest, "pod", job.Pod.Name, "namespace", job.Pod.Namespace)
+	}
+	q.signal()
+}
+
+// salvage runs the job against a fresh copy of its pod. Pods deleted while
+// queued are dropped, as are pre-seeds for pods that got scheduled.
+func (q *Queue) salvage(ctx context.Context, job *Job) error {
+	var pod corev1.Pod
+	if err := q.Orchestrator.Client.Get(ctx, client.ObjectKeyFromObject(job.Pod), &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pod.UID != job.Pod.UID || pod.DeletionTimestamp != nil {
+		return nil
+	}
+	if job.hasIncident {
+		ctx = notify.WithIncident(ctx, job.incident)
+	}
+	if job.Target != "" {
+		if pod.Spec.NodeName != "" {
+			return nil
+		}
+		return q.Orchestrator.preseed(ctx, &pod, job.Digest, job.ImageRef, job.Target, job.SourceNodes, true)
+	}
+	return q.Orchestrator.salvage(ctx, &pod, job.Digest, job.ImageRef, job.SourceNodes, true)
+}
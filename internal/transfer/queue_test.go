@@ -0,0 +1,165 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/ppiankov/tote/internal/metrics"
+	"github.com/ppiankov/tote/internal/notify"
+)
+
+func queuedPod(name, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+func TestLimits(t *testing.T) {
+	l := NewLimits(3, 1, 1)
+	if !l.acquire("node-a") {
+		t.Fatal("expected first slot on node-a")
+	}
+	if l.acquire("node-a") {
+		t.Error("expected node-a to be at its limit")
+	}
+	if !l.acquire("node-b") || !l.acquire("node-c") {
+		t.Fatal("expected slots on other nodes")
+	}
+	if l.acquire("node-d") {
+		t.Error("expected global limit to be reached")
+	}
+	l.release("node-a")
+	if !l.acquire("node-a") {
+		t.Error("expected released slot to be reusable")
+	}
+
+	if !l.acquireSource("node-x") {
+		t.Fatal("expected source slot")
+	}
+	if l.acquireSource("node-x") {
+		t.Error("expected node-x to be at its source limit")
+	}
+	l.releaseSource("node-x")
+	if !l.acquireSource("node-x") {
+		t.Error("expected released source slot to be reusable")
+	}
+}
+
+func TestQueue_Deduplicates(t *testing.T) {
+	m := metrics.NewCounters(prometheus.NewRegistry())
+	q := NewQueue(&Orchestrator{Limits: NewLimits(1, 0, 0)}, m)
+	ctx := context.Background()
+
+	if !q.Enqueue(ctx, &Job{Pod: queuedPod("a", "node-1"), Digest: "sha256:aaa"}) {
+		t.Fatal("expected first job to be queued")
+	}
+	if q.Enqueue(ctx, &Job{Pod: queuedPod("b", "node-1"), Digest: "sha256:aaa"}) {
+		t.Error("expected same digest and node to be merged")
+	}
+	if !q.Enqueue(ctx, &Job{Pod: queuedPod("c", "node-2"), Digest: "sha256:aaa"}) {
+		t.Error("expected another node to get its own job")
+	}
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 queued jobs, got %d", q.Len())
+	}
+	if q.pending[0].pods != 2 {
+		t.Errorf("expected merged job to count 2 pods, got %d", q.pending[0].pods)
+	}
+}
+
+func TestQueue_Order(t *testing.T) {
+	q := NewQueue(&Orchestrator{Limits: NewLimits(1, 0, 0)}, metrics.NewCounters(prometheus.NewRegistry()))
+	ctx := context.Background()
+
+	q.Enqueue(ctx, &Job{Pod: queuedPod("bare", "node-1"), Digest: "sha256:bare", Kind: "Pod"})
+	q.Enqueue(ctx, &Job{Pod: queuedPod("ds", "node-1"), Digest: "sha256:ds", Kind: "DaemonSet"})
+	q.Enqueue(ctx, &Job{Pod: queuedPod("wide-1", "node-1"), Digest: "sha256:wide", Kind: "Deployment"})
+	q.Enqueue(ctx, &Job{Pod: queuedPod("wide-2", "node-2"), Digest: "sha256:wide", Kind: "Deployment"})
+	q.Enqueue(ctx, &Job{Pod: queuedPod("urgent", "node-1"), Digest: "sha256:urgent", Kind: "Pod", Priority: 10})
+	q.Enqueue(ctx, &Job{Pod: queuedPod("deploy", "node-1"), Digest: "sha256:deploy", Kind: "Deployment"})
+
+	q.sort()
+	want := []string{"urgent", "wide-1", "wide-2", "ds", "deploy", "bare"}
+	for i, job := range q.pending {
+		if job.Pod.Name != want[i] {
+			t.Fatalf("position %d: expected %s, got %s", i, want[i], job.Pod.Name)
+		}
+	}
+}
+
+func TestQueue_RunsJob(t *testing.T) {
+	pod := targetPod()
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+	q := NewQueue(o, o.Metrics)
+	q.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Start(ctx) }()
+
+	ictx := notify.WithIncident(context.Background(), notify.Incident{Container: "app"})
+	q.Enqueue(ictx, &Job{Pod: pod, Digest: platformDigest, ImageRef: "registry.example.com/app:v1", SourceNodes: []string{"node-a"}})
+
+	waitFor(t, func() bool { return q.Len() == 0 && idle(o.Limits) })
+	if src := salvagedFrom(t, cl, pod); src != "node-a" {
+		t.Errorf("expected salvage from node-a, got %s", src)
+	}
+}
+
+func TestQueue_RequeuesWhileSourcesBusy(t *testing.T) {
+	pod := targetPod()
+	o, cl := platformCluster(t, pod, []*corev1.Node{
+		platformNode("node-target", "amd64"),
+		platformNode("node-a", "amd64"),
+	}, map[string][]string{
+		"node-a": {"linux/amd64"},
+	})
+	o.SetNodeLimits(1, 1)
+	o.Limits.acquireSource("node-a")
+
+	q := NewQueue(o, o.Metrics)
+	q.Enqueue(context.Background(), &Job{Pod: pod, Digest: platformDigest, ImageRef: "registry.example.com/app:v1", SourceNodes: []string{"node-a"}})
+	q.dispatch(context.Background())
+
+	waitFor(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.running) == 0 && len(q.pending) == 1
+	})
+
+	o.Limits.releaseSource("node-a")
+	q.dispatch(context.Background())
+	waitFor(t, func() bool { return q.Len() == 0 && idle(o.Limits) })
+	if src := salvagedFrom(t, cl, pod); src != "node-a" {
+		t.Errorf("expected salvage from node-a, got %s", src)
+	}
+}
+
+func idle(l *Limits) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running == 0
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
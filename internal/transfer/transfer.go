@@ -2,28 +2,45 @@ package transfer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	v1 "github.com/ppiankov/tote/api/v1"
-	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 	"github.com/ppiankov/tote/internal/events"
 	"github.com/ppiankov/tote/internal/metrics"
 	"github.com/ppiankov/tote/internal/notify"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/recovery"
 	"github.com/ppiankov/tote/internal/registry"
 	"github.com/ppiankov/tote/internal/session"
+	"github.com/ppiankov/tote/internal/workload"
 )
 
+// DefaultSlotWait is how long Transfer and Repair wait for a free transfer
+// slot before giving up with ErrRateLimited.
+const DefaultSlotWait = 30 * time.Second
+
+// ErrRateLimited is returned when all salvage slots are in use, on the
+// target node or cluster-wide, or every source node is busy.
+var ErrRateLimited = errors.New("rate limited: max concurrent salvages reached")
+
 // Orchestrator coordinates image salvage between agent nodes.
 type Orchestrator struct {
 	Sessions     *session.Store
@@ -31,9 +48,10 @@ type Orchestrator struct {
 	Emitter      *events.Emitter
 	Metrics      *metrics.Counters
 	Client       client.Client
-	Semaphore    chan struct{}
+	Limits       *Limits
 	SessionTTL   time.Duration
 	MaxImageSize int64
+	SlotWait     time.Duration // how long Transfer and Repair wait for a slot
 
 	// Backup registry push (optional).
 	BackupRegistry         string
@@ -43,6 +61,12 @@ type Orchestrator struct {
 
 	TransportCreds credentials.TransportCredentials // nil = insecure
 	Notifier       *notify.Notifier
+
+	// Owners resolves the workload a salvaged pod belongs to.
+	Owners *owners.Resolver
+
+	// Recoverer restarts a salvaged pod with its tote.dev/recovery strategy.
+	Recoverer *recovery.Recoverer
 }
 
 // NewOrchestrator creates an Orchestrator with the given dependencies.
@@ -56,15 +80,19 @@ func NewOrchestrator(
 	sessionTTL time.Duration,
 	maxImageSize int64,
 ) *Orchestrator {
+	res := owners.NewResolver(c, owners.DefaultTTL)
 	return &Orchestrator{
 		Sessions:     sessions,
 		Resolver:     resolver,
 		Emitter:      emitter,
 		Metrics:      m,
 		Client:       c,
-		Semaphore:    make(chan struct{}, maxConcurrent),
+		Limits:       NewLimits(maxConcurrent, 0, 0),
 		SessionTTL:   sessionTTL,
 		MaxImageSize: maxImageSize,
+		SlotWait:     DefaultSlotWait,
+		Owners:       res,
+		Recoverer:    recovery.New(c, res, emitter, m, ""),
 	}
 }
 
@@ -76,95 +104,377 @@ func (o *Orchestrator) SetBackupRegistry(reg, secret, namespace string, insec bo
 	o.SecretNamespace = namespace
 }
 
-// Salvage attempts to transfer an image from sourceNode to the pod's node.
-// It is one-shot: on failure it emits an event but does not retry.
-func (o *Orchestrator) Salvage(ctx context.Context, pod *corev1.Pod, digest, imageRef, sourceNode string) error {
+// SetNodeLimits bounds concurrent transfers onto one node and served by one
+// node, keeping the cluster-wide limit. Zero means unbounded.
+func (o *Orchestrator) SetNodeLimits(perTarget, perSource int) {
+	o.Limits = NewLimits(o.Limits.max, perTarget, perSource)
+}
+
+// Salvage attempts to transfer an image to the pod's node from the first of
+// sourceNodes that holds complete content for the target node's platform.
+// Nodes running the target platform are tried first. It is one-shot: on
+// failure it emits an event but does not retry.
+func (o *Orchestrator) Salvage(ctx context.Context, pod *corev1.Pod, digest, imageRef string, sourceNodes []string) error {
+	return o.salvage(ctx, pod, digest, imageRef, sourceNodes, false)
+}
+
+// salvage implements Salvage. If held, the caller already holds the target
+// slot.
+func (o *Orchestrator) salvage(ctx context.Context, pod *corev1.Pod, digest, imageRef string, sourceNodes []string, held bool) error {
 	logger := log.FromContext(ctx)
 	o.Metrics.RecordSalvageAttempt()
 	start := time.Now()
 
 	targetNode := pod.Spec.NodeName
 
-	// Acquire semaphore (non-blocking)
-	select {
-	case o.Semaphore <- struct{}{}:
-		defer func() { <-o.Semaphore }()
-	default:
-		logger.Info("salvage rate limited", "digest", digest)
-		return fmt.Errorf("rate limited: max concurrent salvages reached")
+	result, err := o.transfer(ctx, digest, imageRef, targetNode, sourceNodes, held)
+	if errors.Is(err, ErrRateLimited) {
+		return err
+	}
+	if err != nil {
+		o.fail(ctx, pod, digest, err.Error())
+		return err
+	}
+	sourceNode := result.SourceNode
+
+	o.Metrics.RecordSalvageSuccess()
+	o.Metrics.RecordSalvageDuration(time.Since(start))
+	o.Emitter.EmitSalvaged(ctx, pod, digest, sourceNode, targetNode)
+	if o.Notifier != nil {
+		_ = o.Notifier.Notify(ctx, notify.Event{
+			Type:            notify.EventSalvaged,
+			PodName:         pod.Name,
+			Namespace:       pod.Namespace,
+			ImageRef:        imageRef,
+			Digest:          digest,
+			SizeBytes:       result.SizeBytes,
+			SourceNode:      sourceNode,
+			TargetNode:      targetNode,
+			DurationSeconds: time.Since(start).Seconds(),
+		})
 	}
+	logger.Info("salvage complete", "digest", digest, "source", sourceNode, "target", targetNode)
 
-	// Resolve agent endpoints
-	sourceEndpoint, err := o.Resolver.EndpointForNode(ctx, sourceNode)
+	// Record the salvage as a CRD for persistent history.
+	record, err := o.createSalvageRecord(ctx, pod, v1alpha2.SalvageRecordSpec{
+		Digest:     digest,
+		ImageRef:   imageRef,
+		SourceNode: sourceNode,
+		TargetNode: targetNode,
+	}, start, result.SizeBytes)
 	if err != nil {
-		o.fail(pod, digest, fmt.Sprintf("resolving source agent: %v", err))
+		logger.Error(err, "failed to create SalvageRecord")
+	}
+	o.recordLastSalvage(ctx, pod, workload.Salvage{
+		Result: "Completed", Image: imageRef, Digest: digest, SourceNode: sourceNode, TargetNode: targetNode,
+	})
+
+	// Optional: push to backup registry (non-fatal).
+	if o.BackupRegistry != "" {
+		backupRef, err := o.pushToBackupRegistry(ctx, pod, digest, imageRef, result.sourceEndpoint, sourceNode, result.SizeBytes)
+		if record != nil {
+			o.recordBackup(ctx, record, backupRef, err)
+		}
+	}
+
+	// Restart the pod so it starts from the cached image.
+	o.Recoverer.Recover(ctx, pod, imageRef)
+
+	return nil
+}
+
+// Preseed copies an image onto targetNode ahead of pod, which is not
+// scheduled yet but can land there, so it starts without a registry pull.
+// Unlike Salvage it writes no SalvageRecord and leaves the pod alone.
+func (o *Orchestrator) Preseed(ctx context.Context, pod *corev1.Pod, digest, imageRef, targetNode string, sourceNodes []string) error {
+	return o.preseed(ctx, pod, digest, imageRef, targetNode, sourceNodes, false)
+}
+
+func (o *Orchestrator) preseed(ctx context.Context, pod *corev1.Pod, digest, imageRef, targetNode string, sourceNodes []string, held bool) error {
+	// Node status lags behind imports; ask the agent before copying again.
+	if have, missing, err := o.Resolver.CheckImageOnNode(ctx, targetNode, imageRef); err == nil && have == digest && len(missing) == 0 {
+		return nil
+	}
+	result, err := o.transfer(ctx, digest, imageRef, targetNode, sourceNodes, held)
+	if errors.Is(err, ErrRateLimited) {
 		return err
 	}
-	targetEndpoint, err := o.Resolver.EndpointForNode(ctx, targetNode)
 	if err != nil {
-		o.fail(pod, digest, fmt.Sprintf("resolving target agent: %v", err))
+		o.Metrics.RecordPreseed("failed")
 		return err
 	}
+	o.Metrics.RecordPreseed("success")
+	o.Emitter.EmitPreseeded(ctx, pod, digest, result.SourceNode, targetNode)
+	log.FromContext(ctx).Info("image pre-seeded", "digest", digest, "source", result.SourceNode, "target", targetNode, "pod", pod.Name)
+	return nil
+}
 
-	// Create session
-	sess := o.Sessions.Create(digest, sourceNode, targetNode, o.SessionTTL)
-	defer o.Sessions.Delete(sess.Token)
+// TransferResult describes a completed Transfer.
+type TransferResult struct {
+	SourceNode string
+	SizeBytes  int64
+
+	sourceEndpoint string
+}
+
+// Transfer copies an image to targetNode from the first of sourceNodes that
+// holds complete content for the target's platform, trying nodes of that
+// platform first. Every name the source knows the image by, plus imageRef,
+// is recreated on the target. It shares the salvage concurrency limits,
+// waiting up to SlotWait for a free slot and a free source, and returns
+// ErrRateLimited if none came up. It emits no events and records nothing;
+// Salvage and SalvageRequests do that.
+func (o *Orchestrator) Transfer(ctx context.Context, digest, imageRef, targetNode string, sourceNodes []string) (*TransferResult, error) {
+	deadline := time.Now().Add(o.SlotWait)
+	if err := o.waitForSlot(ctx, targetNode, deadline); err != nil {
+		return nil, err
+	}
+	defer o.Limits.release(targetNode)
 
-	// PrepareExport on source agent
-	sizeBytes, err := o.prepareExport(ctx, sourceEndpoint, sess.Token, digest)
+	for {
+		freed := o.Limits.released()
+		result, err := o.transfer(ctx, digest, imageRef, targetNode, sourceNodes, true)
+		if !errors.Is(err, ErrRateLimited) {
+			return result, err
+		}
+		// Every source is busy; retry once one is released.
+		wait := time.NewTimer(time.Until(deadline))
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return nil, ctx.Err()
+		case <-wait.C:
+			return nil, err
+		case <-freed:
+			wait.Stop()
+		}
+	}
+}
+
+// waitForSlot takes a global and a target slot, waiting until deadline for
+// one to be released. It returns ErrRateLimited if none was.
+func (o *Orchestrator) waitForSlot(ctx context.Context, targetNode string, deadline time.Time) error {
+	waitCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	if err := o.Limits.acquireWait(waitCtx, targetNode); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.FromContext(ctx).Info("transfer rate limited", "target", targetNode, "waited", o.SlotWait)
+		return ErrRateLimited
+	}
+	return nil
+}
+
+func (o *Orchestrator) transfer(ctx context.Context, digest, imageRef, targetNode string, sourceNodes []string, held bool) (*TransferResult, error) {
+	logger := log.FromContext(ctx)
+
+	if !held {
+		if !o.Limits.acquire(targetNode) {
+			logger.Info("salvage rate limited", "digest", digest, "target", targetNode)
+			return nil, ErrRateLimited
+		}
+		defer o.Limits.release(targetNode)
+	}
+
+	targetEndpoint, err := o.Resolver.EndpointForNode(ctx, targetNode)
 	if err != nil {
-		o.fail(pod, digest, fmt.Sprintf("prepare export: %v", err))
-		return err
+		return nil, fmt.Errorf("resolving target agent: %w", err)
+	}
+
+	// Export only the target's platform. Without it, sources must hold
+	// every platform of a multi-arch index, which nodes rarely do.
+	platform, err := nodePlatform(ctx, o.Client, targetNode)
+	if err != nil {
+		logger.Info("target platform unknown, exporting all platforms", "node", targetNode, "error", err.Error())
+	}
+
+	// PrepareExport on the first source that covers the platform.
+	var (
+		sourceNode, sourceEndpoint string
+		sess                       session.Session
+		prepared                   *v1.PrepareExportResponse
+		mismatched, busy           []string
+	)
+	err = fmt.Errorf("no source nodes for %s", digest)
+	for _, node := range rankSources(ctx, o.Client, sourceNodes, platform) {
+		if !o.Limits.acquireSource(node) {
+			busy = append(busy, node)
+			continue
+		}
+		endpoint, resolveErr := o.Resolver.EndpointForNode(ctx, node)
+		if resolveErr != nil {
+			o.Limits.releaseSource(node)
+			err = fmt.Errorf("resolving source agent: %w", resolveErr)
+			continue
+		}
+		candidate := o.Sessions.Create(digest, node, targetNode, o.SessionTTL)
+		resp, prepErr := o.prepareExport(ctx, endpoint, candidate.Token, digest, platform)
+		if prepErr != nil {
+			o.Limits.releaseSource(node)
+			o.Sessions.Delete(candidate.Token)
+			switch status.Code(prepErr) {
+			case codes.FailedPrecondition:
+				mismatched = append(mismatched, node)
+			case codes.ResourceExhausted:
+				// The agent is at its own export limit.
+				busy = append(busy, node)
+			}
+			err = fmt.Errorf("prepare export on %s: %w", node, prepErr)
+			logger.V(1).Info("source cannot serve image", "node", node, "digest", digest, "platform", platform, "error", prepErr.Error())
+			continue
+		}
+		sourceNode, sourceEndpoint, sess, prepared = node, endpoint, candidate, resp
+		break
+	}
+	if sourceNode == "" {
+		switch {
+		case len(busy) > 0:
+			// A busy source may still hold the image; try again later.
+			err = fmt.Errorf("%w: source nodes busy (%s)", ErrRateLimited, strings.Join(busy, ", "))
+		case len(mismatched) > 0 && len(mismatched) == len(sourceNodes):
+			err = fmt.Errorf("%w: no node has a complete %s variant of %s (checked: %s)",
+				ErrNoPlatformVariant, platform, digest, strings.Join(mismatched, ", "))
+		}
+		return nil, err
 	}
+	defer o.Limits.releaseSource(sourceNode)
+	defer o.Sessions.Delete(sess.Token)
 
 	// Check image size limit
-	if o.MaxImageSize > 0 && sizeBytes > o.MaxImageSize {
-		reason := fmt.Sprintf("image %s is %d bytes, exceeds limit %d bytes", digest, sizeBytes, o.MaxImageSize)
-		o.fail(pod, digest, reason)
-		return fmt.Errorf("image size exceeded: %s", reason)
+	if o.MaxImageSize > 0 && prepared.SizeBytes > o.MaxImageSize {
+		return nil, fmt.Errorf("image size exceeded: image %s is %d bytes, exceeds limit %d bytes", digest, prepared.SizeBytes, o.MaxImageSize)
+	}
+
+	// ImportFrom on target agent. Carry over every name the source knows
+	// the image by, plus the requested reference, so later pulls by any of
+	// them resolve locally.
+	names := prepared.ImageNames
+	if imageRef != "" && !slices.Contains(names, imageRef) {
+		names = append(names, imageRef)
+	}
+	if err := o.importFrom(ctx, targetEndpoint, sess.Token, digest, sourceEndpoint, sourceNode, names); err != nil {
+		return nil, fmt.Errorf("import: %w", err)
 	}
 
-	// ImportFrom on target agent
-	if err := o.importFrom(ctx, targetEndpoint, sess.Token, digest, sourceEndpoint); err != nil {
-		o.fail(pod, digest, fmt.Sprintf("import: %v", err))
+	return &TransferResult{SourceNode: sourceNode, SizeBytes: prepared.SizeBytes, sourceEndpoint: sourceEndpoint}, nil
+}
+
+// Repair fetches the given missing or corrupt blobs of a local image on the
+// pod's node, trying each source node's agent in turn and then the backup
+// registry. It shares the salvage concurrency limits, waiting up to
+// SlotWait for a free slot; busy source nodes are skipped. Returns an error
+// if no source could supply every blob; the caller then falls back to
+// removal.
+func (o *Orchestrator) Repair(ctx context.Context, pod *corev1.Pod, digest, imageRef string, blobs, sourceNodes []string) error {
+	logger := log.FromContext(ctx)
+	targetNode := pod.Spec.NodeName
+
+	if err := o.waitForSlot(ctx, targetNode, time.Now().Add(o.SlotWait)); err != nil {
 		return err
 	}
+	defer o.Limits.release(targetNode)
 
-	o.Metrics.RecordSalvageSuccess()
-	o.Metrics.RecordSalvageDuration(time.Since(start))
-	o.Emitter.EmitSalvaged(pod, digest, sourceNode, targetNode)
-	if o.Notifier != nil {
-		_ = o.Notifier.Notify(ctx, notify.Event{
-			Type:       "salvaged",
-			PodName:    pod.Name,
-			Namespace:  pod.Namespace,
-			Digest:     digest,
-			SourceNode: sourceNode,
-			TargetNode: targetNode,
-		})
+	targetEndpoint, err := o.Resolver.EndpointForNode(ctx, targetNode)
+	if err != nil {
+		o.Metrics.RecordRepair("failed")
+		return fmt.Errorf("resolving target agent: %w", err)
 	}
-	logger.Info("salvage complete", "digest", digest, "source", sourceNode, "target", targetNode)
 
-	// Record the salvage as a CRD for persistent history.
-	if err := o.createSalvageRecord(ctx, pod, digest, imageRef, sourceNode, targetNode, "Completed", ""); err != nil {
-		logger.Error(err, "failed to create SalvageRecord")
+	var errs []error
+	for _, sourceNode := range sourceNodes {
+		if sourceNode == targetNode {
+			continue
+		}
+		if !o.Limits.acquireSource(sourceNode) {
+			errs = append(errs, fmt.Errorf("node %s: busy", sourceNode))
+			continue
+		}
+		err := o.repairFromNode(ctx, targetEndpoint, digest, blobs, sourceNode, targetNode)
+		o.Limits.releaseSource(sourceNode)
+		if err != nil {
+			logger.V(1).Info("repair from node failed", "digest", digest, "source", sourceNode, "error", err)
+			errs = append(errs, fmt.Errorf("node %s: %w", sourceNode, err))
+			continue
+		}
+		o.Metrics.RecordRepair("peer")
+		o.Emitter.EmitRepaired(ctx, pod, imageRef, len(blobs), "node "+sourceNode)
+		logger.Info("repaired corrupt image from peer", "digest", digest, "blobs", len(blobs), "source", sourceNode, "target", targetNode)
+		return nil
 	}
 
-	// Optional: push to backup registry (non-fatal).
 	if o.BackupRegistry != "" {
-		o.pushToBackupRegistry(ctx, pod, digest, imageRef, sourceEndpoint, sourceNode)
+		backupRef, err := o.repairFromRegistry(ctx, targetEndpoint, digest, imageRef, blobs)
+		if err == nil {
+			o.Metrics.RecordRepair("registry")
+			o.Emitter.EmitRepaired(ctx, pod, imageRef, len(blobs), "registry "+backupRef)
+			logger.Info("repaired corrupt image from backup registry", "digest", digest, "blobs", len(blobs), "source", backupRef, "target", targetNode)
+			return nil
+		}
+		logger.V(1).Info("repair from backup registry failed", "digest", digest, "error", err)
+		errs = append(errs, fmt.Errorf("backup registry: %w", err))
 	}
 
-	// Delete the pod so the owning controller recreates it with the cached image.
-	// Skip standalone pods (no owner) — they cannot be recreated automatically.
-	if len(pod.OwnerReferences) > 0 {
-		if err := o.Client.Delete(ctx, pod); err != nil {
-			logger.Error(err, "failed to delete pod after salvage", "pod", pod.Name)
-		} else {
-			logger.Info("deleted pod for fast recovery", "pod", pod.Name, "namespace", pod.Namespace)
-		}
+	o.Metrics.RecordRepair("failed")
+	if len(errs) == 0 {
+		return fmt.Errorf("no source node or backup registry has image %s", digest)
+	}
+	return fmt.Errorf("repairing image %s: %w", digest, errors.Join(errs...))
+}
+
+func (o *Orchestrator) repairFromNode(ctx context.Context, targetEndpoint, digest string, blobs []string, sourceNode, targetNode string) error {
+	sourceEndpoint, err := o.Resolver.EndpointForNode(ctx, sourceNode)
+	if err != nil {
+		return err
 	}
 
+	sess := o.Sessions.Create(digest, sourceNode, targetNode, o.SessionTTL)
+	defer o.Sessions.Delete(sess.Token)
+
+	if _, err := o.prepareExport(ctx, sourceEndpoint, sess.Token, digest, ""); err != nil {
+		return fmt.Errorf("prepare export: %w", err)
+	}
+	return o.repairImage(ctx, targetEndpoint, &v1.RepairImageRequest{
+		Digest:         digest,
+		Blobs:          blobs,
+		SessionToken:   sess.Token,
+		SourceEndpoint: sourceEndpoint,
+	})
+}
+
+func (o *Orchestrator) repairFromRegistry(ctx context.Context, targetEndpoint, digest, imageRef string, blobs []string) (string, error) {
+	backupRef, err := registry.BackupRef(imageRef, o.BackupRegistry)
+	if err != nil {
+		return "", err
+	}
+	username, password, err := o.loadRegistryCredentials(ctx)
+	if err != nil {
+		return backupRef, err
+	}
+	return backupRef, o.repairImage(ctx, targetEndpoint, &v1.RepairImageRequest{
+		Digest:           digest,
+		Blobs:            blobs,
+		RegistryRef:      backupRef,
+		RegistryUsername: username,
+		RegistryPassword: password,
+		Insecure:         o.BackupRegistryInsecure,
+	})
+}
+
+func (o *Orchestrator) repairImage(ctx context.Context, endpoint string, req *v1.RepairImageRequest) error {
+	conn, err := grpc.NewClient(endpoint, o.dialOption())
+	if err != nil {
+		return fmt.Errorf("connecting to target: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := v1.NewToteAgentClient(conn).RepairImage(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Error)
+	}
 	return nil
 }
 
@@ -175,24 +485,37 @@ func (o *Orchestrator) dialOption() grpc.DialOption {
 	return grpc.WithTransportCredentials(insecure.NewCredentials())
 }
 
-func (o *Orchestrator) fail(pod *corev1.Pod, digest, reason string) {
+func (o *Orchestrator) fail(ctx context.Context, pod *corev1.Pod, digest, reason string) {
 	o.Metrics.RecordSalvageFailure()
-	o.Emitter.EmitSalvageFailed(pod, digest, reason)
+	o.Emitter.EmitSalvageFailed(context.WithoutCancel(ctx), pod, digest, reason)
 	if o.Notifier != nil {
-		_ = o.Notifier.Notify(context.Background(), notify.Event{
-			Type:      "salvage_failed",
+		_ = o.Notifier.Notify(context.WithoutCancel(ctx), notify.Event{
+			Type:      notify.EventSalvageFailed,
 			PodName:   pod.Name,
 			Namespace: pod.Namespace,
 			Digest:    digest,
 			Error:     reason,
 		})
 	}
+	o.recordLastSalvage(context.WithoutCancel(ctx), pod, workload.Salvage{
+		Result: "Failed", Digest: digest, TargetNode: pod.Spec.NodeName, Error: reason,
+	})
 }
 
-func (o *Orchestrator) prepareExport(ctx context.Context, endpoint, token, digest string) (int64, error) {
+// recordLastSalvage annotates the pod's owning workload with the outcome so
+// `kubectl describe` shows it after the pod is gone. Errors are logged only.
+func (o *Orchestrator) recordLastSalvage(ctx context.Context, pod *corev1.Pod, s workload.Salvage) {
+	s.Time = time.Now().UTC().Format(time.RFC3339)
+	s.Pod = pod.Name
+	if err := workload.RecordSalvage(ctx, o.Client, o.Owners, pod, s); err != nil {
+		log.FromContext(ctx).Error(err, "failed to record last salvage on workload", "pod", pod.Name)
+	}
+}
+
+func (o *Orchestrator) prepareExport(ctx context.Context, endpoint, token, digest, platform string) (*v1.PrepareExportResponse, error) {
 	conn, err := grpc.NewClient(endpoint, o.dialOption())
 	if err != nil {
-		return 0, fmt.Errorf("connecting to source: %w", err)
+		return nil, fmt.Errorf("connecting to source: %w", err)
 	}
 	defer func() { _ = conn.Close() }()
 
@@ -200,14 +523,15 @@ func (o *Orchestrator) prepareExport(ctx context.Context, endpoint, token, diges
 	resp, err := client.PrepareExport(ctx, &v1.PrepareExportRequest{
 		SessionToken: token,
 		Digest:       digest,
+		Platform:     platform,
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return resp.SizeBytes, nil
+	return resp, nil
 }
 
-func (o *Orchestrator) importFrom(ctx context.Context, endpoint, token, digest, sourceEndpoint string) error {
+func (o *Orchestrator) importFrom(ctx context.Context, endpoint, token, digest, sourceEndpoint, sourceNode string, names []string) error {
 	conn, err := grpc.NewClient(endpoint, o.dialOption())
 	if err != nil {
 		return fmt.Errorf("connecting to target: %w", err)
@@ -219,6 +543,8 @@ func (o *Orchestrator) importFrom(ctx context.Context, endpoint, token, digest,
 		SessionToken:   token,
 		Digest:         digest,
 		SourceEndpoint: sourceEndpoint,
+		ImageNames:     names,
+		SourceNode:     sourceNode,
 	})
 	if err != nil {
 		return err
@@ -229,48 +555,93 @@ func (o *Orchestrator) importFrom(ctx context.Context, endpoint, token, digest,
 	return nil
 }
 
-// createSalvageRecord persists a SalvageRecord CR for tracking.
-func (o *Orchestrator) createSalvageRecord(ctx context.Context, pod *corev1.Pod, digest, imageRef, sourceNode, targetNode, phase, errMsg string) error {
+// createSalvageRecord persists a completed SalvageRecord CR for tracking.
+// The record is owned by the pod's workload, so it is garbage-collected
+// with it.
+func (o *Orchestrator) createSalvageRecord(ctx context.Context, pod *corev1.Pod, spec v1alpha2.SalvageRecordSpec, start time.Time, sizeBytes int64) (*v1alpha2.SalvageRecord, error) {
 	// Extract short hex from digest (e.g. "sha256:abc123de..." -> "abc123de").
-	shortDigest := digest
-	if idx := strings.Index(digest, ":"); idx >= 0 {
-		shortDigest = digest[idx+1:]
+	shortDigest := spec.Digest
+	if idx := strings.Index(shortDigest, ":"); idx >= 0 {
+		shortDigest = shortDigest[idx+1:]
 	}
 	if len(shortDigest) > 8 {
 		shortDigest = shortDigest[:8]
 	}
 	name := fmt.Sprintf("%s-%s", pod.Name, shortDigest)
 
-	record := &v1alpha1.SalvageRecord{
+	spec.PodName = pod.Name
+	if inc, ok := notify.IncidentFrom(ctx); ok {
+		spec.Container = inc.Container
+	}
+	record := &v1alpha2.SalvageRecord{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: pod.Namespace,
 		},
-		Spec: v1alpha1.SalvageRecordSpec{
-			PodName:    pod.Name,
-			Digest:     digest,
-			ImageRef:   imageRef,
-			SourceNode: sourceNode,
-			TargetNode: targetNode,
-		},
-		Status: v1alpha1.SalvageRecordStatus{
-			Phase:       phase,
-			CompletedAt: time.Now().UTC().Format(time.RFC3339),
-			Error:       errMsg,
-		},
+		Spec: spec,
+	}
+	if owner := workload.Owner(ctx, o.Owners, pod); owner != nil {
+		record.Spec.Workload = &v1alpha2.WorkloadReference{Kind: workload.Kind(owner), Name: owner.GetName()}
+		if err := controllerutil.SetOwnerReference(owner, record, o.Client.Scheme()); err != nil {
+			return nil, fmt.Errorf("setting owner reference: %w", err)
+		}
+	}
+	if err := o.Client.Create(ctx, record); err != nil {
+		return nil, err
 	}
 
-	return o.Client.Create(ctx, record)
+	// Status is a subresource and is not persisted on create.
+	started := metav1.NewTime(start)
+	completed := metav1.Now()
+	record.Status = v1alpha2.SalvageRecordStatus{
+		Phase:              v1alpha2.PhaseCompleted,
+		ObservedGeneration: record.Generation,
+		StartedAt:          &started,
+		CompletedAt:        &completed,
+		Duration:           &metav1.Duration{Duration: completed.Sub(start)},
+		SizeBytes:          sizeBytes,
+	}
+	meta.SetStatusCondition(&record.Status.Conditions, metav1.Condition{
+		Type:               v1alpha2.ConditionSalvaged,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: record.Generation,
+		Reason:             "ImageImported",
+		Message:            fmt.Sprintf("imported on %s from %s", spec.TargetNode, spec.SourceNode),
+	})
+	return record, o.Client.Status().Update(ctx, record)
 }
 
-func (o *Orchestrator) pushToBackupRegistry(ctx context.Context, pod *corev1.Pod, digest, imageRef, sourceEndpoint, sourceNode string) {
+// recordBackup adds the outcome of the backup push to a SalvageRecord.
+// Errors are logged only.
+func (o *Orchestrator) recordBackup(ctx context.Context, record *v1alpha2.SalvageRecord, backupRef string, pushErr error) {
+	cond := metav1.Condition{
+		Type:               v1alpha2.ConditionBackedUp,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: record.Generation,
+		Reason:             "Pushed",
+		Message:            "pushed to " + backupRef,
+	}
+	if pushErr != nil {
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, "PushFailed", pushErr.Error()
+	} else {
+		record.Status.BackupRef = backupRef
+	}
+	meta.SetStatusCondition(&record.Status.Conditions, cond)
+	if err := o.Client.Status().Update(ctx, record); err != nil {
+		log.FromContext(ctx).Error(err, "failed to record backup on SalvageRecord", "name", record.Name)
+	}
+}
+
+// pushToBackupRegistry pushes the salvaged image to the backup registry and
+// returns the backup reference. Failures are reported but not fatal.
+func (o *Orchestrator) pushToBackupRegistry(ctx context.Context, pod *corev1.Pod, digest, imageRef, sourceEndpoint, sourceNode string, sizeBytes int64) (string, error) {
 	logger := log.FromContext(ctx)
 	pushStart := time.Now()
 
 	targetRef, err := registry.BackupRef(imageRef, o.BackupRegistry)
 	if err != nil {
 		logger.Error(err, "failed to construct backup ref", "image", imageRef)
-		return
+		return "", err
 	}
 
 	o.Metrics.RecordPushAttempt()
@@ -278,30 +649,90 @@ func (o *Orchestrator) pushToBackupRegistry(ctx context.Context, pod *corev1.Pod
 	username, password, err := o.loadRegistryCredentials(ctx)
 	if err != nil {
 		logger.Error(err, "failed to load registry credentials")
-		o.Metrics.RecordPushFailure()
-		o.Emitter.EmitPushFailed(pod, digest, targetRef, err.Error())
-		return
+		o.pushFailed(ctx, pod, digest, imageRef, targetRef, err.Error())
+		return targetRef, err
 	}
 
 	if err := o.pushImage(ctx, sourceEndpoint, digest, targetRef, username, password); err != nil {
 		logger.Error(err, "registry push failed (non-fatal)", "digest", digest, "target", targetRef)
-		o.Metrics.RecordPushFailure()
-		o.Emitter.EmitPushFailed(pod, digest, targetRef, err.Error())
-		return
+		o.pushFailed(ctx, pod, digest, imageRef, targetRef, err.Error())
+		return targetRef, err
 	}
 
 	o.Metrics.RecordPushSuccess()
 	o.Metrics.RecordPushDuration(time.Since(pushStart))
-	o.Emitter.EmitPushed(pod, digest, targetRef, sourceNode)
+	o.Emitter.EmitPushed(ctx, pod, digest, targetRef, sourceNode)
+	if o.Notifier != nil {
+		_ = o.Notifier.Notify(ctx, notify.Event{
+			Type:            notify.EventPushed,
+			PodName:         pod.Name,
+			Namespace:       pod.Namespace,
+			ImageRef:        imageRef,
+			Digest:          digest,
+			SizeBytes:       sizeBytes,
+			SourceNode:      sourceNode,
+			BackupRef:       targetRef,
+			DurationSeconds: time.Since(pushStart).Seconds(),
+		})
+	}
+	logger.Info("pushed to backup registry", "digest", digest, "target", targetRef, "source", sourceNode)
+	return targetRef, nil
+}
+
+func (o *Orchestrator) pushFailed(ctx context.Context, pod *corev1.Pod, digest, imageRef, targetRef, reason string) {
+	o.Metrics.RecordPushFailure()
+	o.Emitter.EmitPushFailed(ctx, pod, digest, targetRef, reason)
 	if o.Notifier != nil {
 		_ = o.Notifier.Notify(ctx, notify.Event{
-			Type:      "pushed",
+			Type:      notify.EventPushFailed,
 			PodName:   pod.Name,
 			Namespace: pod.Namespace,
+			ImageRef:  imageRef,
 			Digest:    digest,
+			BackupRef: targetRef,
+			Error:     reason,
 		})
 	}
-	logger.Info("pushed to backup registry", "digest", digest, "target", targetRef, "source", sourceNode)
+}
+
+// PushBackup pushes digest from sourceNode's agent to the backup registry
+// and returns the backup reference. It records push metrics but emits no
+// events.
+func (o *Orchestrator) PushBackup(ctx context.Context, digest, imageRef, sourceNode string) (string, error) {
+	if o.BackupRegistry == "" {
+		return "", errors.New("no backup registry configured")
+	}
+	if imageRef == "" {
+		return "", errors.New("backup push needs an image reference")
+	}
+	targetRef, err := registry.BackupRef(imageRef, o.BackupRegistry)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := o.Resolver.EndpointForNode(ctx, sourceNode)
+	if err != nil {
+		return targetRef, fmt.Errorf("resolving source agent: %w", err)
+	}
+
+	start := time.Now()
+	o.Metrics.RecordPushAttempt()
+	username, password, err := o.loadRegistryCredentials(ctx)
+	if err == nil {
+		err = o.pushImage(ctx, endpoint, digest, targetRef, username, password)
+	}
+	if err != nil {
+		o.Metrics.RecordPushFailure()
+		return targetRef, err
+	}
+	o.Metrics.RecordPushSuccess()
+	o.Metrics.RecordPushDuration(time.Since(start))
+	return targetRef, nil
+}
+
+// BackupCredentials returns the backup registry credentials. Its signature
+// matches registry.HTTPTagResolver.AuthFunc; the host is ignored.
+func (o *Orchestrator) BackupCredentials(ctx context.Context, _ string) (string, string, error) {
+	return o.loadRegistryCredentials(ctx)
 }
 
 func (o *Orchestrator) loadRegistryCredentials(ctx context.Context) (string, string, error) {
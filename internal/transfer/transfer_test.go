@@ -2,6 +2,7 @@ package transfer
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strconv"
 	"strings"
@@ -9,10 +10,13 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	k8sevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -20,7 +24,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	v1 "github.com/ppiankov/tote/api/v1"
-	v1alpha1 "github.com/ppiankov/tote/api/v1alpha1"
+	v1alpha2 "github.com/ppiankov/tote/api/v1alpha2"
 	"github.com/ppiankov/tote/internal/agent"
 	"github.com/ppiankov/tote/internal/config"
 	"github.com/ppiankov/tote/internal/events"
@@ -62,7 +66,7 @@ func targetPod() *corev1.Pod {
 
 func TestOrchestratorSalvage_RateLimited(t *testing.T) {
 	scheme := newScheme()
-	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(targetPod()).Build()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1alpha2.SalvageRecord{}).WithRuntimeObjects(targetPod()).Build()
 	rec := k8sevents.NewFakeRecorder(10)
 	reg := prometheus.NewRegistry()
 
@@ -71,22 +75,22 @@ func TestOrchestratorSalvage_RateLimited(t *testing.T) {
 
 	o := NewOrchestrator(sessions, resolver, events.NewEmitter(rec), metrics.NewCounters(reg), cl, 1, 5*time.Minute, 0)
 
-	// Fill the semaphore
-	o.Semaphore <- struct{}{}
+	// Fill the only slot
+	o.Limits.acquire("node-other")
 
 	pod := targetPod()
-	err := o.Salvage(context.Background(), pod, "sha256:abc", "registry.example.com/app:v1", "node-source")
-	if err == nil {
-		t.Fatal("expected rate limit error")
+	err := o.Salvage(context.Background(), pod, "sha256:abc", "registry.example.com/app:v1", []string{"node-source"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
 	}
 
-	<-o.Semaphore
+	o.Limits.release("node-other")
 }
 
 func TestOrchestratorSalvage_NoSourceAgent(t *testing.T) {
 	scheme := newScheme()
 	pod := targetPod()
-	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).Build()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1alpha2.SalvageRecord{}).WithRuntimeObjects(pod).Build()
 	rec := k8sevents.NewFakeRecorder(10)
 	reg := prometheus.NewRegistry()
 
@@ -95,7 +99,7 @@ func TestOrchestratorSalvage_NoSourceAgent(t *testing.T) {
 
 	o := NewOrchestrator(sessions, resolver, events.NewEmitter(rec), metrics.NewCounters(reg), cl, 2, 5*time.Minute, 0)
 
-	err := o.Salvage(context.Background(), pod, "sha256:abc", "registry.example.com/app:v1", "node-source")
+	err := o.Salvage(context.Background(), pod, "sha256:abc", "registry.example.com/app:v1", []string{"node-source"})
 	if err == nil {
 		t.Fatal("expected error when no agent pod exists")
 	}
@@ -160,10 +164,10 @@ func TestOrchestratorSalvage_EndToEnd(t *testing.T) {
 	_ = resp
 }
 
-func TestOrchestratorSemaphore(t *testing.T) {
+func TestOrchestratorLimitsReleased(t *testing.T) {
 	scheme := newScheme()
 	pod := targetPod()
-	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).Build()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1alpha2.SalvageRecord{}).WithRuntimeObjects(pod).Build()
 	rec := k8sevents.NewFakeRecorder(10)
 	reg := prometheus.NewRegistry()
 
@@ -172,14 +176,15 @@ func TestOrchestratorSemaphore(t *testing.T) {
 
 	o := NewOrchestrator(sessions, resolver, events.NewEmitter(rec), metrics.NewCounters(reg), cl, 2, 5*time.Minute, 0)
 
-	// Salvage will fail (no agent pods), but semaphore should be released
-	_ = o.Salvage(context.Background(), pod, "sha256:abc", "registry.example.com/app:v1", "node-source")
+	// Salvage will fail (no agent pods), but its slot should be released
+	_ = o.Salvage(context.Background(), pod, "sha256:abc", "registry.example.com/app:v1", []string{"node-source"})
 
-	// Verify semaphore was released by acquiring both slots
-	o.Semaphore <- struct{}{}
-	o.Semaphore <- struct{}{}
-	<-o.Semaphore
-	<-o.Semaphore
+	// Verify the slot was released by acquiring both
+	if !o.Limits.acquire("node-a") || !o.Limits.acquire("node-b") {
+		t.Fatal("expected both slots to be free")
+	}
+	o.Limits.release("node-a")
+	o.Limits.release("node-b")
 }
 
 func ownedPod() *corev1.Pod {
@@ -195,6 +200,7 @@ func ownedPod() *corev1.Pod {
 				Kind:       "ReplicaSet",
 				Name:       "my-rs",
 				UID:        "uid-1",
+				Controller: ptr.To(true),
 			}},
 		},
 		Spec: corev1.PodSpec{
@@ -203,10 +209,17 @@ func ownedPod() *corev1.Pod {
 	}
 }
 
+// ownerReplicaSet is the controller of ownedPod.
+func ownerReplicaSet() *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-rs", Namespace: "default", UID: "uid-1"},
+	}
+}
+
 // salvageOrchestrator sets up a full orchestrator with a running agent server
 // for end-to-end salvage tests. Both source and target resolve to the same
 // gRPC server (shared fake store).
-func salvageOrchestrator(t *testing.T, pod *corev1.Pod) (*Orchestrator, *k8sevents.FakeRecorder, client.Client) {
+func salvageOrchestrator(t *testing.T, pod *corev1.Pod, objs ...runtime.Object) (*Orchestrator, *k8sevents.FakeRecorder, client.Client) {
 	t.Helper()
 
 	store := agent.NewFakeImageStore()
@@ -220,11 +233,11 @@ func salvageOrchestrator(t *testing.T, pod *corev1.Pod) (*Orchestrator, *k8seven
 	port, _ := strconv.Atoi(portStr)
 
 	scheme := newScheme()
-	cl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1alpha2.SalvageRecord{}).WithRuntimeObjects(
 		pod,
 		agentPod("tote-system", "agent-source", "node-source", host),
 		agentPod("tote-system", "agent-target", "node-target", host),
-	).Build()
+	).WithRuntimeObjects(objs...).Build()
 
 	rec := k8sevents.NewFakeRecorder(10)
 	reg := prometheus.NewRegistry()
@@ -236,9 +249,9 @@ func salvageOrchestrator(t *testing.T, pod *corev1.Pod) (*Orchestrator, *k8seven
 
 func TestOrchestratorSalvage_DeletesPodWithOwner(t *testing.T) {
 	pod := ownedPod()
-	o, _, cl := salvageOrchestrator(t, pod)
+	o, _, cl := salvageOrchestrator(t, pod, ownerReplicaSet())
 
-	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", "node-source")
+	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", []string{"node-source"})
 	if err != nil {
 		t.Fatalf("salvage failed: %v", err)
 	}
@@ -251,7 +264,7 @@ func TestOrchestratorSalvage_DeletesPodWithOwner(t *testing.T) {
 	}
 
 	// SalvageRecord should still exist even though the pod was deleted.
-	var record v1alpha1.SalvageRecord
+	var record v1alpha2.SalvageRecord
 	key := client.ObjectKey{Namespace: pod.Namespace, Name: "owned-pod-aaa"}
 	if err := cl.Get(context.Background(), key, &record); err != nil {
 		t.Fatalf("expected SalvageRecord to exist after pod deletion: %v", err)
@@ -268,7 +281,7 @@ func TestOrchestratorSalvage_StandalonePodNotDeleted(t *testing.T) {
 	pod := targetPod() // no owner references
 	o, _, cl := salvageOrchestrator(t, pod)
 
-	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", "node-source")
+	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", []string{"node-source"})
 	if err != nil {
 		t.Fatalf("salvage failed: %v", err)
 	}
@@ -281,7 +294,7 @@ func TestOrchestratorSalvage_StandalonePodNotDeleted(t *testing.T) {
 	}
 
 	// A SalvageRecord should be created for the salvage.
-	var record v1alpha1.SalvageRecord
+	var record v1alpha2.SalvageRecord
 	key := client.ObjectKey{Namespace: pod.Namespace, Name: "failing-pod-aaa"}
 	if err := cl.Get(context.Background(), key, &record); err != nil {
 		t.Fatalf("expected SalvageRecord to exist: %v", err)
@@ -294,6 +307,51 @@ func TestOrchestratorSalvage_StandalonePodNotDeleted(t *testing.T) {
 	}
 }
 
+func TestOrchestratorSalvage_OrphanedPodNotDeleted(t *testing.T) {
+	replaced := ownerReplicaSet()
+	replaced.UID = "uid-2"
+	deleting := ownerReplicaSet()
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	deleting.Finalizers = []string{"foregroundDeletion"}
+
+	tests := []struct {
+		name  string
+		owner []runtime.Object
+	}{
+		{"controller missing", nil},
+		{"controller replaced", []runtime.Object{replaced}},
+		{"controller deleting", []runtime.Object{deleting}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := ownedPod()
+			o, _, cl := salvageOrchestrator(t, pod, tt.owner...)
+
+			if err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", []string{"node-source"}); err != nil {
+				t.Fatalf("salvage failed: %v", err)
+			}
+			// Nothing would recreate the pod, so it must survive.
+			if err := cl.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+				t.Errorf("expected pod to still exist: %v", err)
+			}
+		})
+	}
+}
+
+func TestOrchestratorSalvage_RecoveryWait(t *testing.T) {
+	pod := ownedPod()
+	pod.Annotations[config.AnnotationRecovery] = config.RecoveryWait
+	o, _, cl := salvageOrchestrator(t, pod, ownerReplicaSet())
+
+	if err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", []string{"node-source"}); err != nil {
+		t.Fatalf("salvage failed: %v", err)
+	}
+	// The pod is left to kubelet's pull backoff.
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+		t.Errorf("expected pod to still exist: %v", err)
+	}
+}
+
 func TestOrchestratorSalvage_ImageSizeExceeded(t *testing.T) {
 	pod := targetPod()
 	o, _, _ := salvageOrchestrator(t, pod)
@@ -301,7 +359,7 @@ func TestOrchestratorSalvage_ImageSizeExceeded(t *testing.T) {
 	// Image data is 14 bytes ("image-tar-data"). Set limit to 10 bytes.
 	o.MaxImageSize = 10
 
-	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", "node-source")
+	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", []string{"node-source"})
 	if err == nil {
 		t.Fatal("expected error for oversized image")
 	}
@@ -317,8 +375,148 @@ func TestOrchestratorSalvage_ImageSizeWithinLimit(t *testing.T) {
 	// Image data is 14 bytes. Set limit to 100 bytes — should pass.
 	o.MaxImageSize = 100
 
-	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", "node-source")
+	err := o.Salvage(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1", []string{"node-source"})
 	if err != nil {
 		t.Fatalf("salvage should succeed within size limit: %v", err)
 	}
 }
+
+// repairOrchestrator sets up an orchestrator whose source and target agents
+// share one fake store holding sha256:aaa with a missing layer blob that the
+// store can also serve, as a healthy peer would.
+func repairOrchestrator(t *testing.T, pod *corev1.Pod) (*Orchestrator, *agent.FakeImageStore, *k8sevents.FakeRecorder) {
+	t.Helper()
+
+	store := agent.NewFakeImageStore()
+	store.AddImage("sha256:aaa", []byte("image-tar-data"))
+	store.AddBlob("sha256:layer", []byte("layer-data"))
+	store.SetMissingBlobs("sha256:aaa", []string{"sha256:layer"})
+	sessions := session.NewStore()
+
+	addr, cleanup := startAgentServer(t, store, sessions)
+	t.Cleanup(cleanup)
+
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	scheme := newScheme()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1alpha2.SalvageRecord{}).WithRuntimeObjects(
+		pod,
+		agentPod("tote-system", "agent-source", "node-source", host),
+		agentPod("tote-system", "agent-target", "node-target", host),
+	).Build()
+
+	rec := k8sevents.NewFakeRecorder(10)
+	reg := prometheus.NewRegistry()
+	resolver := NewResolver(cl, "tote-system", port)
+	o := NewOrchestrator(sessions, resolver, events.NewEmitter(rec), metrics.NewCounters(reg), cl, 2, 5*time.Minute, 0)
+
+	return o, store, rec
+}
+
+func TestOrchestratorRepair_FromPeer(t *testing.T) {
+	pod := targetPod()
+	o, store, rec := repairOrchestrator(t, pod)
+
+	err := o.Repair(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1",
+		[]string{"sha256:layer"}, []string{"node-target", "node-source"})
+	if err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+
+	if err := store.Verify(context.Background(), "sha256:aaa"); err != nil {
+		t.Errorf("expected image to verify after repair, got %v", err)
+	}
+	select {
+	case event := <-rec.Events:
+		if !strings.Contains(event, events.ReasonRepaired) || !strings.Contains(event, "node node-source") {
+			t.Errorf("expected repaired event naming source node, got: %s", event)
+		}
+	default:
+		t.Error("expected repaired event")
+	}
+}
+
+func TestOrchestratorRepair_NoSource(t *testing.T) {
+	pod := targetPod()
+	o, _, _ := repairOrchestrator(t, pod)
+
+	// The only candidate is the target node itself and no backup registry
+	// is configured.
+	err := o.Repair(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1",
+		[]string{"sha256:layer"}, []string{"node-target"})
+	if err == nil {
+		t.Fatal("expected error with no source for missing blobs")
+	}
+}
+
+func TestOrchestratorRepair_RateLimited(t *testing.T) {
+	pod := targetPod()
+	o, _, _ := repairOrchestrator(t, pod)
+
+	o.SlotWait = 10 * time.Millisecond
+	o.Limits.acquire("node-a")
+	o.Limits.acquire("node-b")
+	defer func() { o.Limits.release("node-a"); o.Limits.release("node-b") }()
+
+	err := o.Repair(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1",
+		[]string{"sha256:layer"}, []string{"node-source"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestOrchestratorRepair_WaitsForSlot(t *testing.T) {
+	pod := targetPod()
+	o, _, _ := repairOrchestrator(t, pod)
+
+	o.Limits.acquire("node-a")
+	o.Limits.acquire("node-b")
+	defer o.Limits.release("node-b")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		o.Limits.release("node-a")
+	}()
+
+	err := o.Repair(context.Background(), pod, "sha256:aaa", "registry.example.com/app:v1",
+		[]string{"sha256:layer"}, []string{"node-source"})
+	if err != nil {
+		t.Fatalf("expected repair once a slot was released, got %v", err)
+	}
+}
+
+func TestOrchestratorTransfer_WaitsForSource(t *testing.T) {
+	pod := targetPod()
+	o, store, _ := repairOrchestrator(t, pod)
+	store.SetMissingBlobs("sha256:aaa", nil)
+	o.SetNodeLimits(0, 1)
+
+	o.Limits.acquireSource("node-source")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		o.Limits.releaseSource("node-source")
+	}()
+
+	result, err := o.Transfer(context.Background(), "sha256:aaa", "registry.example.com/app:v1", "node-target", []string{"node-source"})
+	if err != nil {
+		t.Fatalf("expected transfer once the source was released, got %v", err)
+	}
+	if result.SourceNode != "node-source" {
+		t.Errorf("expected node-source, got %s", result.SourceNode)
+	}
+}
+
+func TestOrchestratorTransfer_RateLimited(t *testing.T) {
+	pod := targetPod()
+	o, _, _ := repairOrchestrator(t, pod)
+	o.SlotWait = 10 * time.Millisecond
+	o.SetNodeLimits(1, 0)
+
+	o.Limits.acquire("node-target")
+	defer o.Limits.release("node-target")
+
+	_, err := o.Transfer(context.Background(), "sha256:aaa", "registry.example.com/app:v1", "node-target", []string{"node-source"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+}
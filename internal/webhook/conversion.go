@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConversionPath is where controller-runtime serves conversion reviews.
+const ConversionPath = "/convert"
+
+// SalvageRecordCRD is the CRD whose versions the conversion webhook converts.
+const SalvageRecordCRD = "salvagerecords.tote.dev"
+
+// EnableConversion points the CRD's conversion at the controller's webhook
+// service on port 443, trusting caBundle. The CRD ships in the chart's crds/
+// directory, which Helm does not template, so the controller sets this at
+// startup.
+func EnableConversion(ctx context.Context, c client.Client, crdName, namespace, service string, caBundle []byte) error {
+	var crd apiextensionsv1.CustomResourceDefinition
+	if err := c.Get(ctx, client.ObjectKey{Name: crdName}, &crd); err != nil {
+		return fmt.Errorf("getting CRD %s: %w", crdName, err)
+	}
+
+	patch := client.MergeFrom(crd.DeepCopy())
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: namespace,
+					Name:      service,
+					Path:      ptr.To(ConversionPath),
+					Port:      ptr.To[int32](443),
+				},
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+	if err := c.Patch(ctx, &crd, patch); err != nil {
+		return fmt.Errorf("patching CRD %s conversion: %w", crdName, err)
+	}
+	return nil
+}
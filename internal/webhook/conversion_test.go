@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnableConversion(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = apiextensionsv1.AddToScheme(s)
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: SalvageRecordCRD},
+		Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "tote.dev"},
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(crd).Build()
+
+	if err := EnableConversion(context.Background(), cl, SalvageRecordCRD, "tote-system", "tote-webhook", []byte("ca")); err != nil {
+		t.Fatalf("EnableConversion: %v", err)
+	}
+
+	var got apiextensionsv1.CustomResourceDefinition
+	if err := cl.Get(context.Background(), client.ObjectKey{Name: SalvageRecordCRD}, &got); err != nil {
+		t.Fatal(err)
+	}
+	conv := got.Spec.Conversion
+	if conv == nil || conv.Strategy != apiextensionsv1.WebhookConverter || conv.Webhook == nil {
+		t.Fatalf("expected webhook conversion, got %+v", conv)
+	}
+	svc := conv.Webhook.ClientConfig.Service
+	if svc.Namespace != "tote-system" || svc.Name != "tote-webhook" || *svc.Path != ConversionPath || *svc.Port != 443 {
+		t.Errorf("unexpected service reference %+v", svc)
+	}
+	if string(conv.Webhook.ClientConfig.CABundle) != "ca" {
+		t.Errorf("expected CA bundle, got %q", conv.Webhook.ClientConfig.CABundle)
+	}
+	if got.Spec.Group != "tote.dev" {
+		t.Error("expected the rest of the spec to be kept")
+	}
+}
+
+func TestEnableConversion_MissingCRD(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = apiextensionsv1.AddToScheme(s)
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+
+	if err := EnableConversion(context.Background(), cl, SalvageRecordCRD, "tote-system", "tote-webhook", nil); err == nil {
+		t.Error("expected error for missing CRD")
+	}
+}
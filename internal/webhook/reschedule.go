@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/workload"
+)
+
+// ReschedulePath is where the pod steering webhook is served.
+const ReschedulePath = "/mutate-pods"
+
+// rescheduleWeight is the weight of the preferred node affinity term added
+// to steered pods, the maximum the scheduler allows.
+const rescheduleWeight = 100
+
+// PodSteerer adds a preferred node affinity to new pods of a workload with a
+// pending tote.dev/reschedule annotation, so the scheduler places them on
+// nodes that already cache the image the deleted pod failed to pull.
+type PodSteerer struct {
+	// Owners resolves the pod's workload. It should not cache: the
+	// annotation is written moments before the replacement is created.
+	Owners *owners.Resolver
+}
+
+// Handle patches pods on CREATE. It never rejects a pod.
+func (s *PodSteerer) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		return admission.Allowed("") // fail open on decode error
+	}
+	if pod.Spec.NodeName != "" || len(pod.OwnerReferences) == 0 {
+		return admission.Allowed("")
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = req.Namespace
+	}
+	owner := workload.Owner(ctx, s.Owners, &pod)
+	if owner == nil {
+		return admission.Allowed("")
+	}
+	r, ok := workload.PendingReschedule(owner, time.Now())
+	if !ok {
+		return admission.Allowed("")
+	}
+
+	steer(&pod, r)
+	raw, err := json.Marshal(&pod)
+	if err != nil {
+		return admission.Allowed("")
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}
+
+// steer adds a preferred node affinity for r.Nodes to the pod and records
+// the digest in tote.dev/rescheduled, so the controller transfers the image
+// instead of rescheduling the pod again if the scheduler picks another node.
+func steer(pod *corev1.Pod, r workload.Reschedule) {
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	na := pod.Spec.Affinity.NodeAffinity
+	na.PreferredDuringSchedulingIgnoredDuringExecution = append(na.PreferredDuringSchedulingIgnoredDuringExecution, corev1.PreferredSchedulingTerm{
+		Weight: rescheduleWeight,
+		Preference: corev1.NodeSelectorTerm{
+			MatchFields: []corev1.NodeSelectorRequirement{{
+				Key:      "metadata.name",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   r.Nodes,
+			}},
+		},
+	})
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[config.AnnotationRescheduled] = r.Digest
+}
+
+// InjectCABundle sets the CA bundle of every webhook in the named
+// MutatingWebhookConfiguration. The chart cannot render the CA of a
+// certificate it generates in another manifest, so the controller sets it at
+// startup, as it does for the SalvageRecord conversion.
+func InjectCABundle(ctx context.Context, c client.Client, name string, caBundle []byte) error {
+	var cfg admissionregistrationv1.MutatingWebhookConfiguration
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, &cfg); err != nil {
+		return fmt.Errorf("getting MutatingWebhookConfiguration %s: %w", name, err)
+	}
+	patch := client.MergeFrom(cfg.DeepCopy())
+	for i := range cfg.Webhooks {
+		cfg.Webhooks[i].ClientConfig.CABundle = caBundle
+	}
+	if err := c.Patch(ctx, &cfg, patch); err != nil {
+		return fmt.Errorf("patching MutatingWebhookConfiguration %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/owners"
+	"github.com/ppiankov/tote/internal/workload"
+)
+
+func steererWith(expires time.Time) *PodSteerer {
+	s := runtime.NewScheme()
+	_ = appsv1.AddToScheme(s)
+	hint, _ := json.Marshal(workload.Reschedule{Digest: "sha256:abc", Nodes: []string{"node-a", "node-b"}, Expires: expires})
+	return &PodSteerer{Owners: owners.NewResolver(fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "db", Namespace: "default",
+			Annotations: map[string]string{config.AnnotationReschedule: string(hint)},
+		}},
+	).Build(), 0)}
+}
+
+func podRequest(t *testing.T, pod *corev1.Pod) admission.Request {
+	t.Helper()
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Namespace: "default",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func statefulPod() *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "db-0",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", Controller: ptr.To(true)}},
+	}}
+}
+
+func TestPodSteerer_AddsPreferredAffinity(t *testing.T) {
+	resp := steererWith(time.Now().Add(time.Minute)).Handle(context.Background(), podRequest(t, statefulPod()))
+	if !resp.Allowed || len(resp.Patches) == 0 {
+		t.Fatalf("expected an allowed response with patches, got %+v", resp)
+	}
+
+	patched := map[string]bool{}
+	for _, p := range resp.Patches {
+		patched[p.Path] = true
+	}
+	if !patched["/spec/affinity"] || !patched["/metadata/annotations"] {
+		t.Errorf("expected affinity and annotation patches, got %v", resp.Patches)
+	}
+}
+
+func TestPodSteerer_Skips(t *testing.T) {
+	bound := statefulPod()
+	bound.Spec.NodeName = "node-x"
+
+	tests := []struct {
+		name    string
+		steerer *PodSteerer
+		pod     *corev1.Pod
+	}{
+		{"expired", steererWith(time.Now().Add(-time.Minute)), statefulPod()},
+		{"standalone", steererWith(time.Now().Add(time.Minute)), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bare"}}},
+		{"already bound", steererWith(time.Now().Add(time.Minute)), bound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.steerer.Handle(context.Background(), podRequest(t, tt.pod))
+			if !resp.Allowed || len(resp.Patches) != 0 {
+				t.Errorf("expected allowed without patches, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestSteer(t *testing.T) {
+	pod := statefulPod()
+	pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{Weight: 10}},
+	}}
+	steer(pod, workload.Reschedule{Digest: "sha256:abc", Nodes: []string{"node-a"}})
+
+	terms := pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if len(terms) != 2 {
+		t.Fatalf("expected the pod's own term to be kept, got %d terms", len(terms))
+	}
+	field := terms[1].Preference.MatchFields[0]
+	if terms[1].Weight != rescheduleWeight || field.Key != "metadata.name" || field.Values[0] != "node-a" {
+		t.Errorf("unexpected steering term %+v", terms[1])
+	}
+	if pod.Annotations[config.AnnotationRescheduled] != "sha256:abc" {
+		t.Errorf("expected %s annotation, got %v", config.AnnotationRescheduled, pod.Annotations)
+	}
+}
+
+func TestInjectCABundle(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = admissionregistrationv1.AddToScheme(s)
+	cfg := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "tote"},
+		Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "steer.tote.dev"}},
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(cfg).Build()
+
+	if err := InjectCABundle(context.Background(), cl, "tote", []byte("ca")); err != nil {
+		t.Fatalf("InjectCABundle: %v", err)
+	}
+	var got admissionregistrationv1.MutatingWebhookConfiguration
+	if err := cl.Get(context.Background(), client.ObjectKey{Name: "tote"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Webhooks[0].ClientConfig.CABundle) != "ca" {
+		t.Errorf("expected CA bundle, got %q", got.Webhooks[0].ClientConfig.CABundle)
+	}
+
+	if err := InjectCABundle(context.Background(), cl, "missing", nil); err == nil {
+		t.Error("expected error for missing configuration")
+	}
+}
@@ -4,16 +4,58 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/ppiankov/tote/internal/config"
 )
 
-var knownAnnotations = map[string]bool{
-	"tote.dev/allow":        true,
-	"tote.dev/auto-salvage": true,
+// knownAnnotations maps each tote.dev annotation on a Pod or Namespace to a
+// check of its value.
+var knownAnnotations = map[string]func(key, value string) string{
+	config.AnnotationNamespaceAllow:    boolean,
+	config.AnnotationPodAutoSalvage:    boolean,
+	config.AnnotationNamespacePriority: integer,
+	config.AnnotationSalvageStrategy:   strategy,
+	config.AnnotationRecovery:          recovery,
+	config.AnnotationRescheduled:       anyValue,
+}
+
+func boolean(key, value string) string {
+	if value != "true" && value != "false" {
+		return fmt.Sprintf("annotation %q must be \"true\" or \"false\", got %q", key, value)
+	}
+	return ""
+}
+
+func integer(key, value string) string {
+	if _, err := strconv.Atoi(value); err != nil {
+		return fmt.Sprintf("annotation %q must be an integer, got %q", key, value)
+	}
+	return ""
 }
 
+func strategy(key, value string) string {
+	if config.ValidateSalvageStrategy(value) != nil {
+		return fmt.Sprintf("annotation %q must be %q or %q, got %q", key, config.StrategyTransfer, config.StrategyReschedule, value)
+	}
+	return ""
+}
+
+func recovery(key, value string) string {
+	if config.ValidateRecovery(value) != nil {
+		return fmt.Sprintf("annotation %q must be %q, %q, %q or %q, got %q", key,
+			config.RecoveryDelete, config.RecoveryWait, config.RecoveryEvict, config.RecoveryRestart, value)
+	}
+	return ""
+}
+
+func anyValue(_, _ string) string { return "" }
+
 // AnnotationValidator rejects Pods and Namespaces with unknown tote.dev/*
 // annotations or invalid annotation values.
 type AnnotationValidator struct{}
@@ -33,13 +75,13 @@ func (v *AnnotationValidator) Handle(_ context.Context, req admission.Request) a
 		if !strings.HasPrefix(key, "tote.dev/") {
 			continue
 		}
-		if !knownAnnotations[key] {
+		check, ok := knownAnnotations[key]
+		if !ok {
 			return admission.Denied(fmt.Sprintf(
-				"unknown tote.dev annotation %q; valid annotations: tote.dev/allow, tote.dev/auto-salvage", key))
+				"unknown tote.dev annotation %q; valid annotations: %s", key, strings.Join(slices.Sorted(maps.Keys(knownAnnotations)), ", ")))
 		}
-		if value != "true" && value != "false" {
-			return admission.Denied(fmt.Sprintf(
-				"annotation %q must be \"true\" or \"false\", got %q", key, value))
+		if msg := check(key, value); msg != "" {
+			return admission.Denied(msg)
 		}
 	}
 	return admission.Allowed("")
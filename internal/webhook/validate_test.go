@@ -84,3 +84,33 @@ func TestAnnotationValidator_FailsOpenOnBadJSON(t *testing.T) {
 		t.Error("expected fail-open on bad JSON")
 	}
 }
+
+func TestAnnotationValidator_Priority(t *testing.T) {
+	v := &AnnotationValidator{}
+	if resp := v.Handle(context.Background(), makeRequest(map[string]string{"tote.dev/priority": "10"})); !resp.Allowed {
+		t.Errorf("expected integer priority to be allowed: %v", resp.Result)
+	}
+	if resp := v.Handle(context.Background(), makeRequest(map[string]string{"tote.dev/priority": "high"})); resp.Allowed {
+		t.Error("expected non-integer priority to be denied")
+	}
+}
+
+func TestAnnotationValidator_SalvageStrategy(t *testing.T) {
+	v := &AnnotationValidator{}
+	if resp := v.Handle(context.Background(), makeRequest(map[string]string{"tote.dev/salvage-strategy": "reschedule"})); !resp.Allowed {
+		t.Errorf("expected reschedule strategy to be allowed: %v", resp.Result)
+	}
+	if resp := v.Handle(context.Background(), makeRequest(map[string]string{"tote.dev/salvage-strategy": "move"})); resp.Allowed {
+		t.Error("expected unknown strategy to be denied")
+	}
+}
+
+func TestAnnotationValidator_Recovery(t *testing.T) {
+	v := &AnnotationValidator{}
+	if resp := v.Handle(context.Background(), makeRequest(map[string]string{"tote.dev/recovery": "evict"})); !resp.Allowed {
+		t.Errorf("expected evict recovery to be allowed: %v", resp.Result)
+	}
+	if resp := v.Handle(context.Background(), makeRequest(map[string]string{"tote.dev/recovery": "drain"})); resp.Allowed {
+		t.Error("expected unknown recovery strategy to be denied")
+	}
+}
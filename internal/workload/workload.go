@@ -0,0 +1,141 @@
+// Package workload resolves the workload that owns a pod and records
+// salvage outcomes on it, so the evidence outlives the salvaged pod.
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/owners"
+)
+
+// Owner returns the metadata of the workload that owns the pod, as found by
+// owners.Resolver.Workload: the Deployment behind a ReplicaSet, the CronJob
+// behind a Job, or a custom resource such as an Argo Rollout. It returns nil
+// for standalone pods and when the owner cannot be read.
+func Owner(ctx context.Context, res *owners.Resolver, pod *corev1.Pod) client.Object {
+	owner, ok := res.Workload(ctx, pod)
+	if !ok {
+		return nil
+	}
+	return owner.Object
+}
+
+// KindName returns the kind and name of the workload that owns the pod, as
+// referenced by its outermost known controller, so pods are grouped by
+// workload even when it cannot be read. A standalone pod is its own
+// workload.
+func KindName(ctx context.Context, res *owners.Resolver, pod *corev1.Pod) (string, string) {
+	chain := res.Controllers(ctx, pod)
+	if len(chain) == 0 {
+		return "Pod", pod.Name
+	}
+	owner := chain[len(chain)-1]
+	return owner.Kind, owner.Name
+}
+
+// Setting returns the first value of the annotation key that valid accepts,
+// looking at the pod, then its controllers from the workload inwards (so a
+// Deployment's value wins over the copy on its ReplicaSet), then its
+// namespace. It returns "" when none of them sets a valid value.
+func Setting(ctx context.Context, c client.Reader, res *owners.Resolver, pod *corev1.Pod, key string, valid func(string) error) string {
+	if v, ok := pod.Annotations[key]; ok && valid(v) == nil {
+		return v
+	}
+	chain := res.Controllers(ctx, pod)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if v, ok := chain[i].Annotations[key]; ok && valid(v) == nil {
+			return v
+		}
+	}
+	var ns corev1.Namespace
+	if err := c.Get(ctx, types.NamespacedName{Name: pod.Namespace}, &ns); err == nil {
+		if v, ok := ns.Annotations[key]; ok && valid(v) == nil {
+			return v
+		}
+	}
+	return ""
+}
+
+// Kind returns the kind of a workload returned by Owner.
+func Kind(obj client.Object) string {
+	return obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+// Salvage summarizes the last salvage of one of a workload's pods, stored as
+// JSON in the tote.dev/last-salvage annotation.
+type Salvage struct {
+	Time       string `json:"time"`
+	Result     string `json:"result"` // Completed or Failed
+	Pod        string `json:"pod"`
+	Image      string `json:"image,omitempty"`
+	Digest     string `json:"digest"`
+	SourceNode string `json:"sourceNode,omitempty"`
+	TargetNode string `json:"targetNode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// RecordSalvage patches the owning workload's tote.dev/last-salvage
+// annotation. Standalone pods have no workload and are skipped.
+func RecordSalvage(ctx context.Context, c client.Client, res *owners.Resolver, pod *corev1.Pod, s Salvage) error {
+	owner := Owner(ctx, res, pod)
+	if owner == nil {
+		return nil
+	}
+	value, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encoding last salvage: %w", err)
+	}
+	return annotate(ctx, c, owner, config.AnnotationLastSalvage, string(value))
+}
+
+// Reschedule asks the pod webhook to prefer Nodes for the workload's new
+// pods until Expires, stored as JSON in the tote.dev/reschedule annotation.
+type Reschedule struct {
+	Time    time.Time `json:"time"`
+	Pod     string    `json:"pod"`
+	Digest  string    `json:"digest"`
+	Nodes   []string  `json:"nodes"`
+	Expires time.Time `json:"expires"`
+}
+
+// RecordReschedule patches the workload's tote.dev/reschedule annotation.
+func RecordReschedule(ctx context.Context, c client.Client, owner client.Object, r Reschedule) error {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding reschedule: %w", err)
+	}
+	return annotate(ctx, c, owner, config.AnnotationReschedule, string(value))
+}
+
+// PendingReschedule returns the workload's tote.dev/reschedule annotation
+// if it has not expired by now.
+func PendingReschedule(owner client.Object, now time.Time) (Reschedule, bool) {
+	var r Reschedule
+	value, ok := owner.GetAnnotations()[config.AnnotationReschedule]
+	if !ok || json.Unmarshal([]byte(value), &r) != nil || !now.Before(r.Expires) || len(r.Nodes) == 0 {
+		return Reschedule{}, false
+	}
+	return r, true
+}
+
+func annotate(ctx context.Context, c client.Client, owner client.Object, key, value string) error {
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	annotations := owner.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	owner.SetAnnotations(annotations)
+	if err := c.Patch(ctx, owner, patch); err != nil {
+		return fmt.Errorf("annotating %s %s/%s: %w", Kind(owner), owner.GetNamespace(), owner.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ppiankov/tote/internal/config"
+	"github.com/ppiankov/tote/internal/owners"
+)
+
+func newScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = appsv1.AddToScheme(s)
+	_ = batchv1.AddToScheme(s)
+	return s
+}
+
+func ownedPod(kind, name string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default"}}
+	if kind != "" {
+		apiVersion := "apps/v1"
+		if kind == "Job" {
+			apiVersion = "batch/v1"
+		}
+		pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, Controller: ptr.To(true)}}
+	}
+	return pod
+}
+
+func resolver(c client.Reader) *owners.Resolver {
+	return owners.NewResolver(c, 0)
+}
+
+func testClient() client.Client {
+	return fake.NewClientBuilder().WithScheme(newScheme()).WithRuntimeObjects(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "app-rs", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", Controller: ptr.To(true)}},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "bare-rs", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "nightly-1", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "nightly", Controller: ptr.To(true)}},
+		}},
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"}},
+	).Build()
+}
+
+func TestOwner(t *testing.T) {
+	cl := testClient()
+	tests := []struct {
+		name, kind, owner string
+		wantKind          string
+		wantName          string
+	}{
+		{"deployment via replicaset", "ReplicaSet", "app-rs", "Deployment", "app"},
+		{"bare replicaset", "ReplicaSet", "bare-rs", "ReplicaSet", "bare-rs"},
+		{"statefulset", "StatefulSet", "db", "StatefulSet", "db"},
+		{"job", "Job", "migrate", "Job", "migrate"},
+		{"cronjob via job", "Job", "nightly-1", "CronJob", "nightly"},
+		{"missing owner", "DaemonSet", "gone", "", ""},
+		{"standalone", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := Owner(context.Background(), resolver(cl), ownedPod(tt.kind, tt.owner))
+			if tt.wantKind == "" {
+				if owner != nil {
+					t.Fatalf("expected no owner, got %s/%s", Kind(owner), owner.GetName())
+				}
+				return
+			}
+			if owner == nil || Kind(owner) != tt.wantKind || owner.GetName() != tt.wantName {
+				t.Fatalf("expected %s/%s, got %v", tt.wantKind, tt.wantName, owner)
+			}
+		})
+	}
+}
+
+func TestKindName(t *testing.T) {
+	cl := testClient()
+	tests := []struct {
+		name, kind, owner string
+		wantKind          string
+		wantName          string
+	}{
+		{"deployment via replicaset", "ReplicaSet", "app-rs", "Deployment", "app"},
+		{"cronjob via job", "Job", "nightly-1", "CronJob", "nightly"},
+		{"unreadable owner", "DaemonSet", "gone", "DaemonSet", "gone"},
+		{"standalone", "", "", "Pod", "app-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, name := KindName(context.Background(), resolver(cl), ownedPod(tt.kind, tt.owner))
+			if kind != tt.wantKind || name != tt.wantName {
+				t.Errorf("expected %s/%s, got %s/%s", tt.wantKind, tt.wantName, kind, name)
+			}
+		})
+	}
+}
+
+func TestSetting(t *testing.T) {
+	key := config.AnnotationRecovery
+	ns := func(value string) *corev1.Namespace {
+		n := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		if value != "" {
+			n.Annotations = map[string]string{key: value}
+		}
+		return n
+	}
+	db := func(value string) *appsv1.StatefulSet {
+		s := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+		if value != "" {
+			s.Annotations = map[string]string{key: value}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name          string
+		pod, workload string
+		namespace     string
+		want          string
+	}{
+		{"pod wins", "wait", "evict", "restart", "wait"},
+		{"workload over namespace", "", "evict", "restart", "evict"},
+		{"namespace", "", "", "restart", "restart"},
+		{"invalid values skipped", "bogus", "bogus", "restart", "restart"},
+		{"unset", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(newScheme()).WithRuntimeObjects(ns(tt.namespace), db(tt.workload)).Build()
+			pod := ownedPod("StatefulSet", "db")
+			if tt.pod != "" {
+				pod.Annotations = map[string]string{key: tt.pod}
+			}
+			if got := Setting(context.Background(), cl, resolver(cl), pod, key, config.ValidateRecovery); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRecordSalvage(t *testing.T) {
+	cl := testClient()
+	ctx := context.Background()
+
+	err := RecordSalvage(ctx, cl, resolver(cl), ownedPod("StatefulSet", "db"), Salvage{
+		Time: "2026-01-01T00:00:00Z", Result: "Completed", Pod: "db-0", Digest: "sha256:abc", SourceNode: "node-a",
+	})
+	if err != nil {
+		t.Fatalf("RecordSalvage: %v", err)
+	}
+
+	var ss appsv1.StatefulSet
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "db"}, &ss); err != nil {
+		t.Fatal(err)
+	}
+	var got Salvage
+	if err := json.Unmarshal([]byte(ss.Annotations[config.AnnotationLastSalvage]), &got); err != nil {
+		t.Fatalf("decoding annotation %q: %v", ss.Annotations[config.AnnotationLastSalvage], err)
+	}
+	if got.Result != "Completed" || got.Pod != "db-0" || got.SourceNode != "node-a" {
+		t.Errorf("unexpected last salvage %+v", got)
+	}
+
+	if err := RecordSalvage(ctx, cl, resolver(cl), ownedPod("", ""), Salvage{Result: "Failed"}); err != nil {
+		t.Errorf("expected standalone pod to be skipped, got %v", err)
+	}
+}
+
+func TestRecordReschedule(t *testing.T) {
+	cl := testClient()
+	ctx := context.Background()
+	now := time.Now()
+
+	owner := Owner(ctx, resolver(cl), ownedPod("ReplicaSet", "app-rs"))
+	err := RecordReschedule(ctx, cl, owner, Reschedule{Digest: "sha256:abc", Nodes: []string{"node-a"}, Expires: now.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("RecordReschedule: %v", err)
+	}
+
+	var dep appsv1.Deployment
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "app"}, &dep); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := PendingReschedule(&dep, now)
+	if !ok || r.Digest != "sha256:abc" || len(r.Nodes) != 1 || r.Nodes[0] != "node-a" {
+		t.Errorf("unexpected reschedule %+v (pending=%v)", r, ok)
+	}
+	if _, ok := PendingReschedule(&dep, now.Add(2*time.Minute)); ok {
+		t.Error("expected expired reschedule to be ignored")
+	}
+	if _, ok := PendingReschedule(&appsv1.Deployment{}, now); ok {
+		t.Error("expected no reschedule without the annotation")
+	}
+}